@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AuditLogFile is the append-only log of administrative changes inside the data directory
+const AuditLogFile = "audit.jsonl"
+
+// AuditEntry records one administrative change
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	Actor   string    `json:"actor"`
+	Action  string    `json:"action"`
+	Target  string    `json:"target"`
+	Details string    `json:"details,omitempty"`
+}
+
+// CenterStatus is a center with its live seat counts
+type CenterStatus struct {
+	Center   ExamCenter
+	Capacity CenterCapacity
+	Closures []CenterClosure // sittings the center is closed for, see closures.go
+}
+
+// ListCities returns all cities, including disabled ones, sorted by name
+func (h *ExamCenterHandler) ListCities() []City {
+	cities := make([]City, 0, len(h.cities))
+	for _, c := range h.cities {
+		cities = append(cities, c)
+	}
+	sort.Slice(cities, func(i, j int) bool { return cities[i].Name < cities[j].Name })
+	return cities
+}
+
+// ListCenters returns all centers with their seat counts, sorted by city and name.
+// An empty city lists every center.
+func (h *ExamCenterHandler) ListCenters(city string) []CenterStatus {
+	var list []CenterStatus
+	for cityName, centers := range h.examCenters {
+		if city != "" && !strings.EqualFold(city, cityName) {
+			continue
+		}
+		for _, c := range centers {
+			list = append(list, CenterStatus{Center: c, Capacity: h.centerCapacity[c.Name], Closures: h.centerClosures(c.Name)})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Center.City != list[j].Center.City {
+			return list[i].Center.City < list[j].Center.City
+		}
+		return list[i].Center.Name < list[j].Center.Name
+	})
+	return list
+}
+
+// findCity looks up a city by name (case-insensitive)
+func (h *ExamCenterHandler) findCity(name string) (City, bool) {
+	name = strings.TrimSpace(name)
+	for cityName, c := range h.cities {
+		if strings.EqualFold(cityName, name) {
+			return c, true
+		}
+	}
+	return City{}, false
+}
+
+func validateCoordinates(lat, lng float64) error {
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return fmt.Errorf("coordinates %.4f, %.4f are out of range", lat, lng)
+	}
+	return nil
+}
+
+// AddCity adds a new city that candidates can live in and centers can be opened in
+func (h *ExamCenterHandler) AddCity(actor, name, state, district string, lat, lng float64) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("city name cannot be empty")
+	}
+	if _, ok := h.findCity(name); ok {
+		return fmt.Errorf("city '%s' already exists", name)
+	}
+	state, err := NormalizeState(state)
+	if err != nil {
+		return err
+	}
+	if state == "" {
+		return fmt.Errorf("state cannot be empty")
+	}
+	if err := validateCoordinates(lat, lng); err != nil {
+		return err
+	}
+	district = strings.TrimSpace(district)
+	h.cities[name] = City{Name: name, State: state, District: district, Lat: lat, Lng: lng}
+	return h.commit(actor, "city.add", name, fmt.Sprintf("state=%s district=%s lat=%.4f lng=%.4f", state, district, lat, lng))
+}
+
+// SetCityRegion changes the state and district of a city
+func (h *ExamCenterHandler) SetCityRegion(actor, name, state, district string) error {
+	city, ok := h.findCity(name)
+	if !ok {
+		return fmt.Errorf("city '%s' not found", name)
+	}
+	state, err := NormalizeState(state)
+	if err != nil {
+		return err
+	}
+	if state == "" {
+		return fmt.Errorf("state cannot be empty")
+	}
+	details := fmt.Sprintf("state %s -> %s, district %s -> %s", city.State, state, city.District, strings.TrimSpace(district))
+	city.State, city.District = state, strings.TrimSpace(district)
+	h.cities[city.Name] = city
+	return h.commit(actor, "city.region", city.Name, details)
+}
+
+// UpdateCityLocation moves a city to new coordinates
+func (h *ExamCenterHandler) UpdateCityLocation(actor, name string, lat, lng float64) error {
+	city, ok := h.findCity(name)
+	if !ok {
+		return fmt.Errorf("city '%s' not found", name)
+	}
+	if err := validateCoordinates(lat, lng); err != nil {
+		return err
+	}
+	details := fmt.Sprintf("lat %.4f -> %.4f, lng %.4f -> %.4f", city.Lat, lat, city.Lng, lng)
+	city.Lat, city.Lng = lat, lng
+	h.cities[city.Name] = city
+	return h.commit(actor, "city.update", city.Name, details)
+}
+
+// SetCityDisabled stops (or resumes) assigning candidates to a city. Candidates can still give it as their home city.
+func (h *ExamCenterHandler) SetCityDisabled(actor, name string, disabled bool) error {
+	city, ok := h.findCity(name)
+	if !ok {
+		return fmt.Errorf("city '%s' not found", name)
+	}
+	city.Disabled = disabled
+	h.cities[city.Name] = city
+	if !disabled {
+		h.PromoteWaitlist()
+	}
+	return h.commit(actor, disableAction("city", disabled), city.Name, "")
+}
+
+// AddCenter opens a new exam center in a city with all its seats available
+func (h *ExamCenterHandler) AddCenter(actor, cityName, name string, totalSeats int) error {
+	city, ok := h.findCity(cityName)
+	if !ok {
+		return fmt.Errorf("city '%s' not found", cityName)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("center name cannot be empty")
+	}
+	if _, ok := h.findCenter(name); ok {
+		return fmt.Errorf("exam center '%s' already exists", name)
+	}
+	if totalSeats < 0 {
+		return fmt.Errorf("total seats cannot be negative")
+	}
+	h.examCenters[city.Name] = append(h.examCenters[city.Name], ExamCenter{Name: name, City: city.Name})
+	h.centerCapacity[name] = CenterCapacity{TotalSeats: totalSeats, AvailableSeats: totalSeats}
+	h.PromoteWaitlist()
+	return h.commit(actor, "center.add", name, fmt.Sprintf("city=%s seats=%d", city.Name, totalSeats))
+}
+
+// RenameCenter renames a center, carrying its capacity, registrations, closures, superintendents and
+// policy quotas over to the new name
+func (h *ExamCenterHandler) RenameCenter(actor, name, newName string) error {
+	center, ok := h.findCenter(name)
+	if !ok {
+		return fmt.Errorf("exam center '%s' not found", name)
+	}
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return fmt.Errorf("center name cannot be empty")
+	}
+	if existing, ok := h.findCenter(newName); ok && existing.Name != center.Name {
+		return fmt.Errorf("exam center '%s' already exists", newName)
+	}
+	h.updateCenter(center, func(c *ExamCenter) { c.Name = newName })
+	if capInfo, ok := h.centerCapacity[center.Name]; ok {
+		delete(h.centerCapacity, center.Name)
+		h.centerCapacity[newName] = capInfo
+	}
+	for i := range h.registrations {
+		if h.registrations[i].AssignedCenter == center.Name {
+			h.registrations[i].AssignedCenter = newName
+		}
+	}
+	for i := range h.closures {
+		if h.closures[i].Center == center.Name {
+			h.closures[i].Center = newName
+		}
+	}
+	for key, u := range h.users {
+		if u.Role == RoleSuperintendent && u.Center == center.Name {
+			u.Center = newName
+			h.users[key] = u
+		}
+	}
+	h.renameQuotaTargets(QuotaCenter, center.Name, newName)
+	return h.commit(actor, "center.rename", newName, "was "+center.Name)
+}
+
+// SetCenterTotalSeats changes a center's seat count. Booked seats are kept, so the
+// total cannot drop below them; the remainder becomes available.
+func (h *ExamCenterHandler) SetCenterTotalSeats(actor, name string, totalSeats int) error {
+	center, ok := h.findCenter(name)
+	if !ok {
+		return fmt.Errorf("exam center '%s' not found", name)
+	}
+	capInfo := h.centerCapacity[center.Name]
+	if totalSeats < capInfo.BookedSeats {
+		return fmt.Errorf("%s already has %d booked seats; total cannot be %d", center.Name, capInfo.BookedSeats, totalSeats)
+	}
+	details := fmt.Sprintf("total %d -> %d", capInfo.TotalSeats, totalSeats)
+	capInfo.TotalSeats = totalSeats
+	capInfo.AvailableSeats = totalSeats - capInfo.BookedSeats
+	h.centerCapacity[center.Name] = capInfo
+	h.PromoteWaitlist()
+	return h.commit(actor, "center.seats", center.Name, details)
+}
+
+// SetCenterDisabled stops (or resumes) assigning candidates to a center. Existing registrations are kept.
+func (h *ExamCenterHandler) SetCenterDisabled(actor, name string, disabled bool) error {
+	center, ok := h.findCenter(name)
+	if !ok {
+		return fmt.Errorf("exam center '%s' not found", name)
+	}
+	h.updateCenter(center, func(c *ExamCenter) { c.Disabled = disabled })
+	details := ""
+	if disabled {
+		details = fmt.Sprintf("%d seats booked", h.centerCapacity[center.Name].BookedSeats)
+	} else {
+		h.PromoteWaitlist()
+	}
+	return h.commit(actor, disableAction("center", disabled), center.Name, details)
+}
+
+// updateCenter applies fn to the stored copy of a center
+func (h *ExamCenterHandler) updateCenter(center ExamCenter, fn func(*ExamCenter)) {
+	centers := h.examCenters[center.City]
+	for i := range centers {
+		if centers[i].Name == center.Name {
+			fn(&centers[i])
+		}
+	}
+}
+
+func disableAction(kind string, disabled bool) string {
+	if disabled {
+		return kind + ".disable"
+	}
+	return kind + ".enable"
+}
+
+// commit persists the state after an administrative change and appends it to the audit log
+func (h *ExamCenterHandler) commit(actor, action, target, details string) error {
+	if err := h.Save(); err != nil {
+		return err
+	}
+	if h.dataDir == "" {
+		return nil
+	}
+	line, err := json.Marshal(AuditEntry{Time: time.Now(), Actor: actor, Action: action, Target: target, Details: details})
+	if err != nil {
+		return fmt.Errorf("error encoding audit entry: %v", err)
+	}
+	f, err := os.OpenFile(filepath.Join(h.dataDir, AuditLogFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening audit log: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing audit log: %v", err)
+	}
+	return nil
+}
+
+// AuditLog returns up to limit audit entries, newest first. A limit of 0 returns all of them.
+func (h *ExamCenterHandler) AuditLog(limit int) ([]AuditEntry, error) {
+	if h.dataDir == "" {
+		return nil, nil
+	}
+	f, err := os.Open(filepath.Join(h.dataDir, AuditLogFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening audit log: %v", err)
+	}
+	defer f.Close()
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("error reading audit log: %v", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading audit log: %v", err)
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+} 
\ No newline at end of file
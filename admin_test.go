@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAdminConsoleChanges(t *testing.T) {
+	dir := t.TempDir()
+	h, err := OpenExamCenterHandler(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := []struct {
+		name    string
+		change  func() error
+		wantErr bool
+	}{
+		{"add city", func() error { return h.AddCity("admin", " Satara ", "maharashtra", "Satara", 17.68, 74.02) }, false},
+		{"duplicate city", func() error { return h.AddCity("admin", "satara", "Maharashtra", "", 17.68, 74.02) }, true},
+		{"unknown state", func() error { return h.AddCity("admin", "Karad", "Deccan", "", 17.29, 74.18) }, true},
+		{"coordinates out of range", func() error { return h.AddCity("admin", "Karad", "Maharashtra", "", 97.29, 74.18) }, true},
+		{"add center", func() error { return h.AddCenter("admin", "SATARA", "Satara Exam Hall", 40) }, false},
+		{"center in unknown city", func() error { return h.AddCenter("admin", "Karad", "Karad Exam Hall", 40) }, true},
+		{"duplicate center", func() error { return h.AddCenter("admin", "Pune", "satara exam hall", 40) }, true},
+		{"negative seats", func() error { return h.AddCenter("admin", "Satara", "Wai Exam Hall", -1) }, true},
+		{"resize center", func() error { return h.SetCenterTotalSeats("admin", "Satara Exam Hall", 60) }, false},
+		{"disable center", func() error { return h.SetCenterDisabled("admin", "Satara Exam Hall", true) }, false},
+		{"rename onto another center", func() error { return h.RenameCenter("admin", "Satara Exam Hall", "Pune University Center") }, true},
+		{"rename center", func() error { return h.RenameCenter("admin", "Satara Exam Hall", "Satara Central Hall") }, false},
+		{"move city", func() error { return h.UpdateCityLocation("admin", "Satara", 17.69, 74.00) }, false},
+	}
+	for _, s := range steps {
+		if err := s.change(); (err != nil) != s.wantErr {
+			t.Fatalf("%s: error %v, want error %v", s.name, err, s.wantErr)
+		}
+	}
+
+	reopened, err := OpenExamCenterHandler(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	city, ok := reopened.findCity("satara")
+	if want := (City{Name: "Satara", State: "Maharashtra", District: "Satara", Lat: 17.69, Lng: 74.00}); !ok || city != want {
+		t.Errorf("city %+v, want %+v", city, want)
+	}
+	centers := reopened.ListCenters("Satara")
+	want := []CenterStatus{{Center: ExamCenter{Name: "Satara Central Hall", City: "Satara", Disabled: true}, Capacity: CenterCapacity{TotalSeats: 60, AvailableSeats: 60}}}
+	if !reflect.DeepEqual(centers, want) {
+		t.Errorf("centers %+v, want %+v", centers, want)
+	}
+
+	entries, err := reopened.AuditLog(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action+" "+e.Target)
+	}
+	wantActions := []string{"city.update Satara", "center.rename Satara Central Hall", "center.disable Satara Exam Hall",
+		"center.seats Satara Exam Hall", "center.add Satara Exam Hall", "city.add Satara"}
+	if !reflect.DeepEqual(actions, wantActions) {
+		t.Errorf("audit log %q, want %q", actions, wantActions)
+	}
+}
+
+func TestSetCenterTotalSeatsKeepsBookedSeats(t *testing.T) {
+	h := NewExamCenterHandler()
+	const center = "Pune University Center"
+	h.centerCapacity[center] = CenterCapacity{TotalSeats: 10, AvailableSeats: 7, BookedSeats: 3}
+	tests := []struct {
+		total   int
+		wantErr bool
+		want    CenterCapacity
+	}{
+		{2, true, CenterCapacity{TotalSeats: 10, AvailableSeats: 7, BookedSeats: 3}},
+		{3, false, CenterCapacity{TotalSeats: 3, AvailableSeats: 0, BookedSeats: 3}},
+		{25, false, CenterCapacity{TotalSeats: 25, AvailableSeats: 22, BookedSeats: 3}},
+	}
+	for _, tt := range tests {
+		err := h.SetCenterTotalSeats("admin", center, tt.total)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("total %d: error %v, want error %v", tt.total, err, tt.wantErr)
+		}
+		if got := h.centerCapacity[center]; got != tt.want {
+			t.Errorf("total %d: capacity %+v, want %+v", tt.total, got, tt.want)
+		}
+	}
+} 
\ No newline at end of file
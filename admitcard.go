@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ReportingLeadTime is how long before the slot starts candidates must report at the center
+const ReportingLeadTime = 30 * time.Minute
+
+// admitCardPrefix marks (and versions) the signed payload carried in the admit card QR code
+const admitCardPrefix = "ECH1"
+
+// AdmitCardPayload is the candidate data encoded in the admit card QR code
+type AdmitCardPayload struct {
+	RegistrationID string `json:"id"`
+	Name           string `json:"name"`
+	RollNumber     string `json:"roll"`
+	Exam           string `json:"exam"`
+	Edition        int    `json:"edition,omitempty"`
+	Center         string `json:"center"`
+	City           string `json:"city"`
+	Date           string `json:"date"`
+	Slot           string `json:"slot"`
+	Reporting      string `json:"report"`
+}
+
+// ReportingTime returns the reporting time for a slot such as "09:00-12:00"
+func ReportingTime(slot string) string {
+	start, _, _ := strings.Cut(slot, "-")
+	t, err := time.Parse("15:04", strings.TrimSpace(start))
+	if err != nil {
+		return ""
+	}
+	return t.Add(-ReportingLeadTime).Format("15:04")
+}
+
+// NewAdmitCardPayload builds the QR payload for a registration
+func NewAdmitCardPayload(reg ExamRegistration) AdmitCardPayload {
+	return AdmitCardPayload{
+		RegistrationID: reg.ID,
+		Name:           reg.StudentName,
+		RollNumber:     reg.RollNumber,
+		Exam:           reg.ExamType.Code,
+		Edition:        reg.ExamType.Edition,
+		Center:         reg.AssignedCenter,
+		City:           reg.AssignedCity,
+		Date:           reg.ExamDate,
+		Slot:           reg.TimeSlot,
+		Reporting:      ReportingTime(reg.TimeSlot),
+	}
+}
+
+// SignAdmitCardPayload encodes the payload as "ECH1:<signature>:<json>" signed with the server key
+func (h *ExamCenterHandler) SignAdmitCardPayload(p AdmitCardPayload) (string, error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return "", fmt.Errorf("error encoding admit card payload: %v", err)
+	}
+	sig := ed25519.Sign(h.signingKey, body)
+	return admitCardPrefix + ":" + base64.RawURLEncoding.EncodeToString(sig) + ":" + string(body), nil
+}
+
+// ErrInvalidAdmitCard is returned when a scanned payload is malformed or its signature does not verify
+var ErrInvalidAdmitCard = errors.New("admit card signature is not valid")
+
+// VerifyAdmitCard checks a scanned QR payload against the public key and decodes it
+func VerifyAdmitCard(signed string, pub ed25519.PublicKey) (AdmitCardPayload, error) {
+	var p AdmitCardPayload
+	prefix, rest, ok := strings.Cut(strings.TrimSpace(signed), ":")
+	if !ok || prefix != admitCardPrefix {
+		return p, fmt.Errorf("not an ExamCenterHub admit card")
+	}
+	sigText, body, ok := strings.Cut(rest, ":")
+	if !ok {
+		return p, ErrInvalidAdmitCard
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(sigText)
+	if err != nil || !ed25519.Verify(pub, []byte(body), sig) {
+		return p, ErrInvalidAdmitCard
+	}
+	if err := json.Unmarshal([]byte(body), &p); err != nil {
+		return p, fmt.Errorf("error decoding admit card: %v", err)
+	}
+	return p, nil
+}
+
+// GateCheck is the outcome of checking an admit card at a center gate
+type GateCheck struct {
+	Card         AdmitCardPayload
+	ExamName     string
+	WrongCenter  bool
+	WrongDay     bool
+	WrongEdition bool // issued for another edition than the one held on the day, such as last year's
+	Edition      int  // the edition held on the day; 0 when the exam catalogue has none on that day
+}
+
+// Admit reports whether the candidate belongs at this gate today
+func (g GateCheck) Admit() bool { return !g.WrongCenter && !g.WrongDay && !g.WrongEdition }
+
+// CheckAdmitCardAtGate verifies a payload and flags cards issued for another center, day or edition.
+// The exam name and the edition held on date come from the exam catalogue; cards printed before
+// editions were signed count as another edition. An empty center skips the center check; date is
+// YYYY-MM-DD.
+func (h *ExamCenterHandler) CheckAdmitCardAtGate(signed string, pub ed25519.PublicKey, center, date string) (GateCheck, error) {
+	card, err := VerifyAdmitCard(signed, pub)
+	if err != nil {
+		return GateCheck{}, err
+	}
+	check := GateCheck{Card: card, ExamName: card.Exam}
+	if ex, err := h.ExamEdition(card.Exam, card.Edition); err == nil {
+		check.ExamName = ex.Name
+	} else if ex, ok := h.currentEdition(card.Exam); ok {
+		check.ExamName = ex.Name
+	}
+	if held, ok := h.editionOn(card.Exam, date); ok {
+		check.Edition = held.Edition
+		check.WrongEdition = card.Edition != held.Edition
+	}
+	center = strings.TrimSpace(center)
+	check.WrongCenter = center != "" && !strings.EqualFold(center, card.Center)
+	check.WrongDay = date != card.Date
+	return check, nil
+}
+
+// AdmitCardFileName returns a safe file name for a registration's admit card
+func AdmitCardFileName(id string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, id)
+	return "admit-card-" + safe + ".pdf"
+}
+
+// GenerateAdmitCard renders the admit card for a registration as a PDF document
+func (h *ExamCenterHandler) GenerateAdmitCard(id string) ([]byte, error) {
+	reg, err := h.GetRegistration(id)
+	if err != nil {
+		return nil, err
+	}
+	payload := NewAdmitCardPayload(reg)
+	signed, err := h.SignAdmitCardPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	qr, err := encodeQR([]byte(signed), qrLevelM)
+	if err != nil {
+		return nil, err
+	}
+
+	var p pdfPage
+	p.gray(0)
+	p.strokeRect(40, 40, pdfPageWidth-80, pdfPageHeight-80)
+	p.text(60, 772, 22, true, "ExamCenterHub")
+	p.text(60, 750, 16, true, "ADMIT CARD")
+	p.text(60, 732, 11, false, fmt.Sprintf("%s - %s", reg.ExamType.Label(), reg.ExamType.Name))
+	p.line(60, 720, pdfPageWidth-60, 720)
+
+	fields := [][2]string{
+		{"Registration ID", reg.ID},
+		{"Candidate Name", reg.StudentName},
+		{"Roll Number", reg.RollNumber},
+		{"Home City", reg.StudentCity},
+		{"Exam Date", reg.ExamDate},
+		{"Time Slot", reg.TimeSlot},
+		{"Reporting Time", payload.Reporting},
+		{"Exam Center", reg.AssignedCenter},
+		{"City", reg.AssignedCity},
+		{"Duration", reg.ExamType.Duration.String()},
+	}
+	if reg.Room != "" {
+		fields = append(fields, [2]string{"Room / Seat", reg.Room + " / " + reg.SeatNumber})
+	}
+	if reg.Preferences.NeedsScribe {
+		fields = append(fields, [2]string{"Scribe", "Allowed - bring the scribe's photo ID"})
+	}
+	y := 692.0
+	for _, f := range fields {
+		p.text(60, y, 11, true, f[0])
+		p.text(190, y, 11, false, f[1])
+		y -= 24
+	}
+	p.line(60, y+6, pdfPageWidth-60, y+6)
+
+	p.qr(qr, 52, y-200, 190)
+	p.text(60, y-214, 9, false, "Scan at the center gate to verify this card")
+
+	instructions := []string{
+		"IMPORTANT INSTRUCTIONS",
+		fmt.Sprintf("Report at the center by %s.", payload.Reporting),
+		"Carry this admit card and a valid photo ID.",
+		"Electronic devices are not allowed in the exam hall.",
+		"Candidates arriving after the slot starts will not be admitted.",
+		fmt.Sprintf("Exam duration: %s", reg.ExamType.Duration.String()),
+	}
+	iy := y - 30
+	for i, line := range instructions {
+		p.text(260, iy, 10, i == 0, line)
+		iy -= 18
+	}
+	p.text(60, 56, 8, false, fmt.Sprintf("Generated %s by ExamCenterHub", time.Now().Format("2006-01-02 15:04")))
+	return p.bytes(), nil
+}
+
+// WriteAdmitCard writes the admit card PDF for a registration to path
+func (h *ExamCenterHandler) WriteAdmitCard(id, path string) error {
+	pdf, err := h.GenerateAdmitCard(id)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, pdf, 0o644); err != nil {
+		return fmt.Errorf("error writing admit card: %v", err)
+	}
+	return nil
+}
+
+// ProcessAdmitCardDownload asks for a registration ID and writes its admit card to a file
+func (h *ExamCenterHandler) ProcessAdmitCardDownload() error {
+	id, err := h.GetUserInput("Enter your Registration ID: ")
+	if err != nil {
+		return fmt.Errorf("error reading registration ID: %v", err)
+	}
+	reg, err := h.GetRegistration(id)
+	if err != nil {
+		return err
+	}
+	path := AdmitCardFileName(reg.ID)
+	out, err := h.GetUserInput(fmt.Sprintf("Output file [default: %s]: ", path))
+	if err != nil {
+		return fmt.Errorf("error reading output file: %v", err)
+	}
+	if out != "" {
+		path = out
+	}
+	if err := h.WriteAdmitCard(reg.ID, path); err != nil {
+		return err
+	}
+	fmt.Printf("\n✅ Admit card written to %s\n", path)
+	return nil
+} 
\ No newline at end of file
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// admitCardHandler signs with a fresh key and holds two editions of UPSC: 2024 and 2025
+func admitCardHandler(t *testing.T) (*ExamCenterHandler, ed25519.PublicKey) {
+	t.Helper()
+	h := NewExamCenterHandler()
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.SetSigningKey(key)
+	ex, err := h.ExamEdition("UPSC", 2024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ex.Edition, ex.Name = 2025, "Civil Services Preliminary"
+	ex.Schedule.StartDate, ex.Schedule.EndDate = "2025-05-25", "2025-05-26"
+	h.exams = append(h.exams, ex)
+	sortExamTypes(h.exams)
+	return h, pub
+}
+
+func TestAdmitCardSignatureRoundTrip(t *testing.T) {
+	h, pub := admitCardHandler(t)
+	want := AdmitCardPayload{RegistrationID: "UPSC-2025-U1-1", Name: "Asha Verma", RollNumber: "U1", Exam: "UPSC", Edition: 2025,
+		Center: "Pune University Center", City: "Pune", Date: "2025-05-25", Slot: "09:30-12:30", Reporting: "09:00"}
+	signed, err := h.SignAdmitCardPayload(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := VerifyAdmitCard(signed, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decoded %+v, want %+v", got, want)
+	}
+
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	tests := []struct {
+		name    string
+		payload string
+		pub     ed25519.PublicKey
+		wantErr error
+	}{
+		{"edited center", strings.Replace(signed, "Pune University Center", "Kothrud Sports Complex", 1), pub, ErrInvalidAdmitCard},
+		{"edited edition", strings.Replace(signed, `"edition":2025`, `"edition":2026`, 1), pub, ErrInvalidAdmitCard},
+		{"another key", signed, otherPub, ErrInvalidAdmitCard},
+		{"no signature", "ECH1:" + signed[strings.LastIndex(signed, ":")+1:], pub, ErrInvalidAdmitCard},
+		{"not a card", "hello", pub, nil},
+	}
+	for _, tt := range tests {
+		_, err := VerifyAdmitCard(tt.payload, tt.pub)
+		if err == nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckAdmitCardAtGate(t *testing.T) {
+	h, pub := admitCardHandler(t)
+	card := func(edition int, date string) string {
+		signed, err := h.SignAdmitCardPayload(AdmitCardPayload{RegistrationID: "UPSC-U1-1", Exam: "UPSC", Edition: edition,
+			Center: "Pune University Center", City: "Pune", Date: date, Slot: "09:30-12:30"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	tests := []struct {
+		name                             string
+		payload, center, date            string
+		examName                         string
+		wrongCenter, wrongDay, wrongEdit bool
+	}{
+		{"this year's card", card(2025, "2025-05-25"), "Pune University Center", "2025-05-25", "Civil Services Preliminary", false, false, false},
+		{"any center", card(2025, "2025-05-25"), "", "2025-05-25", "Civil Services Preliminary", false, false, false},
+		{"other center", card(2025, "2025-05-25"), "Kothrud Sports Complex", "2025-05-25", "Civil Services Preliminary", true, false, false},
+		{"other day", card(2025, "2025-05-26"), "Pune University Center", "2025-05-25", "Civil Services Preliminary", false, true, false},
+		{"last year's card on the same date", card(2024, "2025-05-25"), "Pune University Center", "2025-05-25", "Union Public Service Commission", false, false, true},
+		{"card without an edition", card(0, "2025-05-25"), "Pune University Center", "2025-05-25", "Union Public Service Commission", false, false, true},
+		{"no edition held that day", card(2025, "2025-07-01"), "Pune University Center", "2025-07-01", "Civil Services Preliminary", false, false, false},
+	}
+	for _, tt := range tests {
+		got, err := h.CheckAdmitCardAtGate(tt.payload, pub, tt.center, tt.date)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got.ExamName != tt.examName || got.WrongCenter != tt.wrongCenter || got.WrongDay != tt.wrongDay || got.WrongEdition != tt.wrongEdit {
+			t.Errorf("%s: %+v, want exam %q, wrong center %v, day %v, edition %v", tt.name, got, tt.examName, tt.wrongCenter, tt.wrongDay, tt.wrongEdit)
+		}
+		if want := !tt.wrongCenter && !tt.wrongDay && !tt.wrongEdit; got.Admit() != want {
+			t.Errorf("%s: admit = %v, want %v", tt.name, got.Admit(), want)
+		}
+	}
+} 
\ No newline at end of file
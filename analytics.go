@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+// DistanceStats summarises how far candidates travel, in km
+type DistanceStats struct {
+	Count int
+	Mean  float64
+	P50   float64
+	P95   float64
+	Max   float64
+}
+
+// GroupAnalytics is the travel picture for one exam or home state
+type GroupAnalytics struct {
+	Name               string
+	Distance           DistanceStats
+	FirstPreference    int     // candidates seated in their nearest city with an exam center
+	FirstPreferencePct float64 // share of Distance.Count
+}
+
+// CenterFill is how full one center is
+type CenterFill struct {
+	Center  string
+	City    string
+	Total   int
+	Booked  int
+	FillPct float64
+}
+
+// Bucket counts values within [From, To); To is 0 for the open-ended last bucket
+type Bucket struct {
+	Label string
+	From  float64
+	To    float64
+	Count int
+}
+
+// Analytics describes travel distances, preference matches, center fill and fairness across home states
+type Analytics struct {
+	Overall         GroupAnalytics
+	Exams           []GroupAnalytics // by exam code
+	States          []GroupAnalytics // by home state, longest mean distance first
+	DistanceBuckets []Bucket
+	Centers         []CenterFill // fullest first
+	FillBuckets     []Bucket
+	MeanFillPct     float64
+	// Gini is the Gini coefficient of candidate distances: 0 when everyone travels equally far,
+	// approaching 1 when a few candidates do all the travelling
+	Gini float64
+	// StateSpread is the highest state mean distance divided by the lowest (0 with fewer than two states)
+	StateSpread float64
+}
+
+var distanceBucketEdges = []float64{0, 25, 50, 100, 200, 400}
+var fillBucketEdges = []float64{0, 25, 50, 75, 90, 100}
+
+// Analytics computes statistics over the registrations matching f. allowed limits them to what the
+// caller may see; nil allows all. Center fill covers f.City (all cities when empty) whatever the exam.
+func (h *ExamCenterHandler) Analytics(f RegistrationFilter, allowed func(ExamRegistration) bool) Analytics {
+	regs := h.FilterRegistrations(f, allowed)
+	var a Analytics
+	byExam := make(map[string][]ExamRegistration)
+	byState := make(map[string][]ExamRegistration)
+	distances := make([]float64, 0, len(regs))
+	firstChoice := make(map[string]string) // home city -> nearest city with an active center
+	for _, r := range regs {
+		byExam[r.ExamType.Code] = append(byExam[r.ExamType.Code], r)
+		byState[h.CityState(r.StudentCity)] = append(byState[h.CityState(r.StudentCity)], r)
+		distances = append(distances, r.Distance)
+		if _, ok := firstChoice[r.StudentCity]; !ok {
+			firstChoice[r.StudentCity] = h.nearestCenterCity(r.StudentCity)
+		}
+	}
+	group := func(name string, regs []ExamRegistration) GroupAnalytics {
+		g := GroupAnalytics{Name: name}
+		d := make([]float64, len(regs))
+		for i, r := range regs {
+			d[i] = r.Distance
+			if r.AssignedCity == firstChoice[r.StudentCity] {
+				g.FirstPreference++
+			}
+		}
+		g.Distance = distanceStats(d)
+		if len(regs) > 0 {
+			g.FirstPreferencePct = float64(g.FirstPreference) / float64(len(regs)) * 100
+		}
+		return g
+	}
+	a.Overall = group("All", regs)
+	for code, list := range byExam {
+		a.Exams = append(a.Exams, group(code, list))
+	}
+	sort.Slice(a.Exams, func(i, j int) bool { return a.Exams[i].Name < a.Exams[j].Name })
+	for state, list := range byState {
+		a.States = append(a.States, group(state, list))
+	}
+	sort.Slice(a.States, func(i, j int) bool {
+		if a.States[i].Distance.Mean != a.States[j].Distance.Mean {
+			return a.States[i].Distance.Mean > a.States[j].Distance.Mean
+		}
+		return a.States[i].Name < a.States[j].Name
+	})
+	if n := len(a.States); n > 1 && a.States[n-1].Distance.Mean > 0 {
+		a.StateSpread = a.States[0].Distance.Mean / a.States[n-1].Distance.Mean
+	}
+	a.Gini = gini(distances)
+	a.DistanceBuckets = bucketize(distances, distanceBucketEdges, " km")
+
+	var fills []float64
+	for _, c := range h.ListCenters(f.City) {
+		if c.Center.Disabled || c.Capacity.TotalSeats == 0 {
+			continue
+		}
+		fill := float64(c.Capacity.BookedSeats) / float64(c.Capacity.TotalSeats) * 100
+		a.Centers = append(a.Centers, CenterFill{Center: c.Center.Name, City: c.Center.City, Total: c.Capacity.TotalSeats, Booked: c.Capacity.BookedSeats, FillPct: fill})
+		fills = append(fills, fill)
+		a.MeanFillPct += fill
+	}
+	if len(fills) > 0 {
+		a.MeanFillPct /= float64(len(fills))
+	}
+	sort.SliceStable(a.Centers, func(i, j int) bool { return a.Centers[i].FillPct > a.Centers[j].FillPct })
+	a.FillBuckets = bucketize(fills, fillBucketEdges, "%")
+	return a
+}
+
+// nearestCenterCity is the closest other city with an active center, seats or not: where the
+// candidate would sit if capacity were unlimited
+func (h *ExamCenterHandler) nearestCenterCity(homeCity string) string {
+	home, ok := h.cities[homeCity]
+	if !ok {
+		return ""
+	}
+	best, bestDist := "", math.MaxFloat64
+	for name, c := range h.cities {
+		if name == homeCity || c.Disabled || len(h.activeCenters(name)) == 0 {
+			continue
+		}
+		if d := h.calculateDistance(home, c); d < bestDist {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+func distanceStats(d []float64) DistanceStats {
+	s := DistanceStats{Count: len(d)}
+	if len(d) == 0 {
+		return s
+	}
+	sorted := append([]float64(nil), d...)
+	sort.Float64s(sorted)
+	for _, v := range sorted {
+		s.Mean += v
+	}
+	s.Mean /= float64(len(sorted))
+	s.P50 = percentile(sorted, 50)
+	s.P95 = percentile(sorted, 95)
+	s.Max = sorted[len(sorted)-1]
+	return s
+}
+
+// percentile uses the nearest-rank method on sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+// gini computes the Gini coefficient of non-negative values
+func gini(values []float64) float64 {
+	n := len(values)
+	if n == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	var sum, weighted float64
+	for i, v := range sorted {
+		sum += v
+		weighted += float64(i+1) * v
+	}
+	if sum == 0 {
+		return 0
+	}
+	return (2*weighted)/(float64(n)*sum) - float64(n+1)/float64(n)
+}
+
+// bucketize counts values between consecutive edges; the last bucket is open-ended unless the
+// final edge is 100 (a percentage), in which case it is closed to include 100
+func bucketize(values, edges []float64, unit string) []Bucket {
+	percent := edges[len(edges)-1] == 100
+	var buckets []Bucket
+	for i := range edges {
+		b := Bucket{From: edges[i]}
+		switch {
+		case i+1 < len(edges):
+			b.To = edges[i+1]
+			b.Label = formatEdge(b.From) + "–" + formatEdge(b.To) + unit
+		case percent:
+			continue
+		default:
+			b.Label = formatEdge(b.From) + "+" + unit
+		}
+		buckets = append(buckets, b)
+	}
+	for _, v := range values {
+		for i := range buckets {
+			last := i == len(buckets)-1
+			if v >= buckets[i].From && (v < buckets[i].To || buckets[i].To == 0 || (last && percent && v <= buckets[i].To)) {
+				buckets[i].Count++
+				break
+			}
+		}
+	}
+	return buckets
+}
+
+func formatEdge(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) } 
\ No newline at end of file
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// analyticsHandler has two Maharashtra cities and one in Gujarat; the Satara center is closed
+func analyticsHandler() *ExamCenterHandler {
+	h := NewExamCenterHandler()
+	h.cities = map[string]City{
+		"Satara": {Name: "Satara", State: "Maharashtra", Lat: 17.68, Lng: 74.02},
+		"Pune":   {Name: "Pune", State: "Maharashtra", Lat: 18.52, Lng: 73.86},
+		"Surat":  {Name: "Surat", State: "Gujarat", Lat: 21.17, Lng: 72.83},
+	}
+	h.examCenters = map[string][]ExamCenter{
+		"Satara": {{Name: "Satara Hall", City: "Satara", Disabled: true}},
+		"Pune":   {{Name: "Pune Hall", City: "Pune"}},
+		"Surat":  {{Name: "Surat Hall", City: "Surat"}},
+	}
+	h.centerCapacity = map[string]CenterCapacity{
+		"Satara Hall": {TotalSeats: 10, AvailableSeats: 10},
+		"Pune Hall":   {TotalSeats: 10, AvailableSeats: 5, BookedSeats: 5},
+		"Surat Hall":  {TotalSeats: 4, BookedSeats: 4},
+	}
+	reg := func(exam, home, assigned string, km float64) ExamRegistration {
+		return ExamRegistration{ExamType: ExamType{Code: exam}, StudentCity: home, AssignedCity: assigned, Distance: km}
+	}
+	h.registrations = []ExamRegistration{
+		reg("UPSC", "Satara", "Pune", 10),
+		reg("UPSC", "Satara", "Surat", 30),
+		reg("NEET", "Surat", "Pune", 450),
+		reg("NEET", "Surat", "Surat", 0),
+	}
+	return h
+}
+
+func TestAnalytics(t *testing.T) {
+	a := analyticsHandler().Analytics(RegistrationFilter{}, nil)
+
+	// the first preference is the nearest other city with an open center: Pune for both home cities
+	want := GroupAnalytics{Name: "All", Distance: DistanceStats{Count: 4, Mean: 122.5, P50: 10, P95: 450, Max: 450}, FirstPreference: 2, FirstPreferencePct: 50}
+	if !reflect.DeepEqual(a.Overall, want) {
+		t.Errorf("overall %+v, want %+v", a.Overall, want)
+	}
+	wantExams := []GroupAnalytics{
+		{Name: "NEET", Distance: DistanceStats{Count: 2, Mean: 225, P50: 0, P95: 450, Max: 450}, FirstPreference: 1, FirstPreferencePct: 50},
+		{Name: "UPSC", Distance: DistanceStats{Count: 2, Mean: 20, P50: 10, P95: 30, Max: 30}, FirstPreference: 1, FirstPreferencePct: 50},
+	}
+	if !reflect.DeepEqual(a.Exams, wantExams) {
+		t.Errorf("exams %+v, want %+v", a.Exams, wantExams)
+	}
+	var states []string
+	for _, s := range a.States {
+		states = append(states, s.Name)
+	}
+	if !reflect.DeepEqual(states, []string{"Gujarat", "Maharashtra"}) || a.StateSpread != 225.0/20 {
+		t.Errorf("states %v with spread %v, want Gujarat then Maharashtra with spread 11.25", states, a.StateSpread)
+	}
+	if math.Abs(a.Gini-0.69898) > 1e-5 {
+		t.Errorf("Gini %.5f, want 0.69898", a.Gini)
+	}
+	if got := bucketCounts(a.DistanceBuckets); !reflect.DeepEqual(got, map[string]int{"0–25 km": 2, "25–50 km": 1, "50–100 km": 0, "100–200 km": 0, "200–400 km": 0, "400+ km": 1}) {
+		t.Errorf("distance buckets %v", got)
+	}
+
+	wantCenters := []CenterFill{{Center: "Surat Hall", City: "Surat", Total: 4, Booked: 4, FillPct: 100}, {Center: "Pune Hall", City: "Pune", Total: 10, Booked: 5, FillPct: 50}}
+	if !reflect.DeepEqual(a.Centers, wantCenters) || a.MeanFillPct != 75 {
+		t.Errorf("centers %+v with mean fill %v, want %+v with 75", a.Centers, a.MeanFillPct, wantCenters)
+	}
+	if got := bucketCounts(a.FillBuckets); !reflect.DeepEqual(got, map[string]int{"0–25%": 0, "25–50%": 0, "50–75%": 1, "75–90%": 0, "90–100%": 1}) {
+		t.Errorf("fill buckets %v", got)
+	}
+}
+
+func TestAnalyticsFilter(t *testing.T) {
+	a := analyticsHandler().Analytics(RegistrationFilter{Exam: "UPSC", City: "Pune"}, nil)
+	if a.Overall.Distance.Count != 1 || len(a.Exams) != 1 || len(a.States) != 1 || a.StateSpread != 0 {
+		t.Errorf("UPSC in Pune: %+v", a)
+	}
+	if len(a.Centers) != 1 || a.Centers[0].Center != "Pune Hall" {
+		t.Errorf("centers %+v, want only Pune Hall", a.Centers)
+	}
+
+	empty := analyticsHandler().Analytics(RegistrationFilter{Exam: "JEE"}, nil)
+	if empty.Overall.Distance != (DistanceStats{}) || empty.Gini != 0 {
+		t.Errorf("no registrations: %+v", empty.Overall)
+	}
+}
+
+func TestGini(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   float64
+	}{
+		{nil, 0},
+		{[]float64{0, 0}, 0},
+		{[]float64{40, 40, 40}, 0},
+		{[]float64{0, 0, 0, 100}, 0.75},
+		{[]float64{10, 30}, 0.25},
+	}
+	for _, tt := range tests {
+		if got := gini(tt.values); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("gini(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
+
+func bucketCounts(buckets []Bucket) map[string]int {
+	counts := make(map[string]int)
+	for _, b := range buckets {
+		counts[b.Label] = b.Count
+	}
+	return counts
+} 
\ No newline at end of file
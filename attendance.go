@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RowError reports a problem with one row of an uploaded file
+type RowError struct {
+	Row     int
+	Message string
+}
+
+func (e RowError) Error() string { return fmt.Sprintf("row %d: %s", e.Row, e.Message) }
+
+// ParseAttendanceStatus accepts the spellings invigilators commonly use on sheets
+func ParseAttendanceStatus(s string) (AttendanceStatus, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "present", "p", "yes", "y", "1":
+		return AttendancePresent, nil
+	case "absent", "a", "no", "n", "0":
+		return AttendanceAbsent, nil
+	}
+	return "", fmt.Errorf("invalid attendance status '%s' (use present or absent)", s)
+}
+
+// MarkAttendance records a candidate as present or absent; a later mark replaces an earlier one
+func (h *ExamCenterHandler) MarkAttendance(registrationID string, status AttendanceStatus, markedBy string) error {
+	reg, err := h.GetRegistration(registrationID)
+	if err != nil {
+		return err
+	}
+	if status != AttendancePresent && status != AttendanceAbsent {
+		return fmt.Errorf("invalid attendance status '%s'", status)
+	}
+	h.attendance[reg.ID] = AttendanceRecord{
+		RegistrationID: reg.ID,
+		Status:         status,
+		MarkedBy:       markedBy,
+		MarkedAt:       time.Now(),
+	}
+	return nil
+}
+
+// GetAttendance returns the attendance mark for a registration, if any
+func (h *ExamCenterHandler) GetAttendance(registrationID string) (AttendanceRecord, bool) {
+	rec, ok := h.attendance[registrationID]
+	return rec, ok
+}
+
+// ImportAttendanceCSV marks attendance from CSV rows of "registration_id,status".
+// A header row is optional. Valid rows are applied even when other rows fail.
+// When allowed is not nil, rows for registrations it rejects are reported instead of marked.
+func (h *ExamCenterHandler) ImportAttendanceCSV(r io.Reader, markedBy string, allowed func(ExamRegistration) bool) (int, []RowError) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	var errs []RowError
+	marked := 0
+	for row := 1; ; row++ {
+		rec, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			errs = append(errs, RowError{Row: row, Message: err.Error()})
+			break
+		}
+		if row == 1 && len(rec) > 0 && strings.EqualFold(strings.TrimSpace(rec[0]), "registration_id") {
+			continue
+		}
+		if len(rec) < 2 {
+			errs = append(errs, RowError{Row: row, Message: "expected registration_id,status"})
+			continue
+		}
+		status, err := ParseAttendanceStatus(rec[1])
+		if err != nil {
+			errs = append(errs, RowError{Row: row, Message: err.Error()})
+			continue
+		}
+		if allowed != nil {
+			if reg, err := h.GetRegistration(rec[0]); err == nil && !allowed(reg) {
+				errs = append(errs, RowError{Row: row, Message: fmt.Sprintf("registration '%s' is outside your scope", reg.ID)})
+				continue
+			}
+		}
+		if err := h.MarkAttendance(rec[0], status, markedBy); err != nil {
+			errs = append(errs, RowError{Row: row, Message: err.Error()})
+			continue
+		}
+		marked++
+	}
+	return marked, errs
+}
+
+// CenterAttendance summarises exam-day attendance at one sitting (date and slot) of a center
+type CenterAttendance struct {
+	Center     string
+	City       string
+	Date       string
+	Slot       string
+	Registered int // registrations for this sitting
+	Present    int
+	Absent     int
+	Unmarked   int
+	Seats      int // the center's seats, each sitting can use all of them
+	NoShows    []ExamRegistration
+
+	// Center-wide figures, set on the first row of each center so drift is reported once
+	Booked           int // the center's booked seat counter (CenterCapacity.BookedSeats)
+	CenterRegistered int // registrations at the center across all sittings
+	CenterMarked     int // of those, marked present or absent
+}
+
+// NoShowRate is the share of marked candidates who were absent
+func (c CenterAttendance) NoShowRate() float64 {
+	if c.Present+c.Absent == 0 {
+		return 0
+	}
+	return float64(c.Absent) / float64(c.Present+c.Absent) * 100
+}
+
+// Issues lists where the sitting's attendance fails to reconcile with its registrations and seats
+func (c CenterAttendance) Issues() []string {
+	var issues []string
+	if c.Unmarked > 0 {
+		issues = append(issues, fmt.Sprintf("%d of %d registered candidates not marked", c.Unmarked, c.Registered))
+	}
+	if c.Seats > 0 && c.Registered > c.Seats {
+		issues = append(issues, fmt.Sprintf("%d registrations for %d seats", c.Registered, c.Seats))
+	}
+	if c.Seats > 0 && c.Present > c.Seats {
+		issues = append(issues, fmt.Sprintf("%d candidates marked present for %d seats", c.Present, c.Seats))
+	}
+	// The counter also holds seats booked outside ExamCenterHub, so only a counter below the
+	// registrations it should include is drift
+	if c.CenterRegistered > c.Booked {
+		issues = append(issues, fmt.Sprintf("center has %d registrations (%d marked) but its seat counter shows %d booked",
+			c.CenterRegistered, c.CenterMarked, c.Booked))
+	}
+	return issues
+}
+
+// AttendanceReport summarises attendance for every sitting of every center with registrations, sorted
+// by city, center, date and slot. An empty center returns all centers.
+func (h *ExamCenterHandler) AttendanceReport(center string) []CenterAttendance {
+	bySitting := make(map[string]*CenterAttendance)
+	byCenter := make(map[string]*CenterAttendance) // center-wide totals, copied to the center's first row
+	for _, reg := range h.registrations {
+		if center != "" && !strings.EqualFold(reg.AssignedCenter, center) {
+			continue
+		}
+		key := reg.AssignedCenter + "\x00" + reg.ExamDate + "\x00" + reg.TimeSlot
+		ca, ok := bySitting[key]
+		if !ok {
+			ca = &CenterAttendance{Center: reg.AssignedCenter, City: reg.AssignedCity, Date: reg.ExamDate, Slot: reg.TimeSlot}
+			if capInfo, ok := h.centerCapacity[reg.AssignedCenter]; ok {
+				ca.Seats = capInfo.TotalSeats
+			}
+			bySitting[key] = ca
+		}
+		total, ok := byCenter[reg.AssignedCenter]
+		if !ok {
+			total = &CenterAttendance{}
+			if capInfo, ok := h.centerCapacity[reg.AssignedCenter]; ok {
+				byCenter[reg.AssignedCenter] = total
+				total.Booked = capInfo.BookedSeats
+			}
+		}
+		ca.Registered++
+		total.CenterRegistered++
+		rec, marked := h.attendance[reg.ID]
+		switch {
+		case !marked:
+			ca.Unmarked++
+		case rec.Status == AttendancePresent:
+			ca.Present++
+			total.CenterMarked++
+		default:
+			ca.Absent++
+			total.CenterMarked++
+			ca.NoShows = append(ca.NoShows, reg)
+		}
+	}
+	report := make([]CenterAttendance, 0, len(bySitting))
+	for _, ca := range bySitting {
+		report = append(report, *ca)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		switch {
+		case a.City != b.City:
+			return a.City < b.City
+		case a.Center != b.Center:
+			return a.Center < b.Center
+		case a.Date != b.Date:
+			return a.Date < b.Date
+		}
+		return a.Slot < b.Slot
+	})
+	for i := range report {
+		if total, ok := byCenter[report[i].Center]; ok {
+			report[i].Booked, report[i].CenterRegistered, report[i].CenterMarked = total.Booked, total.CenterRegistered, total.CenterMarked
+			delete(byCenter, report[i].Center)
+		}
+	}
+	return report
+}
+
+// DisplayAttendanceReport prints per-center attendance, no-shows and reconciliation issues
+func (h *ExamCenterHandler) DisplayAttendanceReport(center string) {
+	report := h.AttendanceReport(center)
+	if len(report) == 0 {
+		fmt.Println("No registrations found.")
+		return
+	}
+	fmt.Println("\n" + strings.Repeat("=", 70))
+	fmt.Println("EXAM-DAY ATTENDANCE REPORT")
+	fmt.Println(strings.Repeat("=", 70))
+	for _, ca := range report {
+		fmt.Printf("\n🏢 %s, %s — %s %s\n", ca.Center, ca.City, ca.Date, ca.Slot)
+		fmt.Printf("   Registered: %d | Present: %d | Absent: %d | Unmarked: %d | No-show rate: %.1f%%\n",
+			ca.Registered, ca.Present, ca.Absent, ca.Unmarked, ca.NoShowRate())
+		for _, reg := range ca.NoShows {
+			fmt.Printf("   ✗ %s - %s (%s)\n", reg.ID, reg.StudentName, reg.ExamType.Code)
+		}
+		for _, issue := range ca.Issues() {
+			fmt.Printf("   ⚠️  %s\n", issue)
+		}
+	}
+}
+
+// ProcessAttendance runs the interactive attendance menu: mark one candidate, import a CSV or show the report
+func (h *ExamCenterHandler) ProcessAttendance() error {
+	fmt.Println("=== Exam-Day Attendance ===")
+	fmt.Println("1. Mark a candidate")
+	fmt.Println("2. Import CSV (registration_id,status)")
+	fmt.Println("3. No-show and reconciliation report")
+	choice, err := h.GetUserInput("Select (1-3): ")
+	if err != nil {
+		return err
+	}
+	switch choice {
+	case "1":
+		id, err := h.GetUserInput("Registration ID: ")
+		if err != nil {
+			return err
+		}
+		statusInput, err := h.GetUserInput("Status (present/absent): ")
+		if err != nil {
+			return err
+		}
+		status, err := ParseAttendanceStatus(statusInput)
+		if err != nil {
+			return err
+		}
+		if err := h.MarkAttendance(id, status, "cli"); err != nil {
+			return err
+		}
+		fmt.Printf("✅ %s marked %s\n", id, status)
+	case "2":
+		path, err := h.GetUserInput("CSV file: ")
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("error opening %s: %v", path, err)
+		}
+		defer f.Close()
+		marked, errs := h.ImportAttendanceCSV(f, "cli", nil)
+		fmt.Printf("✅ %d candidates marked\n", marked)
+		for _, e := range errs {
+			fmt.Printf("❌ %v\n", e)
+		}
+	case "3":
+		center, err := h.GetUserInput("Center name [default: all]: ")
+		if err != nil {
+			return err
+		}
+		h.DisplayAttendanceReport(center)
+	default:
+		return fmt.Errorf("invalid option")
+	}
+	return nil
+} 
\ No newline at end of file
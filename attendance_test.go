@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAttendanceReportReconcilesPerSitting(t *testing.T) {
+	h := NewExamCenterHandler()
+	center := "Pune University Center"
+	// Seats booked by other registrations and seeded bookings do not belong to any sitting
+	h.bookSeat(center)
+	h.bookSeat(center)
+	sittings := []struct{ id, date, slot string }{
+		{"A-1", "2024-06-02", "09:30-12:30"},
+		{"A-2", "2024-06-02", "09:30-12:30"},
+		{"B-1", "2024-06-02", "14:30-17:30"},
+		{"C-1", "2024-06-03", "09:30-12:30"},
+	}
+	for _, s := range sittings {
+		h.registrations = append(h.registrations, ExamRegistration{ID: s.id, AssignedCenter: center, AssignedCity: "Pune", ExamDate: s.date, TimeSlot: s.slot})
+	}
+	for id, status := range map[string]AttendanceStatus{"A-1": AttendancePresent, "A-2": AttendanceAbsent, "B-1": AttendancePresent} {
+		if err := h.MarkAttendance(id, status, "invigilator"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	report := h.AttendanceReport(center)
+	want := []struct {
+		date, slot string
+		registered int
+		issues     []string
+	}{
+		{"2024-06-02", "09:30-12:30", 2, nil},
+		{"2024-06-02", "14:30-17:30", 1, nil},
+		{"2024-06-03", "09:30-12:30", 1, []string{"1 of 1 registered candidates not marked"}},
+	}
+	if len(report) != len(want) {
+		t.Fatalf("%d rows, want one per sitting (%d)", len(report), len(want))
+	}
+	for i, w := range want {
+		r := report[i]
+		if r.Date != w.date || r.Slot != w.slot || r.Registered != w.registered {
+			t.Errorf("row %d: %s %s with %d registered, want %s %s with %d", i, r.Date, r.Slot, r.Registered, w.date, w.slot, w.registered)
+		}
+		if got := r.Issues(); !reflect.DeepEqual(got, w.issues) {
+			t.Errorf("%s %s: issues %q, want %q", w.date, w.slot, got, w.issues)
+		}
+	}
+	full := CenterAttendance{Registered: 3, Present: 3, Seats: 2}
+	if got := len(full.Issues()); got != 2 {
+		t.Errorf("overfull sitting: %d issues (%q), want 2", got, full.Issues())
+	}
+}
+func TestAttendanceReportFlagsSeatCounterDrift(t *testing.T) {
+	h := NewExamCenterHandler()
+	center := "Pune University Center"
+	for _, s := range []struct{ id, date string }{{"A-1", "2024-06-02"}, {"A-2", "2024-06-02"}, {"B-1", "2024-06-03"}} {
+		h.registrations = append(h.registrations, ExamRegistration{ID: s.id, AssignedCenter: center, AssignedCity: "Pune", ExamDate: s.date, TimeSlot: "09:30-12:30"})
+		if err := h.MarkAttendance(s.id, AttendancePresent, "invigilator"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	drift := "center has 3 registrations (3 marked) but its seat counter shows 2 booked"
+	tests := []struct {
+		name   string
+		booked int
+		issues [][]string // per sitting row
+	}{
+		{"counter covers the registrations", 3, [][]string{nil, nil}},
+		{"counter includes bookings from outside", 10, [][]string{nil, nil}},
+		{"counter lost a booking", 2, [][]string{{drift}, nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h.centerCapacity[center] = CenterCapacity{TotalSeats: 100, BookedSeats: tt.booked, AvailableSeats: 100 - tt.booked}
+			report := h.AttendanceReport(center)
+			if len(report) != len(tt.issues) {
+				t.Fatalf("%d rows, want %d", len(report), len(tt.issues))
+			}
+			for i, want := range tt.issues {
+				if got := report[i].Issues(); !reflect.DeepEqual(got, want) {
+					t.Errorf("row %d (%s): issues %q, want %q", i, report[i].Date, got, want)
+				}
+			}
+		})
+	}
+} 
\ No newline at end of file
@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A candidate is one person across exams. Roll numbers are issued per exam, so registrations are tied
+// together by the candidate's name together with their mobile number, email or roll number. A shared
+// contact alone is not enough: coaching institutes often register many students with one number.
+
+// LocalTravelTime is allowed between two centers in the same city
+const LocalTravelTime = time.Hour
+
+// Travel between exam cities is estimated as the faster of the road and a flight
+const (
+	RoadSpeedKmh   = 50.0          // average door to door by road or rail
+	FlightSpeedKmh = 700.0         // in the air
+	FlightOverhead = 4 * time.Hour // getting to, through and from the airports
+)
+
+// EstimateTravel returns how long a candidate needs to cover km between two exam cities
+func EstimateTravel(km float64) time.Duration {
+	hours := min(km/RoadSpeedKmh, FlightOverhead.Hours()+km/FlightSpeedKmh)
+	return time.Duration(hours * float64(time.Hour))
+}
+
+// ClashKind says why two registrations of one candidate cannot both be sat
+type ClashKind string
+
+const (
+	ClashOverlap ClashKind = "overlap" // the exam slots overlap
+	ClashTravel  ClashKind = "travel"  // too little time to travel between the centers and report
+)
+
+// Clash is a pair of registrations of the same candidate that cannot both be sat
+type Clash struct {
+	CandidateID string
+	Kind        ClashKind
+	First       ExamRegistration // the exam that starts first
+	Second      ExamRegistration
+	Detail      string
+}
+
+// samePerson reports whether a registration was made by the student: the names match and so does
+// the mobile number, the email or the roll number
+func samePerson(reg ExamRegistration, s StudentInfo) bool {
+	if !sameName(reg.StudentName, s.Name) {
+		return false
+	}
+	switch {
+	case s.Phone != "" && reg.Phone == s.Phone:
+		return true
+	case s.Email != "" && strings.EqualFold(reg.Email, s.Email):
+		return true
+	}
+	return s.RollNumber != "" && reg.RollNumber == s.RollNumber
+}
+
+// sameName compares two names ignoring case and spacing
+func sameName(a, b string) bool {
+	a, b = strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " ")
+	return a != "" && strings.EqualFold(a, b)
+}
+
+// candidateFor returns the candidate ID of an earlier registration by the same person, or a new one
+func (h *ExamCenterHandler) candidateFor(s StudentInfo) string {
+	next := 0
+	for _, reg := range h.registrations {
+		if reg.CandidateID == "" {
+			continue
+		}
+		if samePerson(reg, s) {
+			return reg.CandidateID
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(reg.CandidateID, "C")); err == nil && n > next {
+			next = n
+		}
+	}
+	return fmt.Sprintf("C%06d", next+1)
+}
+
+// backfillCandidates gives registrations made before candidate IDs existed their identity, oldest first
+func (h *ExamCenterHandler) backfillCandidates() {
+	for i, reg := range h.registrations {
+		if reg.CandidateID == "" {
+			s := StudentInfo{Name: reg.StudentName, RollNumber: reg.RollNumber, Phone: reg.Phone, Email: reg.Email}
+			h.registrations[i].CandidateID = h.candidateFor(s)
+		}
+	}
+}
+
+// CandidateRegistrations returns the registrations of one candidate in exam order
+func (h *ExamCenterHandler) CandidateRegistrations(candidateID string) []ExamRegistration {
+	var regs []ExamRegistration
+	for _, reg := range h.registrations {
+		if reg.CandidateID == candidateID {
+			regs = append(regs, reg)
+		}
+	}
+	sort.SliceStable(regs, func(i, j int) bool {
+		return regs[i].ExamDate+regs[i].TimeSlot < regs[j].ExamDate+regs[j].TimeSlot
+	})
+	return regs
+}
+
+// SetRegistrationCandidate moves a registration to another candidate, or to a new one when candidateID is
+// empty. It corrects the automatic matching when two people share a contact or one person used two.
+func (h *ExamCenterHandler) SetRegistrationCandidate(actor, id, candidateID string) (ExamRegistration, error) {
+	i, err := h.registrationIndex(id)
+	if err != nil {
+		return ExamRegistration{}, err
+	}
+	candidateID = strings.ToUpper(strings.TrimSpace(candidateID))
+	if candidateID == "" {
+		candidateID = h.candidateFor(StudentInfo{})
+	} else if len(h.CandidateRegistrations(candidateID)) == 0 {
+		return ExamRegistration{}, fmt.Errorf("candidate '%s' not found", candidateID)
+	}
+	previous := h.registrations[i].CandidateID
+	h.registrations[i].CandidateID = candidateID
+	return h.registrations[i], h.commit(actor, "registration.candidate", id, previous+" -> "+candidateID)
+}
+
+// slotTimes returns when an exam slot such as "09:00-12:00" starts and ends on a YYYY-MM-DD date
+func slotTimes(date, slot string) (time.Time, time.Time, bool) {
+	from, to, ok := strings.Cut(strings.ReplaceAll(slot, " ", ""), "-")
+	start, err1 := time.Parse("2006-01-02 15:04", date+" "+from)
+	end, err2 := time.Parse("2006-01-02 15:04", date+" "+to)
+	return start, end, ok && err1 == nil && err2 == nil && end.After(start)
+}
+
+// travelTime estimates the trip from the center of one registration to that of another
+func (h *ExamCenterHandler) travelTime(a, b ExamRegistration) (time.Duration, float64) {
+	switch {
+	case a.AssignedCenter == b.AssignedCenter:
+		return 0, 0
+	case a.AssignedCity == b.AssignedCity:
+		return LocalTravelTime, 0
+	}
+	from, ok1 := h.cities[a.AssignedCity]
+	to, ok2 := h.cities[b.AssignedCity]
+	if !ok1 || !ok2 {
+		return 0, 0
+	}
+	km := h.calculateDistance(from, to)
+	return EstimateTravel(km), km
+}
+
+// clashBetween checks two registrations of the same candidate
+func (h *ExamCenterHandler) clashBetween(a, b ExamRegistration) (Clash, bool) {
+	aStart, aEnd, ok1 := slotTimes(a.ExamDate, a.TimeSlot)
+	bStart, bEnd, ok2 := slotTimes(b.ExamDate, b.TimeSlot)
+	if !ok1 || !ok2 {
+		return Clash{}, false
+	}
+	if bStart.Before(aStart) {
+		a, b = b, a
+		aEnd, bStart = bEnd, aStart
+	}
+	c := Clash{CandidateID: a.CandidateID, First: a, Second: b}
+	if bStart.Before(aEnd) {
+		c.Kind = ClashOverlap
+		c.Detail = fmt.Sprintf("%s on %s %s overlaps %s on %s %s", a.ExamType.Label(), a.ExamDate, a.TimeSlot,
+			b.ExamType.Label(), b.ExamDate, b.TimeSlot)
+		return c, true
+	}
+	travel, km := h.travelTime(a, b)
+	if gap := bStart.Sub(aEnd); gap < travel+ReportingLeadTime {
+		c.Kind = ClashTravel
+		c.Detail = fmt.Sprintf("%s ends at %s in %s and %s starts at %s in %s: %s apart, but the trip (%.0f km) and reporting take about %s",
+			a.ExamType.Label(), aEnd.Format("15:04"), a.AssignedCity, b.ExamType.Label(), bStart.Format("15:04"), b.AssignedCity,
+			roughDuration(gap), km, roughDuration(travel+ReportingLeadTime))
+		return c, true
+	}
+	return Clash{}, false
+}
+
+// roughDuration writes a duration in hours and minutes, e.g. "7h30m"
+func roughDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours, mins := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case hours >= 48:
+		return fmt.Sprintf("%d days", hours/24)
+	case mins == 0:
+		return fmt.Sprintf("%dh", hours)
+	case hours == 0:
+		return fmt.Sprintf("%dm", mins)
+	}
+	return fmt.Sprintf("%dh%02dm", hours, mins)
+}
+
+// Clashes returns every clash between registrations of the same candidate, earliest first.
+// allowed limits the result to clashes where the caller may see at least one side; nil allows all.
+func (h *ExamCenterHandler) Clashes(allowed func(ExamRegistration) bool) []Clash {
+	byCandidate := make(map[string][]ExamRegistration)
+	for _, reg := range h.registrations {
+		if reg.CandidateID != "" {
+			byCandidate[reg.CandidateID] = append(byCandidate[reg.CandidateID], reg)
+		}
+	}
+	var clashes []Clash
+	for _, regs := range byCandidate {
+		for i := range regs {
+			for j := i + 1; j < len(regs); j++ {
+				c, ok := h.clashBetween(regs[i], regs[j])
+				if ok && (allowed == nil || allowed(c.First) || allowed(c.Second)) {
+					clashes = append(clashes, c)
+				}
+			}
+		}
+	}
+	sort.Slice(clashes, func(i, j int) bool {
+		a, b := clashes[i].First, clashes[j].First
+		if a.ExamDate+a.TimeSlot != b.ExamDate+b.TimeSlot {
+			return a.ExamDate+a.TimeSlot < b.ExamDate+b.TimeSlot
+		}
+		return clashes[i].Second.ID < clashes[j].Second.ID
+	})
+	return clashes
+}
+
+// RegistrationClashes returns the clashes one registration is part of
+func (h *ExamCenterHandler) RegistrationClashes(id string) []Clash {
+	var clashes []Clash
+	for _, c := range h.Clashes(nil) {
+		if c.First.ID == id || c.Second.ID == id {
+			clashes = append(clashes, c)
+		}
+	}
+	return clashes
+}
+
+// clashesWithAny reports whether reg clashes with any of others
+func (h *ExamCenterHandler) clashesWithAny(reg ExamRegistration, others []ExamRegistration) bool {
+	for _, o := range others {
+		if _, ok := h.clashBetween(reg, o); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// examDates lists the days of a schedule, at most a year of them
+func examDates(s ExamSchedule) []string {
+	start, err1 := time.Parse("2006-01-02", s.StartDate)
+	end, err2 := time.Parse("2006-01-02", s.EndDate)
+	if err1 != nil {
+		return nil
+	}
+	if err2 != nil || end.Before(start) {
+		end = start
+	}
+	var dates []string
+	for d := start; !d.After(end) && len(dates) < 366; d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format("2006-01-02"))
+	}
+	return dates
+}
+
+// chooseSlot keeps a registration's date and slot unless they clash with another exam of the same
+// candidate or its center is closed then. Then it takes the first date and slot of the exam that
+// is open and does not clash; when every open choice clashes the registration keeps its slot, or
+// the first open one if its own is closed, and the clash is reported. ok is false when the center
+// is closed for every sitting of the exam.
+func (h *ExamCenterHandler) chooseSlot(reg ExamRegistration) (date, slot string, ok bool) {
+	var others []ExamRegistration
+	for _, o := range h.registrations {
+		if o.CandidateID == reg.CandidateID && o.ID != reg.ID {
+			others = append(others, o)
+		}
+	}
+	_, closed := h.closureFor(reg.AssignedCenter, reg.ExamDate, reg.TimeSlot)
+	if !closed && !h.clashesWithAny(reg, others) {
+		return reg.ExamDate, reg.TimeSlot, true
+	}
+	var firstOpen ExamRegistration
+	for _, date := range examDates(reg.ExamType.Schedule) {
+		for _, slot := range reg.ExamType.Schedule.TimeSlots {
+			if _, shut := h.closureFor(reg.AssignedCenter, date, slot); shut {
+				continue
+			}
+			try := reg
+			try.ExamDate, try.TimeSlot = date, slot
+			if !h.clashesWithAny(try, others) {
+				return date, slot, true
+			}
+			if firstOpen.ExamDate == "" {
+				firstOpen = try
+			}
+		}
+	}
+	switch {
+	case !closed:
+		return reg.ExamDate, reg.TimeSlot, true
+	case firstOpen.ExamDate != "":
+		return firstOpen.ExamDate, firstOpen.TimeSlot, true
+	}
+	return reg.ExamDate, reg.TimeSlot, false
+} 
\ No newline at end of file
@@ -0,0 +1,84 @@
+package handler
+
+import "testing"
+
+func TestSamePerson(t *testing.T) {
+	reg := ExamRegistration{StudentName: "Asha Verma", RollNumber: "NEET-001", Phone: "9876543210", Email: "office@coaching.example"}
+	tests := []struct {
+		name string
+		s    StudentInfo
+		want bool
+	}{
+		{"same phone and name", StudentInfo{Name: "asha  verma", RollNumber: "JEE-77", Phone: "9876543210"}, true},
+		{"same email and name", StudentInfo{Name: "Asha Verma", RollNumber: "JEE-77", Email: "Office@Coaching.example"}, true},
+		{"same roll and name", StudentInfo{Name: "Asha Verma", RollNumber: "NEET-001"}, true},
+		{"institute phone, other student", StudentInfo{Name: "Ravi Kumar", RollNumber: "JEE-78", Phone: "9876543210"}, false},
+		{"institute email, other student", StudentInfo{Name: "Ravi Kumar", RollNumber: "JEE-78", Email: "office@coaching.example"}, false},
+		{"same roll, other name", StudentInfo{Name: "Ravi Kumar", RollNumber: "NEET-001"}, false},
+		{"same name only", StudentInfo{Name: "Asha Verma", RollNumber: "JEE-79", Phone: "9123456780"}, false},
+		{"no name", StudentInfo{RollNumber: "NEET-001", Phone: "9876543210"}, false},
+	}
+	for _, tt := range tests {
+		if got := samePerson(reg, tt.s); got != tt.want {
+			t.Errorf("%s: samePerson = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCandidateForKeepsSharedContactsApart(t *testing.T) {
+	h := NewExamCenterHandler()
+	h.registrations = []ExamRegistration{{ID: "NEET-A-1", StudentName: "Asha Verma", RollNumber: "A", Phone: "9876543210", CandidateID: "C000001"}}
+	if got := h.candidateFor(StudentInfo{Name: "Ravi Kumar", RollNumber: "B", Phone: "9876543210"}); got != "C000002" {
+		t.Errorf("another student on the institute's phone got %s, want a new candidate C000002", got)
+	}
+	if got := h.candidateFor(StudentInfo{Name: "Asha Verma", RollNumber: "J", Phone: "9876543210"}); got != "C000001" {
+		t.Errorf("the same student on another exam got %s, want C000001", got)
+	}
+}
+
+// slotHandler has exam X on two days with two slots, and a registration for it at Center A on day one, morning
+func slotHandler(closures ...CenterClosure) (*ExamCenterHandler, ExamRegistration) {
+	h := NewExamCenterHandler()
+	exam := ExamType{Code: "X", Schedule: ExamSchedule{StartDate: "2026-05-04", EndDate: "2026-05-05", TimeSlots: []string{"09:00-12:00", "14:00-17:00"}}}
+	reg := ExamRegistration{ID: "X-1", CandidateID: "C000001", ExamType: exam, AssignedCenter: "Center A", AssignedCity: "Pune", ExamDate: "2026-05-04", TimeSlot: "09:00-12:00"}
+	h.registrations = []ExamRegistration{reg}
+	h.closures = closures
+	return h, reg
+}
+
+func TestChooseSlotAvoidsClosures(t *testing.T) {
+	morning, afternoon := "09:00-12:00", "14:00-17:00"
+	day1, day2 := "2026-05-04", "2026-05-05"
+	closed := func(date, slot string) CenterClosure {
+		return CenterClosure{Center: "Center A", Date: date, Slot: slot}
+	}
+	tests := []struct {
+		name     string
+		closures []CenterClosure
+		other    *ExamRegistration // another exam of the same candidate
+		date     string
+		slot     string
+		ok       bool
+	}{
+		{"open, no clash", nil, nil, day1, morning, true},
+		{"slot closed", []CenterClosure{closed(day1, morning)}, nil, day1, afternoon, true},
+		{"day closed", []CenterClosure{closed(day1, "")}, nil, day2, morning, true},
+		{"overlapping closure", []CenterClosure{closed(day1, "11:00-13:00")}, nil, day1, afternoon, true},
+		{"other center closed", []CenterClosure{{Center: "Center B", Date: day1}}, nil, day1, morning, true},
+		{"clash skips closed slots", []CenterClosure{closed(day1, afternoon), closed(day2, morning)},
+			&ExamRegistration{ID: "Y-1", CandidateID: "C000001", AssignedCenter: "Center A", AssignedCity: "Pune", ExamDate: day1, TimeSlot: "08:00-11:00"}, day2, afternoon, true},
+		{"every open slot clashes", []CenterClosure{closed(day1, morning), closed(day2, "")},
+			&ExamRegistration{ID: "Y-1", CandidateID: "C000001", AssignedCenter: "Center A", AssignedCity: "Pune", ExamDate: day1, TimeSlot: "13:00-18:00"}, day1, afternoon, true},
+		{"all closed", []CenterClosure{closed(day1, ""), closed(day2, "")}, nil, day1, morning, false},
+	}
+	for _, tt := range tests {
+		h, reg := slotHandler(tt.closures...)
+		if tt.other != nil {
+			h.registrations = append(h.registrations, *tt.other)
+		}
+		date, slot, ok := h.chooseSlot(reg)
+		if date != tt.date || slot != tt.slot || ok != tt.ok {
+			t.Errorf("%s: chooseSlot = %s %s %v, want %s %s %v", tt.name, date, slot, ok, tt.date, tt.slot, tt.ok)
+		}
+	}
+} 
\ No newline at end of file
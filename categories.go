@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Priority categories. Candidates in one are allocated before general candidates by the batch
+// allocator and the waitlist, and may take the seats a center holds back for them (PrioritySeats).
+// PwD and special-needs candidates are only placed at accessible centers, and candidates who write
+// with a scribe only at centers that can seat scribes. Women taking an exam with a WomenRadius are
+// placed within that radius whenever a center there has room.
+
+// Category is a priority category a candidate declares when registering
+type Category string
+
+const (
+	CategoryPwD          Category = "pwd"           // person with a disability
+	CategoryWoman        Category = "woman"         // see ExamType.WomenRadius
+	CategorySpecialNeeds Category = "special_needs" // e.g. a medical condition needing a ground-floor hall
+)
+
+// Categories lists the priority categories in display order
+var Categories = []Category{CategoryPwD, CategoryWoman, CategorySpecialNeeds}
+
+// Label is the category's display name
+func (c Category) Label() string {
+	switch c {
+	case CategoryPwD:
+		return "PwD"
+	case CategoryWoman:
+		return "Woman"
+	case CategorySpecialNeeds:
+		return "Special needs"
+	}
+	return string(c)
+}
+
+// ParseCategories reads a list such as "pwd, woman"; an empty list means a general candidate
+func ParseCategories(s string) ([]Category, error) {
+	var cats []Category
+	for _, f := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return r == ',' || r == ';' || r == ' ' }) {
+		c := Category(strings.ReplaceAll(f, "-", "_"))
+		switch c {
+		case CategoryPwD, CategoryWoman, CategorySpecialNeeds:
+		default:
+			return nil, fmt.Errorf("unknown category '%s' (use pwd, woman or special_needs)", f)
+		}
+		if !hasCategory(cats, c) {
+			cats = append(cats, c)
+		}
+	}
+	return cats, nil
+}
+
+func hasCategory(cats []Category, c Category) bool {
+	for _, have := range cats {
+		if have == c {
+			return true
+		}
+	}
+	return false
+}
+
+// WithCategories returns the preferences with the candidate's priority categories set from a list
+// as read by ParseCategories. A scribe is only arranged for PwD candidates.
+func (p StudentPreference) WithCategories(list string, scribe bool) (StudentPreference, error) {
+	cats, err := ParseCategories(list)
+	if err != nil {
+		return p, err
+	}
+	if scribe && !hasCategory(cats, CategoryPwD) {
+		return p, fmt.Errorf("a scribe is only arranged for PwD candidates")
+	}
+	p.Categories, p.NeedsScribe = cats, scribe
+	return p, nil
+}
+
+// Has reports whether the candidate declared a category
+func (p StudentPreference) Has(c Category) bool { return hasCategory(p.Categories, c) }
+
+// Priority reports whether the candidate is allocated ahead of general candidates
+func (p StudentPreference) Priority() bool { return len(p.Categories) > 0 }
+
+// NeedsAccessible reports whether the candidate may only be placed at an accessible center
+func (p StudentPreference) NeedsAccessible() bool {
+	return p.Has(CategoryPwD) || p.Has(CategorySpecialNeeds)
+}
+
+// CategoryList names the candidate's categories, e.g. "PwD, Woman", or "General"
+func (p StudentPreference) CategoryList() string {
+	if !p.Priority() {
+		return "General"
+	}
+	labels := make([]string, len(p.Categories))
+	for i, c := range p.Categories {
+		labels[i] = c.Label()
+	}
+	return strings.Join(labels, ", ")
+}
+
+// categoryCodes joins the categories as ParseCategories reads them, e.g. "pwd,woman"
+func (p StudentPreference) categoryCodes() string {
+	codes := make([]string, len(p.Categories))
+	for i, c := range p.Categories {
+		codes[i] = string(c)
+	}
+	return strings.Join(codes, ",")
+}
+
+// freeSeatsFor returns the seats of a center open to the candidate: general candidates cannot take
+// the seats held back for priority candidates
+func (h *ExamCenterHandler) freeSeatsFor(c ExamCenter, p StudentPreference) int {
+	free := h.centerCapacity[c.Name].AvailableSeats
+	if !p.Priority() {
+		free -= c.PrioritySeats
+	}
+	return free
+}
+
+// withinWomenRadius reports whether a city at distance km counts as near for a woman taking the exam
+func withinWomenRadius(ex ExamType, p StudentPreference, km float64) bool {
+	return ex.WomenRadius > 0 && p.Has(CategoryWoman) && km <= ex.WomenRadius
+}
+
+// SetCenterAccess records a center's accessibility, whether it can seat scribes and how many of its
+// free seats are held back for priority candidates
+func (h *ExamCenterHandler) SetCenterAccess(actor, name string, accessible, scribes bool, prioritySeats int) error {
+	center, ok := h.findCenter(name)
+	if !ok {
+		return fmt.Errorf("exam center '%s' not found", name)
+	}
+	if prioritySeats < 0 {
+		return fmt.Errorf("priority seats cannot be negative")
+	}
+	if total := h.centerCapacity[center.Name].TotalSeats; prioritySeats > total {
+		return fmt.Errorf("%s has only %d seats; cannot hold %d for priority candidates", center.Name, total, prioritySeats)
+	}
+	h.updateCenter(center, func(c *ExamCenter) {
+		c.Accessible, c.Scribes, c.PrioritySeats = accessible, scribes, prioritySeats
+	})
+	h.PromoteWaitlist()
+	details := fmt.Sprintf("accessible %t, scribes %t, %d priority seats", accessible, scribes, prioritySeats)
+	return h.commit(actor, "center.access", center.Name, details)
+} 
\ No newline at end of file
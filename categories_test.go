@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCategories(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []Category
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"PwD, woman", []Category{CategoryPwD, CategoryWoman}, false},
+		{"special-needs;pwd pwd", []Category{CategorySpecialNeeds, CategoryPwD}, false},
+		{"pwd, obc", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCategories(tt.in)
+		if !reflect.DeepEqual(got, tt.want) || (err != nil) != tt.wantErr {
+			t.Errorf("ParseCategories(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestWithCategories(t *testing.T) {
+	tests := []struct {
+		list       string
+		scribe     bool
+		wantErr    bool
+		accessible bool
+		label      string
+	}{
+		{"", false, false, false, "General"},
+		{"woman", false, false, false, "Woman"},
+		{"special_needs,woman", false, false, true, "Special needs, Woman"},
+		{"pwd", true, false, true, "PwD"},
+		{"woman", true, true, false, "General"},
+	}
+	for _, tt := range tests {
+		p, err := StudentPreference{}.WithCategories(tt.list, tt.scribe)
+		if (err != nil) != tt.wantErr || p.NeedsAccessible() != tt.accessible || p.CategoryList() != tt.label {
+			t.Errorf("%q with scribe %v: error %v, accessible %v, %q; want error %v, accessible %v, %q",
+				tt.list, tt.scribe, err, p.NeedsAccessible(), p.CategoryList(), tt.wantErr, tt.accessible, tt.label)
+		}
+	}
+}
+
+func TestCategoryAllocation(t *testing.T) {
+	pwd := StudentPreference{MaxDistance: 200, Categories: []Category{CategoryPwD}}
+	woman := StudentPreference{MaxDistance: 200, Categories: []Category{CategoryWoman}}
+	general := StudentPreference{MaxDistance: 200}
+	tests := []struct {
+		name   string
+		prefs  StudentPreference
+		setup  func(h *ExamCenterHandler, ex *ExamType, p *AllocationPolicy)
+		first  string
+		rules  map[string]string // center -> the rule that excludes it; "" when offered
+		reason map[string]string // center -> why it is excluded
+	}{
+		{
+			name:   "PwD candidates only at accessible centers",
+			prefs:  pwd,
+			first:  "Pune University Center",
+			rules:  map[string]string{"Shivaji Nagar Exam Hall": FilterAccess, "Navi Mumbai Central Exam Center": ""},
+			reason: map[string]string{"Shivaji Nagar Exam Hall": "not accessible"},
+		},
+		{
+			name:  "scribes only where they can be seated",
+			prefs: StudentPreference{MaxDistance: 200, Categories: []Category{CategoryPwD}, NeedsScribe: true},
+			setup: func(h *ExamCenterHandler, ex *ExamType, p *AllocationPolicy) {
+				if err := h.SetCenterAccess("admin", "Pune University Center", true, false, 10); err != nil {
+					t.Fatal(err)
+				}
+			},
+			first:  "Navi Mumbai Central Exam Center",
+			rules:  map[string]string{"Pune University Center": FilterAccess},
+			reason: map[string]string{"Pune University Center": "cannot seat scribes"},
+		},
+		{
+			name:  "held-back seats are not for general candidates",
+			prefs: general,
+			setup: func(h *ExamCenterHandler, ex *ExamType, p *AllocationPolicy) {
+				h.centerCapacity["Pune University Center"] = CenterCapacity{TotalSeats: 100, AvailableSeats: 10, BookedSeats: 90}
+			},
+			first:  "Shivaji Nagar Exam Hall",
+			rules:  map[string]string{"Pune University Center": FilterHasSeats},
+			reason: map[string]string{"Pune University Center": "full; the remaining seats are held for priority candidates"},
+		},
+		{
+			name:  "held-back seats go to priority candidates",
+			prefs: woman,
+			setup: func(h *ExamCenterHandler, ex *ExamType, p *AllocationPolicy) {
+				h.centerCapacity["Pune University Center"] = CenterCapacity{TotalSeats: 100, AvailableSeats: 10, BookedSeats: 90}
+			},
+			first: "Pune University Center",
+			rules: map[string]string{"Pune University Center": ""},
+		},
+	}
+	for _, tt := range tests {
+		h, ex := allocationHandler(t)
+		p := DefaultPolicy(ex.Code)
+		p.TieBreakers = []string{TieName}
+		if tt.setup != nil {
+			tt.setup(h, &ex, &p)
+		}
+		// the name tie-breaker would put Kothrud first; close it so the order is decided by the category rules
+		h.examCenters["Pune"][2].Disabled = true
+		res, err := h.allocate(p, "Pune", ex, tt.prefs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Verdicts) == 0 || res.Verdicts[0].Center.Name != tt.first {
+			t.Errorf("%s: first center is not %s: %+v", tt.name, tt.first, res.Nearest)
+		}
+		got := make(map[string]CenterVerdict)
+		for _, v := range res.Verdicts {
+			got[v.Center.Name] = v
+		}
+		for center, rule := range tt.rules {
+			if v := got[center]; v.Rule != rule || (rule == "") != (v.Rank > 0) {
+				t.Errorf("%s: %s has rule %q and rank %d, want rule %q", tt.name, center, v.Rule, v.Rank, rule)
+			}
+		}
+		for center, reason := range tt.reason {
+			if v := got[center]; v.Reason != reason {
+				t.Errorf("%s: %s excluded because %q, want %q", tt.name, center, v.Reason, reason)
+			}
+		}
+	}
+}
+
+func TestWomenRadius(t *testing.T) {
+	woman := StudentPreference{MaxDistance: 200, Categories: []Category{CategoryWoman}}
+	tests := []struct {
+		name   string
+		radius float64
+		prefs  StudentPreference
+		first  string
+		behind string // why the first center outside Pune is behind
+	}{
+		{"general candidate", 50, StudentPreference{MaxDistance: 200}, "Navi Mumbai Central Exam Center", ""},
+		{"no radius for the exam", 0, woman, "Navi Mumbai Central Exam Center", ""},
+		{"woman within the radius", 50, woman, "Kothrud Sports Complex", "outside the women's radius"},
+	}
+	for _, tt := range tests {
+		h, ex := allocationHandler(t)
+		ex.WomenRadius = tt.radius
+		// rank by how full a center is, so the emptier Navi Mumbai center beats the Pune ones
+		p := DefaultPolicy(ex.Code)
+		p.Weights, p.TieBreakers = PolicyWeights{FillPercent: 1}, []string{TieName}
+		for _, c := range h.examCenters["Pune"] {
+			h.centerCapacity[c.Name] = CenterCapacity{TotalSeats: 100, AvailableSeats: 50, BookedSeats: 50}
+		}
+		h.centerCapacity["Navi Mumbai Central Exam Center"] = CenterCapacity{TotalSeats: 100, AvailableSeats: 100}
+		res, err := h.allocate(p, "Pune", ex, tt.prefs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Verdicts[0].Center.Name != tt.first {
+			t.Errorf("%s: first center %s, want %s", tt.name, res.Verdicts[0].Center.Name, tt.first)
+		}
+		for _, v := range res.Verdicts {
+			if v.Rank > 1 && v.City != "Pune" && tt.behind != "" {
+				if v.Behind != tt.behind {
+					t.Errorf("%s: %s is behind %q, want %q", tt.name, v.Center.Name, v.Behind, tt.behind)
+				}
+				break
+			}
+		}
+	}
+}
+
+func TestSetCenterAccess(t *testing.T) {
+	h := NewExamCenterHandler()
+	const center = "Shivaji Nagar Exam Hall"
+	total := h.centerCapacity[center].TotalSeats
+	tests := []struct {
+		name     string
+		priority int
+		wantErr  bool
+	}{
+		{"negative", -1, true},
+		{"more than the center has", total + 1, true},
+		{"every seat", total, false},
+		{"some seats", 5, false},
+	}
+	for _, tt := range tests {
+		if err := h.SetCenterAccess("admin", center, true, true, tt.priority); (err != nil) != tt.wantErr {
+			t.Errorf("%s: error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+	if c, _ := h.findCenter(center); !c.Accessible || !c.Scribes || c.PrioritySeats != 5 {
+		t.Errorf("center %+v, want accessible with scribes and 5 priority seats", c)
+	}
+	if err := h.SetCenterAccess("admin", "Nowhere Hall", true, true, 0); err == nil {
+		t.Error("unknown center, want an error")
+	}
+} 
\ No newline at end of file
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"exam-center-assignment/internal/handler"
+)
+
+// cmdAnalytics prints travel distance, first-preference, fill rate and fairness statistics
+func cmdAnalytics(args []string) int {
+	fs := flag.NewFlagSet("analytics", flag.ExitOnError)
+	f := filterFlags(fs)
+	_ = fs.Parse(args)
+	if err := f.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	a := h.Analytics(*f, nil)
+	printGroup := func(g handler.GroupAnalytics) {
+		d := g.Distance
+		fmt.Printf("%-20s %6d %8.1f %8.1f %8.1f %8.1f %9.1f%%\n", clip(g.Name, 20), d.Count, d.Mean, d.P50, d.P95, d.Max, g.FirstPreferencePct)
+	}
+	header := func(title string) {
+		fmt.Printf("\n%-20s %6s %8s %8s %8s %8s %10s\n", title, "Count", "Mean km", "p50", "p95", "Max", "Nearest")
+	}
+	header("Exam")
+	for _, g := range a.Exams {
+		printGroup(g)
+	}
+	printGroup(a.Overall)
+	header("Home state")
+	for _, g := range a.States {
+		printGroup(g)
+	}
+	fmt.Printf("\nDistance inequality: Gini %.2f", a.Gini)
+	if a.StateSpread > 0 {
+		fmt.Printf(", farthest-travelling state %.1f× the nearest", a.StateSpread)
+	}
+	fmt.Printf("\nCenters: %d active, mean fill %.1f%%\n", len(a.Centers), a.MeanFillPct)
+	for _, b := range a.FillBuckets {
+		fmt.Printf("  %-10s %d\n", b.Label, b.Count)
+	}
+	return 0
+} 
\ No newline at end of file
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"exam-center-assignment/internal/handler"
+)
+
+// cmdCandidate lists exam clashes and the registrations of one candidate: candidate clashes|show|link
+func cmdCandidate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: examcenterhub candidate clashes [-exam CODE]")
+		fmt.Fprintln(os.Stderr, "       examcenterhub candidate show CANDIDATE|REGISTRATION")
+		fmt.Fprintln(os.Stderr, "       examcenterhub candidate link REGISTRATION [CANDIDATE]  (no candidate: split off as a new one)")
+		return 2
+	}
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	sub, args := args[0], args[1:]
+	fs := flag.NewFlagSet("candidate "+sub, flag.ExitOnError)
+	exam := fs.String("exam", "", "only clashes involving this exam code (clashes)")
+	_ = fs.Parse(args)
+
+	switch {
+	case sub == "clashes":
+		var allowed func(handler.ExamRegistration) bool
+		if *exam != "" {
+			allowed = handler.RegistrationFilter{Exam: *exam}.Match
+		}
+		clashes := h.Clashes(allowed)
+		for _, c := range clashes {
+			fmt.Printf("%s %-7s %s\n        %s / %s\n", c.CandidateID, c.Kind, c.Detail, c.First.ID, c.Second.ID)
+		}
+		if len(clashes) == 1 {
+			fmt.Println("1 clash")
+		} else {
+			fmt.Printf("%d clashes\n", len(clashes))
+		}
+	case sub == "show" && fs.NArg() == 1:
+		id := fs.Arg(0)
+		if reg, lookupErr := h.GetRegistration(id); lookupErr == nil {
+			id = reg.CandidateID
+		}
+		regs := h.CandidateRegistrations(id)
+		if len(regs) == 0 {
+			err = fmt.Errorf("candidate or registration '%s' not found", id)
+			break
+		}
+		fmt.Printf("Candidate %s\n", id)
+		for _, r := range regs {
+			fmt.Printf("  %-28s %-10s %-10s %-11s %-20s %s\n", r.ID, r.ExamType.Label(), r.ExamDate, r.TimeSlot, r.AssignedCity, r.StudentName)
+		}
+		for _, r := range regs {
+			for _, c := range h.RegistrationClashes(r.ID) {
+				if c.First.ID == r.ID {
+					fmt.Printf("⚠️  %s\n", c.Detail)
+				}
+			}
+		}
+	case sub == "link" && (fs.NArg() == 1 || fs.NArg() == 2):
+		var reg handler.ExamRegistration
+		if reg, err = h.SetRegistrationCandidate(cliActor(), fs.Arg(0), fs.Arg(1)); err == nil {
+			fmt.Printf("✅ %s now belongs to candidate %s\n", reg.ID, reg.CandidateID)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown or incomplete candidate command %q\n", sub)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+} 
\ No newline at end of file
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"exam-center-assignment/internal/handler"
+)
+
+// cmdClosure closes exam centers for a date or slot and moves their candidates: closure list|close|reopen
+func cmdClosure(args []string) int {
+	if len(args) == 0 || (args[0] != "list" && args[0] != "close" && args[0] != "reopen") {
+		fmt.Fprintln(os.Stderr, "Usage: examcenterhub closure list")
+		fmt.Fprintln(os.Stderr, "       examcenterhub closure close [-slot 09:00-12:00] [-reason TEXT] [-apply] CENTER DATE")
+		fmt.Fprintln(os.Stderr, "       examcenterhub closure reopen [-slot 09:00-12:00] CENTER DATE")
+		return 2
+	}
+	sub, args := args[0], args[1:]
+	fs := flag.NewFlagSet("closure "+sub, flag.ExitOnError)
+	slot := fs.String("slot", "", "close only this time slot (default the whole day)")
+	reason := fs.String("reason", "", "why the center is closed, e.g. flood; shown to the candidates moved (close)")
+	apply := fs.Bool("apply", false, "close the center and move its candidates instead of only previewing the moves (close)")
+	_ = fs.Parse(args)
+	if sub != "list" && fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	switch sub {
+	case "list":
+		closures := h.Closures()
+		for _, c := range closures {
+			fmt.Printf("%-32s %-12s %-24s %s (by %s)\n", c.Center, c.City, c.Sitting(), c.Reason, c.ClosedBy)
+		}
+		if len(closures) == 0 {
+			fmt.Println("No centers are closed.")
+		}
+		return 0
+	case "reopen":
+		if err = h.ReopenCenter(cliActor(), fs.Arg(0), fs.Arg(1), *slot); err == nil {
+			fmt.Println("✅ center reopened; candidates moved away keep their new centers")
+		}
+	default:
+		var impact handler.ClosureImpact
+		if *apply {
+			impact, err = h.CloseCenter(cliActor(), fs.Arg(0), fs.Arg(1), *slot, *reason)
+		} else {
+			impact, err = h.PreviewCenterClosure(fs.Arg(0), fs.Arg(1), *slot, *reason)
+		}
+		if err == nil {
+			printClosureImpact(impact, *apply)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// printClosureImpact lists each registration at the closed center with where it goes
+func printClosureImpact(impact handler.ClosureImpact, applied bool) {
+	for _, m := range impact.Moves {
+		reg := m.Registration
+		if m.Center == "" {
+			fmt.Printf("! %-24s %-16s stays: %s\n", reg.ID, reg.StudentCity, m.Problem)
+			continue
+		}
+		line := fmt.Sprintf("> %-24s %-16s -> %s, %s (%.0f km)", reg.ID, reg.StudentCity, m.Center, m.City, m.Distance)
+		if m.ExamDate != reg.ExamDate || m.TimeSlot != reg.TimeSlot {
+			line += fmt.Sprintf(", now %s %s", m.ExamDate, m.TimeSlot)
+		}
+		fmt.Println(line)
+	}
+	if !applied {
+		fmt.Printf("Closing %s on %s would affect %s\n", impact.Closure.Center, impact.Closure.Sitting(), impact.Summary())
+		fmt.Println("Nothing was changed; run again with -apply to close the center and notify the candidates moved.")
+		return
+	}
+	fmt.Printf("✅ %s closed on %s — %s\n", impact.Closure.Center, impact.Closure.Sitting(), impact.Summary())
+} 
\ No newline at end of file
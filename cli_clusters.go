@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"exam-center-assignment/internal/handler"
+)
+
+// cmdCluster manages the metro clusters used by exams that exclude the candidate's whole metro area: cluster list|set|remove
+func cmdCluster(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: examcenterhub cluster list")
+		fmt.Fprintln(os.Stderr, "       examcenterhub cluster set NAME CITY,CITY[,...]")
+		fmt.Fprintln(os.Stderr, "       examcenterhub cluster remove NAME")
+		return 2
+	}
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	sub, args := args[0], args[1:]
+	switch {
+	case sub == "list":
+		for _, c := range h.MetroClusters() {
+			fmt.Printf("%-28s %s\n", c.Name, strings.Join(c.Cities, ", "))
+		}
+	case sub == "set" && len(args) == 2:
+		if err = h.SetMetroCluster(cliActor(), args[0], strings.Split(args[1], ",")); err == nil {
+			fmt.Printf("✅ cluster %s saved\n", args[0])
+		}
+	case sub == "remove" && len(args) == 1:
+		if err = h.RemoveMetroCluster(cliActor(), args[0]); err == nil {
+			fmt.Printf("✅ cluster %s removed\n", args[0])
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown or incomplete cluster command %q\n", sub)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+} 
\ No newline at end of file
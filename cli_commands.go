@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"exam-center-assignment/internal/handler"
+)
+
+// command is a non-interactive subcommand of the CLI
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+var commands = []command{
+	{"verify", "verify a scanned admit card payload at the center gate", cmdVerify},
+	{"pubkey", "print the admit card public key to hand out to centers", cmdPubkey},
+	{"user", "add, list, disable or reset web UI accounts", cmdUser},
+	{"notify", "send due exam reminders and retry failed messages when no web server runs", cmdNotify},
+	{"webhook", "manage exam body webhooks and replay failed deliveries", cmdWebhook},
+	{"import", "check, register or queue candidates from a CSV file", cmdImport},
+	{"allocate", "assign centers to all queued imported candidates", cmdAllocate},
+	{"export", "export registrations or center utilization as csv, jsonl or xlsx", cmdExport},
+	{"registrations", "search, sort and page through registrations", cmdRegistrations},
+	{"explain", "show why a registration was given its center", cmdExplain},
+	{"analytics", "travel distance, preference, fill rate and fairness statistics", cmdAnalytics},
+	{"geo", "export cities and centers as geojson or kml, or review and apply an edited file", cmdGeo},
+	{"cluster", "list and edit the metro clusters used by the home city policy", cmdCluster},
+	{"exam", "list, add and edit exam types and their yearly editions", cmdExam},
+	{"candidate", "find exam clashes of candidates registered for several exams", cmdCandidate},
+	{"policy", "show, set or dry-run the allocation rule file of an exam type", cmdPolicy},
+	{"closure", "close a center for a date or slot and move its candidates, with a preview", cmdClosure},
+	{"plan", "simulate forecast demand against the seats and try adding seats in cities", cmdPlan},
+}
+
+// runCommand dispatches a subcommand and returns the process exit code
+func runCommand(args []string) int {
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:])
+		}
+	}
+	if args[0] != "help" && args[0] != "-h" && args[0] != "--help" {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	}
+	fmt.Fprintln(os.Stderr, "Usage: examcenterhub [command] [flags]")
+	fmt.Fprintln(os.Stderr, "\nWithout a command the interactive menu starts. Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", c.name, c.summary)
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		return 0
+	}
+	return 2
+}
+
+// cliActor names the operator in audit entries written from the command line
+func cliActor() string {
+	if user := os.Getenv("USER"); user != "" {
+		return "cli:" + user
+	}
+	return "cli"
+}
+
+// dataDir returns the server state directory, overridable with EXAMHUB_DATA
+func dataDir() string {
+	if dir := os.Getenv("EXAMHUB_DATA"); dir != "" {
+		return dir
+	}
+	return handler.DefaultDataDir
+} 
\ No newline at end of file
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"exam-center-assignment/internal/handler"
+)
+
+// cmdExam manages exam types and their yearly editions: exam list|add|edition|set|close|open|remove
+func cmdExam(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: examcenterhub exam list [CODE]")
+		fmt.Fprintln(os.Stderr, "       examcenterhub exam add|edition|set [flags] CODE YEAR")
+		fmt.Fprintln(os.Stderr, "       examcenterhub exam close|open|remove CODE YEAR")
+		return 2
+	}
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	sub, args := args[0], args[1:]
+	fs := flag.NewFlagSet("exam "+sub, flag.ExitOnError)
+	name := fs.String("name", "", "exam name")
+	description := fs.String("description", "", "one-line description")
+	duration := fs.Duration("duration", 0, "exam length, e.g. 3h or 2h40m")
+	maxCenters := fs.Int("max-centers", 0, "number of nearby cities to suggest")
+	allocation := fs.String("allocation", "", "any, same_state_first, within_state or within_zone")
+	zones := fs.String("zones", "", "zone scheme for within_zone, e.g. SSC")
+	homeCity := fs.String("home-city", "", "include, exclude or exclude_cluster")
+	womenRadius := fs.Float64("women-radius", 0, "km within which women are placed when a center there has room (0: no guarantee)")
+	start := fs.String("start", "", "first exam day, YYYY-MM-DD")
+	end := fs.String("end", "", "last exam day, YYYY-MM-DD (default the first day, for add and edition)")
+	slots := fs.String("slots", "", "comma-separated time slots, e.g. 09:00-12:00,15:00-18:00")
+	deadline := fs.String("deadline", "", "registration deadline, YYYY-MM-DD or text such as 'Rolling basis'")
+	_ = fs.Parse(args)
+
+	// apply copies the flags given on the command line onto an edition
+	apply := func(ex *handler.ExamType) {
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "name":
+				ex.Name = *name
+			case "description":
+				ex.Description = *description
+			case "duration":
+				ex.Duration = *duration
+			case "max-centers":
+				ex.MaxCenters = *maxCenters
+			case "allocation":
+				ex.Allocation = handler.AllocationRule(*allocation)
+			case "zones":
+				ex.Zones = *zones
+			case "home-city":
+				ex.HomeCity = handler.HomeCityPolicy(*homeCity)
+			case "women-radius":
+				ex.WomenRadius = *womenRadius
+			case "start":
+				ex.Schedule.StartDate = *start
+			case "end":
+				ex.Schedule.EndDate = *end
+			case "slots":
+				ex.Schedule.TimeSlots = strings.Split(*slots, ",")
+			case "deadline":
+				ex.Schedule.RegistrationDeadline = *deadline
+			}
+		})
+	}
+
+	if sub == "list" {
+		for _, ex := range h.ExamEditions(fs.Arg(0)) {
+			status := "open"
+			if ex.Closed {
+				status = "closed"
+			}
+			fmt.Printf("%-11s %-6s %s to %s  %-36s %s\n", ex.Label(), status, ex.Schedule.StartDate, ex.Schedule.EndDate,
+				strings.Join(ex.Schedule.TimeSlots, ","), ex.Name)
+		}
+		return 0
+	}
+	code, year, err := handler.ParseExamRef(strings.Join(fs.Args(), " "))
+	if err == nil && year == 0 {
+		err = fmt.Errorf("give the exam code and edition year, e.g. JEE 2026")
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	var ex handler.ExamType
+	switch sub {
+	case "add":
+		if len(h.ExamEditions(code)) > 0 {
+			err = fmt.Errorf("exam type %s already exists; use exam edition to add a year", code)
+			break
+		}
+		ex = handler.ExamType{Code: code, Edition: year}
+		apply(&ex)
+		if *end == "" {
+			ex.Schedule.EndDate = ex.Schedule.StartDate
+		}
+		ex, err = h.SaveExamEdition(cliActor(), ex)
+	case "edition":
+		if ex, err = h.NewExamEdition(code, year); err == nil {
+			apply(&ex)
+			if *end == "" {
+				ex.Schedule.EndDate = ex.Schedule.StartDate
+			}
+			ex, err = h.SaveExamEdition(cliActor(), ex)
+		}
+	case "set":
+		if ex, err = h.ExamEdition(code, year); err == nil {
+			apply(&ex)
+			ex, err = h.SaveExamEdition(cliActor(), ex)
+		}
+	case "close", "open":
+		err = h.SetExamEditionClosed(cliActor(), code, year, sub == "close")
+	case "remove":
+		err = h.RemoveExamEdition(cliActor(), code, year)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown exam command %q\n", sub)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if ex.Code != "" {
+		fmt.Printf("✅ %s saved: %s to %s, slots %s\n", ex.Label(), ex.Schedule.StartDate, ex.Schedule.EndDate, strings.Join(ex.Schedule.TimeSlots, ", "))
+	} else {
+		done := map[string]string{"close": "closed for registration", "open": "open for registration", "remove": "removed"}
+		fmt.Printf("✅ %s %d %s\n", code, year, done[sub])
+	}
+	return 0
+} 
\ No newline at end of file
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"exam-center-assignment/internal/handler"
+)
+
+// cmdExport writes registrations or per-center utilization as CSV, JSON Lines or XLSX
+func cmdExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	what := fs.String("what", "registrations", "registrations or utilization")
+	formatName := fs.String("format", "csv", "csv, jsonl or xlsx")
+	out := fs.String("o", "", "output file (default standard output)")
+	f := filterFlags(fs)
+	_ = fs.Parse(args)
+
+	format, err := handler.ParseExportFormat(*formatName)
+	if err == nil {
+		err = f.Validate()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	regs := h.FilterRegistrations(*f, nil)
+	var t handler.Table
+	switch *what {
+	case "registrations":
+		t = handler.RegistrationsTable(regs)
+	case "utilization":
+		t = h.UtilizationTable(*f, regs)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown export %q (use registrations or utilization)\n", *what)
+		return 2
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	} else if format == handler.FormatXLSX {
+		fmt.Fprintln(os.Stderr, "xlsx needs an output file (-o)")
+		return 2
+	}
+	if err := handler.WriteTable(w, t, format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *out != "" {
+		fmt.Fprintf(os.Stderr, "✅ %d rows written to %s\n", len(t.Rows), *out)
+	}
+	return 0
+}
+
+// filterFlags adds the registration filter flags shared by export and registrations
+func filterFlags(fs *flag.FlagSet) *handler.RegistrationFilter {
+	f := &handler.RegistrationFilter{}
+	fs.StringVar(&f.Exam, "exam", "", "only this exam code")
+	fs.IntVar(&f.Edition, "edition", 0, "only this exam edition (year)")
+	fs.StringVar(&f.City, "city", "", "only this assigned city")
+	fs.StringVar(&f.Center, "center", "", "only this assigned center")
+	fs.StringVar(&f.HomeCity, "home", "", "only candidates from this home city")
+	fs.StringVar(&f.From, "from", "", "first exam date, YYYY-MM-DD")
+	fs.StringVar(&f.To, "to", "", "last exam date, YYYY-MM-DD")
+	fs.StringVar(&f.Name, "name", "", "only candidates whose name contains this text")
+	return f
+} 
\ No newline at end of file
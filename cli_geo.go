@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"exam-center-assignment/internal/handler"
+)
+
+// changeMarks prefixes each line of a reviewed diff
+var changeMarks = map[string]string{
+	handler.GeoAdded:   "+",
+	handler.GeoMoved:   ">",
+	handler.GeoUpdated: "~",
+	handler.GeoRemoved: "-",
+}
+
+// cmdGeo exports cities and centers for GIS tools and imports them back: geo export|import
+func cmdGeo(args []string) int {
+	if len(args) == 0 || (args[0] != "export" && args[0] != "import") {
+		fmt.Fprintln(os.Stderr, "Usage: examcenterhub geo export [-format geojson|kml] [-o file]")
+		fmt.Fprintln(os.Stderr, "       examcenterhub geo import [-format geojson|kml] [-apply] file")
+		return 2
+	}
+	sub, args := args[0], args[1:]
+	fs := flag.NewFlagSet("geo "+sub, flag.ExitOnError)
+	formatName := fs.String("format", "", "geojson or kml (default geojson, or the import file's extension)")
+	out := fs.String("o", "", "output file (export; default standard output)")
+	apply := fs.Bool("apply", false, "apply the changes instead of only listing them (import)")
+	_ = fs.Parse(args)
+	if sub == "import" && fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	name := *formatName
+	if name == "" {
+		name = string(handler.FormatGeoJSON)
+		if sub == "import" {
+			name = fs.Arg(0)
+		} else if *out != "" {
+			name = *out
+		}
+	}
+	format, err := handler.ParseGeoFormat(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if sub == "export" {
+		return geoExport(h, format, *out)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer f.Close()
+	features, err := handler.ReadGeo(f, format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	d := h.DiffGeo(features)
+	if *apply && len(d.Problems) == 0 {
+		d, err = h.ApplyGeo(cliActor(), filepath.Base(fs.Arg(0)), features)
+	}
+	for _, c := range d.Changes {
+		fmt.Printf("%s %-6s %-34s %-7s %s\n", changeMarks[c.Change], c.Kind, c.Name, c.Change, c.Details)
+	}
+	for _, n := range d.Notes {
+		fmt.Fprintln(os.Stderr, "ℹ️ ", n)
+	}
+	for _, p := range d.Problems {
+		fmt.Fprintln(os.Stderr, "❌", p)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("%s, %d unchanged", d.Summary(), d.Unchanged)
+	switch {
+	case len(d.Problems) > 0:
+		fmt.Printf("; %d problems\n", len(d.Problems))
+		return 1
+	case *apply:
+		fmt.Println("; applied")
+	case len(d.Changes) > 0:
+		fmt.Println("; run again with -apply to make these changes")
+	default:
+		fmt.Println()
+	}
+	return 0
+}
+
+func geoExport(h *handler.ExamCenterHandler, format handler.GeoFormat, out string) int {
+	var w io.Writer = os.Stdout
+	if out != "" {
+		file, err := os.Create(out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+	features := h.GeoFeatures()
+	write := handler.WriteGeoJSON
+	if format == handler.FormatKML {
+		write = handler.WriteKML
+	}
+	if err := write(w, features); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if out != "" {
+		fmt.Fprintf(os.Stderr, "✅ %d cities and centers written to %s\n", len(features), out)
+	}
+	return 0
+} 
\ No newline at end of file
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"exam-center-assignment/internal/handler"
+)
+
+// cmdImport registers or queues candidates from a CSV file
+func cmdImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	modeName := fs.String("mode", string(handler.ImportCheck), "check (validate only), allocate (register now) or queue (for the allocate command)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: examcenterhub import [-mode check|allocate|queue] candidates.csv")
+		fmt.Fprintln(os.Stderr, "\nThe header row names the columns: name, roll_number, exam, home_city and optionally phone, email, max_distance.")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	mode, err := handler.ParseImportMode(*modeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer f.Close()
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	res, err := h.ImportCandidatesCSV(cliActor(), f, filepath.Base(fs.Arg(0)), mode, nil)
+	for _, e := range res.Errors {
+		fmt.Fprintln(os.Stderr, "❌", e)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("%d rows, %d valid, %d with errors", res.Rows, res.Valid, len(res.Errors))
+	switch mode {
+	case handler.ImportAllocate:
+		fmt.Printf("; %d registered, %d waitlisted", res.Registered, res.Waitlisted)
+	case handler.ImportQueue:
+		fmt.Printf("; %d queued (%d waiting in total)", res.Queued, len(h.QueuedCandidates()))
+	}
+	fmt.Println()
+	if len(res.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// cmdAllocate runs the batch allocator over queued imports
+func cmdAllocate(args []string) int {
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	res, err := h.RunBatchAllocation(cliActor(), nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("✅ %d registered, %d waitlisted\n", res.Registered, res.Waitlisted)
+	return 0
+} 
\ No newline at end of file
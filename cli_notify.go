@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"exam-center-assignment/internal/handler"
+)
+
+// cmdNotify queues due exam reminders and sends queued messages and retries once, for deployments without
+// a web server; a running server sends them itself
+func cmdNotify(args []string) int {
+	fs := flag.NewFlagSet("notify", flag.ExitOnError)
+	showLog := fs.Int("log", 0, "list this many recent deliveries instead of sending")
+	_ = fs.Parse(args)
+
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *showLog > 0 {
+		for _, d := range h.Deliveries(*showLog) {
+			fmt.Printf("%s  %s  %-24s %-28s %-5s %-30s %s (%d)\n", d.ID, d.CreatedAt.Format("2006-01-02 15:04"), d.Event, d.RegistrationID, d.Channel, d.To, d.Status, d.Attempts)
+			if d.LastError != "" {
+				fmt.Printf("         last error: %s\n", d.LastError)
+			}
+		}
+		return 0
+	}
+	reminders, sent := h.RunNotifications(time.Now())
+	if err := h.Save(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("✅ queued %d reminder(s), sent or retried %d message(s)\n", reminders, sent)
+	return 0
+} 
\ No newline at end of file
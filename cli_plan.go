@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"exam-center-assignment/internal/handler"
+)
+
+// cmdPlan runs the capacity planner for forecast or synthetic demand and shows what planned seats change
+func cmdPlan(args []string) int {
+	fs := flag.NewFlagSet("plan", flag.ExitOnError)
+	demandFile := fs.String("demand", "", "CSV forecast with home_city,candidates columns")
+	synthetic := fs.Int("synthetic", 0, "spread this many candidates over the cities by population instead of -demand")
+	add := fs.String("add", "", "planned seats to try, e.g. \"Patna:500,Gaya:200\"")
+	keep := fs.Bool("keep-bookings", false, "start from the seats still free instead of empty centers")
+	maxDistance := fs.Float64("max-distance", handler.DefaultImportDistance, "the candidates' distance limit in km")
+	seed := fs.Int64("seed", 1, "arrival order of the candidates")
+	top := fs.Int("top", 10, "how many overloaded cities and home cities to list")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 || (*demandFile == "") == (*synthetic <= 0) {
+		fmt.Fprintln(os.Stderr, "Usage: examcenterhub plan (-demand FILE | -synthetic N) [-add CITY:SEATS,...] [-keep-bookings] [-max-distance KM] [-seed N] EXAM")
+		return 2
+	}
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	sc := handler.SimulationScenario{Exam: fs.Arg(0), KeepBookings: *keep, MaxDistance: *maxDistance, Seed: *seed}
+	if *demandFile != "" {
+		f, err := os.Open(*demandFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		sc.Demand, err = h.ParseDemandCSV(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	} else {
+		sc.Demand = h.SyntheticDemand(*synthetic)
+	}
+	if sc.AddSeats, err = h.ParseSeatChanges(*add); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	sim, err := h.Simulate(sc)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	printSimulation(sim, *top)
+	return 0
+}
+
+// printSimulation shows the baseline next to the planned changes, then where seats run short
+func printSimulation(sim handler.Simulation, top int) {
+	b := sim.Baseline
+	runs := []handler.SimulationResult{b}
+	fmt.Printf("%s: %d candidates from %d home cities\n\n%-22s %12s", b.Exam, b.Candidates, len(b.Homes), "", "Current")
+	if sim.Changed != nil {
+		runs = append(runs, *sim.Changed)
+		fmt.Printf(" %12s", "Planned")
+	}
+	fmt.Println()
+	row := func(label string, value func(r handler.SimulationResult) string) {
+		fmt.Printf("%-22s", label)
+		for _, r := range runs {
+			fmt.Printf(" %12s", value(r))
+		}
+		fmt.Println()
+	}
+	row("Seats", func(r handler.SimulationResult) string { return fmt.Sprint(r.Seats) })
+	row("Allocated", func(r handler.SimulationResult) string { return fmt.Sprint(r.Allocated) })
+	row("Unallocated", func(r handler.SimulationResult) string { return fmt.Sprint(r.Unallocated) })
+	row("Mean distance km", func(r handler.SimulationResult) string { return fmt.Sprintf("%.1f", r.Distance.Mean) })
+	row("Median distance km", func(r handler.SimulationResult) string { return fmt.Sprintf("%.1f", r.Distance.P50) })
+	row("p95 distance km", func(r handler.SimulationResult) string { return fmt.Sprintf("%.1f", r.Distance.P95) })
+	row("Max distance km", func(r handler.SimulationResult) string { return fmt.Sprintf("%.1f", r.Distance.Max) })
+	row("Overloaded cities", func(r handler.SimulationResult) string { return fmt.Sprint(len(r.Overloaded())) })
+
+	last := runs[len(runs)-1]
+	overloaded := last.Overloaded()
+	fmt.Printf("\nOverloaded cities%s: more candidates wanted them than they could seat\n", planned(sim))
+	fmt.Printf("%-20s %8s %8s %8s %10s\n", "City", "Seats", "Booked", "Wanted", "Seats short")
+	for _, c := range overloaded[:min(top, len(overloaded))] {
+		fmt.Printf("%-20s %8d %8d %8d %10d\n", clip(c.City, 20), c.Seats, c.Booked, c.Wanted, c.Overflow)
+	}
+	if len(overloaded) == 0 {
+		fmt.Println("None.")
+	}
+	if last.Unallocated == 0 {
+		return
+	}
+	fmt.Printf("\nHome cities with unallocated candidates%s\n", planned(sim))
+	fmt.Printf("%-20s %8s %10s %12s %8s\n", "Home city", "Demand", "Allocated", "Unallocated", "Mean km")
+	for _, home := range last.Homes[:min(top, len(last.Homes))] {
+		if home.Unallocated == 0 {
+			break
+		}
+		fmt.Printf("%-20s %8d %10d %12d %8.1f\n", clip(home.City, 20), home.Demand, home.Allocated, home.Unallocated, home.Distance.Mean)
+	}
+}
+
+// planned qualifies the detail tables, which show the run with the planned seats when there is one
+func planned(sim handler.Simulation) string {
+	if sim.Changed != nil {
+		return " (with the planned seats)"
+	}
+	return ""
+} 
\ No newline at end of file
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"exam-center-assignment/internal/handler"
+)
+
+// cmdPolicy manages the allocation rule files of exam types: policy show|set|reset|explain
+func cmdPolicy(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: examcenterhub policy show EXAM")
+		fmt.Fprintln(os.Stderr, "       examcenterhub policy set FILE")
+		fmt.Fprintln(os.Stderr, "       examcenterhub policy reset EXAM")
+		fmt.Fprintln(os.Stderr, "       examcenterhub policy explain [-file FILE] [-category pwd,woman] [-scribe] [-max-distance KM] EXAM HOME_CITY")
+		return 2
+	}
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	sub, args := args[0], args[1:]
+	switch {
+	case sub == "show" && len(args) == 1:
+		p, custom := h.AllocationPolicyFor(args[0])
+		if len(h.ExamEditions(p.Exam)) == 0 {
+			err = fmt.Errorf("exam type '%s' not found", args[0])
+			break
+		}
+		if !custom {
+			fmt.Fprintf(os.Stderr, "%s uses the default policy\n", p.Exam)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(p)
+	case sub == "set" && len(args) == 1:
+		var p handler.AllocationPolicy
+		if p, err = readPolicyFile(args[0]); err == nil {
+			if err = h.SetAllocationPolicy(cliActor(), p); err == nil {
+				fmt.Printf("✅ policy for %s saved\n", strings.ToUpper(p.Exam))
+			}
+		}
+	case sub == "reset" && len(args) == 1:
+		if err = h.ResetAllocationPolicy(cliActor(), args[0]); err == nil {
+			fmt.Printf("✅ %s uses the default policy again\n", strings.ToUpper(args[0]))
+		}
+	case sub == "explain":
+		return policyExplain(h, args)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown or incomplete policy command %q\n", sub)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func readPolicyFile(path string) (handler.AllocationPolicy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return handler.AllocationPolicy{}, err
+	}
+	defer f.Close()
+	return handler.ParseAllocationPolicy(f)
+}
+
+// policyExplain dry-runs the allocation of one candidate and shows which rule excluded or ranked each center.
+// With -file the rule file is tried without saving it.
+func policyExplain(h *handler.ExamCenterHandler, args []string) int {
+	fs := flag.NewFlagSet("policy explain", flag.ExitOnError)
+	file := fs.String("file", "", "rule file to try instead of the exam's saved policy")
+	categories := fs.String("category", "", "priority categories, e.g. pwd,woman")
+	scribe := fs.Bool("scribe", false, "the candidate writes with a scribe")
+	maxDistance := fs.Float64("max-distance", handler.DefaultImportDistance, "the candidate's distance limit in km")
+	_ = fs.Parse(args)
+	if fs.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, "policy explain needs an exam (e.g. JEE or JEE-2026) and a home city")
+		return 2
+	}
+	ex, err := h.GetExamTypeDetails(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	homeCity, err := h.ValidateCity(strings.Join(fs.Args()[1:], " "))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	prefs, err := handler.StudentPreference{MaxDistance: *maxDistance, PreferredTransport: "any"}.WithCategories(*categories, *scribe)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	policy, custom := h.AllocationPolicyFor(ex.Code)
+	source := "default policy"
+	if custom {
+		source = ex.Code + " policy"
+	}
+	if *file != "" {
+		if policy, err = readPolicyFile(*file); err == nil {
+			err = h.CheckAllocationPolicy(&policy)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if policy.Exam != ex.Code {
+			fmt.Fprintf(os.Stderr, "%s is a policy for %s, not %s\n", *file, policy.Exam, ex.Code)
+			return 1
+		}
+		source = *file + " (not saved)"
+	}
+	res, err := h.ExplainAllocation(policy, homeCity, ex, prefs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Printf("%s candidate from %s (%s, up to %.0f km), %s\n", ex.Label(), homeCity, prefs.CategoryList(), prefs.MaxDistance, source)
+	if len(res.Nearest) == 0 {
+		fmt.Println("No center can be offered: the candidate would join the waitlist.")
+	}
+	header := false
+	for _, v := range res.Verdicts {
+		if v.Rule != "" && !header {
+			fmt.Println("\nExcluded:")
+			header = true
+		} else if v.Rank == 1 {
+			fmt.Println("\nOffered:")
+		}
+		place := "-"
+		if v.Rank > 0 {
+			place = fmt.Sprint(v.Rank)
+		}
+		fmt.Printf("%3s  %-36s %-13s %6.0f km  %6s  fill %3.0f%%  ", place, v.Center.Name, v.City, v.Distance, travelText(v.Travel.Hours()), v.FillPct)
+		if v.Rule != "" {
+			fmt.Printf("%s: %s\n", v.Rule, v.Reason)
+		} else {
+			fmt.Printf("score %.1f  %s\n", v.Score, v.Behind)
+		}
+	}
+	return 0
+}
+
+// travelText shows an estimated travel time in hours and minutes
+func travelText(hours float64) string {
+	mins := int(hours*60 + 0.5)
+	return fmt.Sprintf("%dh%02d", mins/60, mins%60)
+} 
\ No newline at end of file
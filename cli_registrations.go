@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"exam-center-assignment/internal/handler"
+)
+
+// cmdRegistrations searches, sorts and pages through registrations
+func cmdRegistrations(args []string) int {
+	fs := flag.NewFlagSet("registrations", flag.ExitOnError)
+	f := filterFlags(fs)
+	sortBy := fs.String("sort", handler.SortRegistered, "sort by "+strings.Join(handler.SortKeys, ", "))
+	desc := fs.Bool("desc", false, "reverse the sort order")
+	page := fs.Int("page", 1, "page number")
+	size := fs.Int("n", handler.DefaultPageSize, "registrations per page")
+	_ = fs.Parse(args)
+
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	p, err := h.QueryRegistrations(handler.RegistrationQuery{Filter: *f, Sort: *sortBy, Desc: *desc, Page: *page, PageSize: *size}, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if p.Total == 0 {
+		fmt.Println("No registrations found.")
+		return 0
+	}
+	fmt.Printf("%-28s %-22s %-6s %-14s %-36s %-22s %9s\n", "ID", "Candidate", "Exam", "Home city", "Center", "Date & slot", "Distance")
+	for _, r := range p.Items {
+		fmt.Printf("%-28s %-22s %-6s %-14s %-36s %-22s %6.1f km\n", r.ID, clip(r.StudentName, 22), r.ExamType.Code, r.StudentCity,
+			clip(r.AssignedCenter+", "+r.AssignedCity, 36), r.ExamDate+" "+r.TimeSlot, r.Distance)
+	}
+	fmt.Printf("\nShowing %d-%d of %d (page %d of %d)\n", p.First(), p.Last(), p.Total, p.Page, p.Pages)
+	return 0
+}
+
+// clip shortens s to n characters for table output
+func clip(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+// cmdExplain shows why a registration was given its center
+func cmdExplain(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: examcenterhub explain REGISTRATION_ID")
+		return 2
+	}
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	reg, err := h.GetRegistration(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("%s: %s (%s) for %s, home %s\n", reg.ID, reg.StudentName, reg.Preferences.CategoryList(), reg.ExamType.Label(), reg.StudentCity)
+	fmt.Printf("Assigned %s, %s\n", reg.AssignedCenter, reg.AssignedCity)
+	if reg.Explanation == nil {
+		fmt.Println("No explanation was recorded: the registration was made before assignments were explained.")
+		return 0
+	}
+	handler.DisplayExplanation(*reg.Explanation)
+	return 0
+} 
\ No newline at end of file
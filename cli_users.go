@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"exam-center-assignment/internal/handler"
+)
+
+// cmdUser manages web UI accounts: user add|list|passwd|disable|enable
+func cmdUser(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: examcenterhub user add|list|passwd|disable|enable [flags] [name]")
+		return 2
+	}
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	sub, args := args[0], args[1:]
+	fs := flag.NewFlagSet("user "+sub, flag.ExitOnError)
+	role := fs.String("role", string(handler.RoleCandidate), "national_admin, exam_admin, superintendent or candidate")
+	exams := fs.String("exams", "", "comma-separated exam codes an exam admin manages")
+	center := fs.String("center", "", "exam center a superintendent runs")
+	roll := fs.String("roll", "", "candidate roll number (defaults to the user name)")
+	contact := fs.String("contact", "", "mobile number or email on the candidate's registrations")
+	password := fs.String("password", "", "password (prompted for when omitted)")
+	_ = fs.Parse(args)
+
+	if sub == "list" {
+		for _, u := range h.ListUsers() {
+			status := ""
+			if u.Disabled {
+				status = " (disabled)"
+			}
+			fmt.Printf("%-20s %-15s %s%s\n", u.Username, u.Role, u.Scope(), status)
+		}
+		return 0
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "user %s needs exactly one user name\n", sub)
+		return 2
+	}
+	name := fs.Arg(0)
+	switch sub {
+	case "add":
+		var r handler.Role
+		if r, err = handler.ParseRole(*role); err == nil {
+			u := handler.User{Username: name, Role: r, ExamCodes: strings.Split(*exams, ","), Center: *center, RollNumber: *roll, Contact: *contact}
+			err = h.AddUser(cliActor(), u, readPassword(*password))
+		}
+	case "passwd":
+		err = h.SetUserPassword(cliActor(), name, readPassword(*password))
+	case "disable":
+		err = h.SetUserDisabled(cliActor(), name, true)
+	case "enable":
+		err = h.SetUserDisabled(cliActor(), name, false)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown user command %q\n", sub)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("✅ user %s saved\n", name)
+	return 0
+}
+
+// readPassword returns the flag value, or reads a line from stdin when it is empty
+func readPassword(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimRight(line, "\r\n")
+} 
\ No newline at end of file
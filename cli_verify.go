@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"exam-center-assignment/internal/handler"
+)
+
+// cmdVerify checks admit card payloads offline using the public key and this machine's exam catalogue.
+// Payloads come from the arguments, or one per line on stdin (as typed by a handheld scanner).
+func cmdVerify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	pubPath := fs.String("pubkey", filepath.Join(dataDir(), handler.PublicKeyFile), "public key (PEM) to verify against")
+	center := fs.String("center", "", "this gate's exam center; cards for other centers are flagged")
+	date := fs.String("date", time.Now().Format("2006-01-02"), "exam day (YYYY-MM-DD); cards for other days are flagged")
+	_ = fs.Parse(args)
+
+	pub, err := handler.LoadPublicKey(*pubPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	code := 0
+	check := func(payload string) {
+		if strings.TrimSpace(payload) == "" {
+			return
+		}
+		if !printGateCheck(h, payload, pub, *center, *date) {
+			code = 1
+		}
+	}
+	if fs.NArg() > 0 {
+		check(strings.Join(fs.Args(), " "))
+		return code
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		check(scanner.Text())
+	}
+	return code
+}
+
+// printGateCheck prints the verification result and reports whether the candidate may enter
+func printGateCheck(h *handler.ExamCenterHandler, payload string, pub ed25519.PublicKey, center, date string) bool {
+	fmt.Println(strings.Repeat("-", 60))
+	res, err := h.CheckAdmitCardAtGate(payload, pub, center, date)
+	if errors.Is(err, handler.ErrInvalidAdmitCard) {
+		fmt.Println("❌ FORGED OR DAMAGED CARD: signature does not verify")
+		return false
+	}
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+	c := res.Card
+	fmt.Printf("Candidate: %s (Roll %s)\n", c.Name, c.RollNumber)
+	fmt.Printf("Exam:      %s - %s\n", c.Exam, res.ExamName)
+	fmt.Printf("Center:    %s, %s\n", c.Center, c.City)
+	fmt.Printf("Slot:      %s %s (report by %s)\n", c.Date, c.Slot, c.Reporting)
+	fmt.Printf("Reg. ID:   %s\n", c.RegistrationID)
+	if res.WrongCenter {
+		fmt.Printf("⚠️  WRONG CENTER: card is for %s, this gate is %s\n", c.Center, center)
+	}
+	if res.WrongDay {
+		fmt.Printf("⚠️  WRONG DAY: card is for %s, today is %s\n", c.Date, date)
+	}
+	if res.WrongEdition {
+		card := "an edition it does not name"
+		if c.Edition != 0 {
+			card = fmt.Sprintf("%s %d", c.Exam, c.Edition)
+		}
+		fmt.Printf("⚠️  WRONG EDITION: card is for %s, today is %s %d\n", card, c.Exam, res.Edition)
+	}
+	if res.Admit() {
+		fmt.Println("✅ VALID - admit candidate")
+	}
+	return res.Admit()
+}
+
+// cmdPubkey prints the public key matching the server's signing key
+func cmdPubkey(args []string) int {
+	fs := flag.NewFlagSet("pubkey", flag.ExitOnError)
+	dir := fs.String("data", dataDir(), "data directory holding the signing key")
+	_ = fs.Parse(args)
+	key, err := handler.LoadOrCreateSigningKey(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	pem, err := handler.EncodePublicKey(key.Public().(ed25519.PublicKey))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Print(string(pem))
+	return 0
+} 
\ No newline at end of file
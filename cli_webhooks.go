@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"exam-center-assignment/internal/handler"
+)
+
+// cmdWebhook manages exam body webhooks: webhook add|list|remove|disable|enable|rotate|log|replay|run
+func cmdWebhook(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: examcenterhub webhook add|list|remove|disable|enable|rotate|log|replay|run [flags] [id]")
+		return 2
+	}
+	h, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	sub, args := args[0], args[1:]
+	fs := flag.NewFlagSet("webhook "+sub, flag.ExitOnError)
+	exam := fs.String("exam", "", "exam code the webhook receives events for (add)")
+	target := fs.String("url", "", "receiver URL (add)")
+	events := fs.String("events", "", "comma-separated events, default all: "+eventList())
+	status := fs.String("status", "", "only deliveries with this status: pending, delivered or dead (log)")
+	limit := fs.Int("n", 20, "number of deliveries to list (log)")
+	_ = fs.Parse(args)
+
+	id := fs.Arg(0)
+	switch sub {
+	case "add":
+		var kinds []handler.EventKind
+		if kinds, err = handler.ParseWebhookEvents(*events); err == nil {
+			var w handler.WebhookSubscription
+			if w, err = h.AddWebhook(cliActor(), *exam, *target, kinds); err == nil {
+				fmt.Printf("✅ webhook %s added for %s\nSigning secret (shown once): %s\n", w.ID, w.ExamCode, w.Secret)
+			}
+		}
+	case "list":
+		for _, w := range h.Webhooks() {
+			state := "active"
+			if w.Disabled {
+				state = "paused"
+			}
+			fmt.Printf("%-12s %-6s %-7s %s\n", w.ID, w.ExamCode, state, w.URL)
+		}
+	case "remove":
+		err = h.RemoveWebhook(cliActor(), id)
+	case "disable", "enable":
+		err = h.SetWebhookDisabled(cliActor(), id, sub == "disable")
+	case "rotate":
+		var secret string
+		if secret, err = h.RotateWebhookSecret(cliActor(), id); err == nil {
+			fmt.Printf("New signing secret for %s: %s\n", id, secret)
+		}
+	case "log":
+		for _, d := range h.WebhookDeliveries(*status, *limit) {
+			fmt.Printf("%s  %s  %-12s %-24s %-28s %-9s (%d)\n", d.ID, d.CreatedAt.Format("2006-01-02 15:04"), d.SubscriptionID, d.Event, d.RegistrationID, d.Status, d.Attempts)
+			if d.LastError != "" {
+				fmt.Printf("         last error: %s\n", d.LastError)
+			}
+		}
+	case "replay":
+		// A delivery ID replays that delivery; a webhook ID or nothing replays its dead letters
+		queued := 1
+		if strings.HasPrefix(id, "W") {
+			_, err = h.ReplayWebhook(cliActor(), id)
+		} else {
+			queued, err = h.ReplayDeadWebhooks(cliActor(), id)
+		}
+		if err == nil {
+			fmt.Printf("queued %d delivery(ies) again and posted %d; see 'webhook log' for the outcome\n", queued, h.RunWebhooks(time.Now()))
+			err = h.Save()
+		}
+	case "run":
+		fmt.Printf("retried %d webhook deliveries\n", h.RunWebhooks(time.Now()))
+		err = h.Save()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown webhook command %q\n", sub)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func eventList() string {
+	var names []string
+	for _, k := range handler.WebhookEvents {
+		names = append(names, string(k))
+	}
+	return strings.Join(names, ", ")
+} 
\ No newline at end of file
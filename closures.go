@@ -0,0 +1,282 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A center is closed for a date, or for one slot of it, when its venue becomes unavailable (flood,
+// venue cancellation). Closing moves every registration the center has for that sitting to the
+// best center with room under the exam's policy, nearest first by default, and tells each candidate
+// their new center. Until the center is reopened the engine offers it to no one sitting an exam then.
+// A closure can be previewed: the moves are worked out the same way and then undone.
+
+// CenterClosure closes a center for a date, or for one slot of it
+type CenterClosure struct {
+	Center   string
+	City     string
+	Date     string // YYYY-MM-DD
+	Slot     string // e.g. "09:00-12:00"; empty closes the whole day
+	Reason   string
+	ClosedBy string
+	ClosedAt time.Time
+}
+
+// Covers reports whether the closure keeps candidates sitting an exam on date in slot away from center
+func (c CenterClosure) Covers(center, date, slot string) bool {
+	if c.Center != center || c.Date != date {
+		return false
+	}
+	if c.Slot == "" || c.Slot == slot {
+		return true
+	}
+	// Exams have slots of their own, so a closed slot covers every slot it overlaps
+	start, end, ok1 := slotTimes(c.Date, c.Slot)
+	from, to, ok2 := slotTimes(date, slot)
+	return ok1 && ok2 && from.Before(end) && start.Before(to)
+}
+
+// Sitting names what is closed, e.g. "2025-05-04 09:00-12:00" or "2025-05-04, all day"
+func (c CenterClosure) Sitting() string {
+	if c.Slot == "" {
+		return c.Date + ", all day"
+	}
+	return c.Date + " " + c.Slot
+}
+
+// Describe says why the engine passes the center over
+func (c CenterClosure) Describe() string {
+	s := "closed on " + c.Sitting()
+	if c.Reason != "" {
+		s += " (" + c.Reason + ")"
+	}
+	return s
+}
+
+// ClosureMove is what closing a center does to one of its registrations
+type ClosureMove struct {
+	Registration ExamRegistration // as it was before the closure
+	Center       string           // the new center; empty when none had room
+	City         string
+	Distance     float64
+	ExamDate     string // the new date and slot; they change only when the new city clashes with the candidate's other exams, and never to a closed one
+	TimeSlot     string
+	Problem      string // why the registration could not be moved
+}
+
+// ClosureImpact lists the moves closing a center makes, or would make
+type ClosureImpact struct {
+	Closure CenterClosure
+	Moves   []ClosureMove
+}
+
+// Moved counts the registrations given a new center
+func (i ClosureImpact) Moved() int {
+	n := 0
+	for _, m := range i.Moves {
+		if m.Center != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Stranded counts the registrations left at the closed center because no eligible center had room
+func (i ClosureImpact) Stranded() int { return len(i.Moves) - i.Moved() }
+
+// Summary describes the impact in a few words, e.g. "12 registrations: 10 moved, 2 with no center to move to"
+func (i ClosureImpact) Summary() string {
+	return fmt.Sprintf("%d registrations: %d moved, %d with no center to move to", len(i.Moves), i.Moved(), i.Stranded())
+}
+
+// Fingerprint identifies the moves, so a preview can be checked against the data when the closure is applied
+func (i ClosureImpact) Fingerprint() string {
+	sum := sha256.New()
+	fmt.Fprintf(sum, "%s\x00%s\n", i.Closure.Center, i.Closure.Sitting())
+	for _, m := range i.Moves {
+		fmt.Fprintf(sum, "%s\x00%s\x00%s\x00%s\x00%s\n", m.Registration.ID, m.Center, m.ExamDate, m.TimeSlot, m.Problem)
+	}
+	return hex.EncodeToString(sum.Sum(nil))[:16]
+}
+
+// Closures returns every closure by date and center
+func (h *ExamCenterHandler) Closures() []CenterClosure {
+	list := append([]CenterClosure(nil), h.closures...)
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Date != list[j].Date {
+			return list[i].Date < list[j].Date
+		}
+		return list[i].Center < list[j].Center
+	})
+	return list
+}
+
+// centerClosures returns the closures of one center by date
+func (h *ExamCenterHandler) centerClosures(center string) []CenterClosure {
+	var list []CenterClosure
+	for _, c := range h.Closures() {
+		if c.Center == center {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// closureFor returns the closure, if any, that keeps candidates sitting on date in slot away from center
+func (h *ExamCenterHandler) closureFor(center, date, slot string) (CenterClosure, bool) {
+	for _, c := range h.closures {
+		if c.Covers(center, date, slot) {
+			return c, true
+		}
+	}
+	return CenterClosure{}, false
+}
+
+// closureIndex returns the position of the closure of the same center and sitting in h.closures, or -1
+func (h *ExamCenterHandler) closureIndex(cl CenterClosure) int {
+	for i, c := range h.closures {
+		if c.Center == cl.Center && c.Date == cl.Date && c.Slot == cl.Slot {
+			return i
+		}
+	}
+	return -1
+}
+
+// newClosure checks the center, date and slot of a closure
+func (h *ExamCenterHandler) newClosure(center, date, slot, reason string) (CenterClosure, error) {
+	c, ok := h.findCenter(center)
+	if !ok {
+		return CenterClosure{}, fmt.Errorf("exam center '%s' not found", center)
+	}
+	date = strings.TrimSpace(date)
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return CenterClosure{}, fmt.Errorf("date '%s' is not a YYYY-MM-DD date", date)
+	}
+	slot = strings.ReplaceAll(strings.TrimSpace(slot), " ", "")
+	if _, _, ok := slotTimes(date, slot); slot != "" && !ok {
+		return CenterClosure{}, fmt.Errorf("time slot '%s' is not like 09:00-12:00", slot)
+	}
+	return CenterClosure{Center: c.Name, City: c.City, Date: date, Slot: slot, Reason: strings.TrimSpace(reason)}, nil
+}
+
+// PreviewCenterClosure works out the moves closing a center would make, without changing anything
+func (h *ExamCenterHandler) PreviewCenterClosure(center, date, slot, reason string) (ClosureImpact, error) {
+	cl, err := h.newClosure(center, date, slot, reason)
+	if err != nil {
+		return ClosureImpact{}, err
+	}
+	capacity, registrations, closures := h.centerCapacity, h.registrations, h.closures
+	h.centerCapacity = make(map[string]CenterCapacity, len(capacity))
+	for name, capInfo := range capacity {
+		h.centerCapacity[name] = capInfo
+	}
+	h.registrations = append([]ExamRegistration(nil), registrations...)
+	h.closures = append(append([]CenterClosure(nil), closures...), cl)
+	impact := h.reassignClosed(cl)
+	h.centerCapacity, h.registrations, h.closures = capacity, registrations, closures
+	return impact, nil
+}
+
+// CloseCenter closes a center for a date, or one slot of it, moves its registrations for that sitting
+// and notifies the candidates moved. Closing a center again retries the registrations that could not
+// be moved the first time, e.g. after seats were added elsewhere.
+func (h *ExamCenterHandler) CloseCenter(actor, center, date, slot, reason string) (ClosureImpact, error) {
+	cl, err := h.newClosure(center, date, slot, reason)
+	if err != nil {
+		return ClosureImpact{}, err
+	}
+	if i := h.closureIndex(cl); i >= 0 {
+		cl = h.closures[i]
+	} else {
+		cl.ClosedBy, cl.ClosedAt = actor, time.Now()
+		h.closures = append(h.closures, cl)
+	}
+	impact := h.reassignClosed(cl)
+	why := fmt.Sprintf("%s is %s.", cl.Center, cl.Describe())
+	for _, m := range impact.Moves {
+		if m.Center == "" {
+			continue
+		}
+		reg, _ := h.GetRegistration(m.Registration.ID)
+		h.emit(Event{Kind: EventRegistrationReassigned, Registration: reg, PreviousCenter: m.Registration.AssignedCenter, PreviousCity: m.Registration.AssignedCity, Reason: why})
+	}
+	h.PromoteWaitlist()
+	return impact, h.commit(actor, "center.close", cl.Center, cl.Sitting()+": "+impact.Summary())
+}
+
+// ReopenCenter lifts a closure. Registrations moved away stay at their new centers.
+func (h *ExamCenterHandler) ReopenCenter(actor, center, date, slot string) error {
+	cl, err := h.newClosure(center, date, slot, "")
+	if err != nil {
+		return err
+	}
+	i := h.closureIndex(cl)
+	if i < 0 {
+		return fmt.Errorf("%s is not closed on %s", cl.Center, cl.Sitting())
+	}
+	h.closures = append(h.closures[:i], h.closures[i+1:]...)
+	h.PromoteWaitlist()
+	return h.commit(actor, "center.reopen", cl.Center, cl.Sitting())
+}
+
+// reassignClosed moves the registrations a closure covers, priority candidates first
+func (h *ExamCenterHandler) reassignClosed(cl CenterClosure) ClosureImpact {
+	impact := ClosureImpact{Closure: cl}
+	var affected []ExamRegistration
+	for _, reg := range h.registrations {
+		if cl.Covers(reg.AssignedCenter, reg.ExamDate, reg.TimeSlot) {
+			affected = append(affected, reg)
+		}
+	}
+	sort.SliceStable(affected, func(i, j int) bool {
+		return affected[i].Preferences.Priority() && !affected[j].Preferences.Priority()
+	})
+	for _, reg := range affected {
+		impact.Moves = append(impact.Moves, h.moveRegistration(reg))
+	}
+	return impact
+}
+
+// moveRegistration gives a registration the first center the exam's policy offers for its sitting
+func (h *ExamCenterHandler) moveRegistration(reg ExamRegistration) ClosureMove {
+	move := ClosureMove{Registration: reg}
+	prefs := reg.Preferences
+	if prefs.MaxDistance <= 0 {
+		prefs.MaxDistance = 1000
+	}
+	policy, _ := h.AllocationPolicyFor(reg.ExamType.Code)
+	res, err := h.allocateAt(policy, reg.StudentCity, reg.ExamType, prefs, reg.ExamDate, reg.TimeSlot)
+	if err != nil {
+		move.Problem = err.Error()
+		return move
+	}
+	if len(res.Nearest) == 0 {
+		move.Problem = fmt.Sprintf("no eligible center with free seats within %.0f km of %s", prefs.MaxDistance, reg.StudentCity)
+		return move
+	}
+	best := res.Nearest[0]
+	from := reg.AssignedCenter
+	reg.Explanation = h.explainAssignment(reg.StudentCity, reg.ExamType, prefs, best.Centers[0].Name, reg.ExamDate, reg.TimeSlot)
+	reg.AssignedCenter, reg.AssignedCity, reg.Distance = best.Centers[0].Name, best.City.Name, best.Distance
+	reg.Room, reg.SeatNumber = "", ""
+	// The new city may be too far from the candidate's other exams for the old slot; the slot chosen
+	// instead must not be one the new center is closed for
+	date, slot, ok := h.chooseSlot(reg)
+	if !ok {
+		move.Problem = fmt.Sprintf("no open slot: %s is closed for every sitting of %s", reg.AssignedCenter, reg.ExamType.Label())
+		return move
+	}
+	reg.ExamDate, reg.TimeSlot = date, slot
+	h.releaseSeat(from)
+	h.bookSeat(reg.AssignedCenter)
+	if i, err := h.registrationIndex(reg.ID); err == nil {
+		h.registrations[i] = reg
+	}
+	move.Center, move.City, move.Distance = reg.AssignedCenter, reg.AssignedCity, reg.Distance
+	move.ExamDate, move.TimeSlot = reg.ExamDate, reg.TimeSlot
+	return move
+} 
\ No newline at end of file
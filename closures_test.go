@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+// closureHandler has one UPSC registration at Pune University Center on the first morning
+func closureHandler(t *testing.T) (*ExamCenterHandler, ExamRegistration) {
+	t.Helper()
+	h := NewExamCenterHandler()
+	exam := includeHomeCity(t, h, "UPSC")
+	reg := ExamRegistration{
+		ID: "UPSC-U1-1", StudentName: "Asha Verma", RollNumber: "U1", CandidateID: "C000001", StudentCity: "Pune",
+		ExamType: exam, AssignedCenter: "Pune University Center", AssignedCity: "Pune",
+		ExamDate: "2024-06-02", TimeSlot: "09:30-12:30", Preferences: StudentPreference{MaxDistance: 500},
+	}
+	h.registrations = []ExamRegistration{reg}
+	h.bookSeat(reg.AssignedCenter)
+	return h, reg
+}
+
+func TestPreviewCenterClosureMatchesClose(t *testing.T) {
+	tests := []struct {
+		name   string
+		center string
+		date   string
+		slot   string
+		moves  int
+	}{
+		{"whole day", "Pune University Center", "2024-06-02", "", 1},
+		{"the slot", "Pune University Center", "2024-06-02", "09:30-12:30", 1},
+		{"overlapping slot", "Pune University Center", "2024-06-02", "12:00-13:00", 1},
+		{"other slot", "Pune University Center", "2024-06-02", "14:30-17:30", 0},
+		{"other day", "Pune University Center", "2024-06-03", "", 0},
+		{"other center", "Shivaji Nagar Exam Hall", "2024-06-02", "", 0},
+	}
+	for _, tt := range tests {
+		h, reg := closureHandler(t)
+		capacity := make(map[string]CenterCapacity)
+		for name, c := range h.centerCapacity {
+			capacity[name] = c
+		}
+		preview, err := h.PreviewCenterClosure(tt.center, tt.date, tt.slot, "flooded")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(preview.Moves) != tt.moves {
+			t.Fatalf("%s: preview has %d moves, want %d", tt.name, len(preview.Moves), tt.moves)
+		}
+		if !reflect.DeepEqual(h.registrations, []ExamRegistration{reg}) || !reflect.DeepEqual(h.centerCapacity, capacity) || len(h.closures) != 0 {
+			t.Fatalf("%s: preview changed the data", tt.name)
+		}
+		for _, m := range preview.Moves {
+			if m.Center == "" || m.Center == tt.center {
+				t.Errorf("%s: registration not moved away: %+v", tt.name, m)
+			}
+		}
+		applied, err := h.CloseCenter("admin", tt.center, tt.date, tt.slot, "flooded")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if applied.Fingerprint() != preview.Fingerprint() {
+			t.Errorf("%s: closing made other moves than the preview showed", tt.name)
+		}
+		if got, _ := h.GetRegistration(reg.ID); tt.moves > 0 && got.AssignedCenter != preview.Moves[0].Center {
+			t.Errorf("%s: registration is at %s, preview said %s", tt.name, got.AssignedCenter, preview.Moves[0].Center)
+		}
+	}
+}
+
+func TestCloseCenterNeverMovesOntoAClosure(t *testing.T) {
+	h, reg := closureHandler(t)
+	// The candidate's other exam takes up the whole first day, so the move must also change the slot
+	other := ExamRegistration{ID: "GATE-G1-1", CandidateID: reg.CandidateID, AssignedCenter: "Kothrud Sports Complex", AssignedCity: "Pune",
+		ExamDate: "2024-06-02", TimeSlot: "09:00-18:00"}
+	h.registrations = append(h.registrations, other)
+	for _, center := range []string{"Shivaji Nagar Exam Hall", "Kothrud Sports Complex"} {
+		h.closures = append(h.closures, CenterClosure{Center: center, Date: "2024-06-03", Slot: "09:30-12:30"})
+	}
+	impact, err := h.CloseCenter("admin", "Pune University Center", "2024-06-02", "", "flooded")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(impact.Moves) != 1 {
+		t.Fatalf("%d moves, want 1", len(impact.Moves))
+	}
+	m := impact.Moves[0]
+	if m.Center == "" {
+		t.Fatalf("not moved: %s", m.Problem)
+	}
+	if _, closed := h.closureFor(m.Center, m.ExamDate, m.TimeSlot); closed {
+		t.Errorf("moved onto a closure: %s on %s %s", m.Center, m.ExamDate, m.TimeSlot)
+	}
+	if m.City != "Pune" || m.ExamDate != "2024-06-03" || m.TimeSlot != "14:30-17:30" {
+		t.Errorf("moved to %s (%s) on %s %s, want another Pune center on the first open slot that does not clash, 2024-06-03 14:30-17:30",
+			m.Center, m.City, m.ExamDate, m.TimeSlot)
+	}
+} 
\ No newline at end of file
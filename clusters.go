@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// HomeCityPolicy decides whether a candidate may sit the exam in their own city
+type HomeCityPolicy string
+
+const (
+	HomeCityExclude        HomeCityPolicy = ""                // never the home city
+	HomeCityInclude        HomeCityPolicy = "include"         // the home city is the nearest choice
+	HomeCityExcludeCluster HomeCityPolicy = "exclude_cluster" // neither the home city nor its metro cluster
+)
+
+// HomeCityPolicies lists the policies in the order they are offered
+var HomeCityPolicies = []HomeCityPolicy{HomeCityExclude, HomeCityInclude, HomeCityExcludeCluster}
+
+// ParseHomeCityPolicy reads a policy name; "exclude" and "" both mean HomeCityExclude
+func ParseHomeCityPolicy(s string) (HomeCityPolicy, error) {
+	p := HomeCityPolicy(strings.ToLower(strings.TrimSpace(s)))
+	if p == "exclude" {
+		return HomeCityExclude, nil
+	}
+	for _, known := range HomeCityPolicies {
+		if p == known {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown home city policy '%s' (use include, exclude or exclude_cluster)", s)
+}
+
+// String names the policy for display
+func (p HomeCityPolicy) String() string {
+	if p == HomeCityExclude {
+		return "exclude"
+	}
+	return string(p)
+}
+
+// MetroCluster is a group of neighbouring cities that count as one place, e.g. Delhi NCR
+type MetroCluster struct {
+	Name   string
+	Cities []string
+}
+
+// defaultClusters are seeded into new data directories and state files from before clusters existed
+var defaultClusters = []MetroCluster{
+	{Name: "Delhi NCR", Cities: []string{"Delhi", "Faridabad", "Ghaziabad", "Gurgaon"}},
+	{Name: "Kolkata", Cities: []string{"Howrah", "Kolkata"}},
+	{Name: "Mumbai Metropolitan Region", Cities: []string{"Kalyan", "Mumbai", "Navi Mumbai", "Vasai"}},
+}
+
+func seedClusters() []MetroCluster {
+	clusters := make([]MetroCluster, len(defaultClusters))
+	for i, c := range defaultClusters {
+		clusters[i] = MetroCluster{Name: c.Name, Cities: append([]string(nil), c.Cities...)}
+	}
+	return clusters
+}
+
+// MetroClusters returns the clusters sorted by name
+func (h *ExamCenterHandler) MetroClusters() []MetroCluster {
+	return append([]MetroCluster(nil), h.clusters...)
+}
+
+// clusterOf returns the name of the cluster a city belongs to, or ""
+func (h *ExamCenterHandler) clusterOf(city string) string {
+	for _, c := range h.clusters {
+		for _, name := range c.Cities {
+			if name == city {
+				return c.Name
+			}
+		}
+	}
+	return ""
+}
+
+// SetMetroCluster creates a cluster or replaces its cities. A city can be in one cluster only.
+func (h *ExamCenterHandler) SetMetroCluster(actor, name string, cities []string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("cluster name cannot be empty")
+	}
+	var members []string
+	for _, c := range cities {
+		if strings.TrimSpace(c) == "" {
+			continue
+		}
+		city, ok := h.findCity(c)
+		if !ok {
+			return fmt.Errorf("city '%s' not found", strings.TrimSpace(c))
+		}
+		if other := h.clusterOf(city.Name); other != "" && !strings.EqualFold(other, name) {
+			return fmt.Errorf("%s is already in the %s cluster", city.Name, other)
+		}
+		members = append(members, city.Name)
+	}
+	sort.Strings(members)
+	for i := 1; i < len(members); i++ {
+		if members[i] == members[i-1] {
+			return fmt.Errorf("%s is listed twice", members[i])
+		}
+	}
+	if len(members) < 2 {
+		return fmt.Errorf("a cluster needs at least two cities")
+	}
+	cluster := MetroCluster{Name: name, Cities: members}
+	action := "cluster.add"
+	for i, c := range h.clusters {
+		if strings.EqualFold(c.Name, name) {
+			cluster.Name = c.Name
+			h.clusters = append(h.clusters[:i], h.clusters[i+1:]...)
+			action = "cluster.update"
+			break
+		}
+	}
+	h.clusters = append(h.clusters, cluster)
+	sort.Slice(h.clusters, func(i, j int) bool { return h.clusters[i].Name < h.clusters[j].Name })
+	return h.commit(actor, action, cluster.Name, strings.Join(members, ", "))
+}
+
+// RemoveMetroCluster deletes a cluster; its cities stay
+func (h *ExamCenterHandler) RemoveMetroCluster(actor, name string) error {
+	for i, c := range h.clusters {
+		if strings.EqualFold(c.Name, strings.TrimSpace(name)) {
+			h.clusters = append(h.clusters[:i], h.clusters[i+1:]...)
+			return h.commit(actor, "cluster.remove", c.Name, "")
+		}
+	}
+	return fmt.Errorf("cluster '%s' not found", name)
+}
+
+// excludesHome reports whether the exam's home city policy rules city out for a candidate living in home
+func (h *ExamCenterHandler) excludesHome(ex ExamType, home, city string) bool {
+	switch ex.HomeCity {
+	case HomeCityInclude:
+		return false
+	case HomeCityExcludeCluster:
+		if cluster := h.clusterOf(home); cluster != "" && h.clusterOf(city) == cluster {
+			return true
+		}
+	}
+	return strings.EqualFold(home, city)
+} 
\ No newline at end of file
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHomeCityPolicy(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    HomeCityPolicy
+		wantErr bool
+	}{
+		{"", HomeCityExclude, false},
+		{"exclude", HomeCityExclude, false},
+		{" Include ", HomeCityInclude, false},
+		{"exclude_cluster", HomeCityExcludeCluster, false},
+		{"prefer", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseHomeCityPolicy(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseHomeCityPolicy(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestHomeCityPolicies(t *testing.T) {
+	tests := []struct {
+		policy HomeCityPolicy
+		rules  map[string]string // center -> the rule that excludes it; "" when offered
+	}{
+		{HomeCityInclude, map[string]string{"Mumbai Central Exam Center": "", "Navi Mumbai Central Exam Center": "", "Pune University Center": ""}},
+		{HomeCityExclude, map[string]string{"Mumbai Central Exam Center": FilterHomeCity, "Navi Mumbai Central Exam Center": "", "Pune University Center": ""}},
+		{HomeCityExcludeCluster, map[string]string{"Mumbai Central Exam Center": FilterHomeCity, "Navi Mumbai Central Exam Center": FilterHomeCity,
+			"Kalyan Central Exam Center": FilterHomeCity, "Pune University Center": ""}},
+	}
+	reasons := map[string]string{
+		"Mumbai Central Exam Center":      "the candidate's home city",
+		"Navi Mumbai Central Exam Center": "in the candidate's metro cluster (Mumbai Metropolitan Region)",
+		"Kalyan Central Exam Center":      "in the candidate's metro cluster (Mumbai Metropolitan Region)",
+	}
+	for _, tt := range tests {
+		h := NewExamCenterHandler()
+		ex, err := h.GetExamTypeDetails("UPSC")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ex.HomeCity, ex.MaxCenters = tt.policy, 0
+		res, err := h.allocate(DefaultPolicy(ex.Code), "Mumbai", ex, StudentPreference{MaxDistance: 200})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string]CenterVerdict)
+		for _, v := range res.Verdicts {
+			got[v.Center.Name] = v
+		}
+		for center, rule := range tt.rules {
+			v, ok := got[center]
+			switch {
+			case !ok:
+				t.Errorf("%s: no verdict for %s", tt.policy, center)
+			case v.Rule != rule:
+				t.Errorf("%s: %s has rule %q, want %q", tt.policy, center, v.Rule, rule)
+			case rule != "" && v.Reason != reasons[center]:
+				t.Errorf("%s: %s excluded because %q, want %q", tt.policy, center, v.Reason, reasons[center])
+			}
+		}
+	}
+}
+
+func TestSetMetroCluster(t *testing.T) {
+	h := NewExamCenterHandler()
+	tests := []struct {
+		name    string
+		cluster string
+		cities  []string
+		wantErr bool
+	}{
+		{"no name", " ", []string{"Pune", "Nashik"}, true},
+		{"unknown city", "Pune Metro", []string{"Pune", "Lonavala"}, true},
+		{"city in another cluster", "Pune Metro", []string{"Pune", "Kalyan"}, true},
+		{"city listed twice", "Pune Metro", []string{"Pune", "pune"}, true},
+		{"one city", "Pune Metro", []string{"Pune", " "}, true},
+		{"new cluster", "Pune Metro", []string{"pune", "Nashik"}, false},
+		{"same cities again", "pune metro", []string{"Nashik", "Pune", "Aurangabad"}, false},
+	}
+	for _, tt := range tests {
+		if err := h.SetMetroCluster("admin", tt.cluster, tt.cities); (err != nil) != tt.wantErr {
+			t.Errorf("%s: error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+	want := []MetroCluster{
+		{Name: "Delhi NCR", Cities: []string{"Delhi", "Faridabad", "Ghaziabad", "Gurgaon"}},
+		{Name: "Kolkata", Cities: []string{"Howrah", "Kolkata"}},
+		{Name: "Mumbai Metropolitan Region", Cities: []string{"Kalyan", "Mumbai", "Navi Mumbai", "Vasai"}},
+		{Name: "Pune Metro", Cities: []string{"Aurangabad", "Nashik", "Pune"}},
+	}
+	if got := h.MetroClusters(); !reflect.DeepEqual(got, want) {
+		t.Errorf("clusters %+v, want %+v", got, want)
+	}
+
+	if err := h.RemoveMetroCluster("admin", "Kolkata"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.RemoveMetroCluster("admin", "Kolkata"); err == nil {
+		t.Error("removed a cluster twice")
+	}
+	ex := ExamType{HomeCity: HomeCityExcludeCluster}
+	if h.excludesHome(ex, "Kolkata", "Howrah") || !h.excludesHome(ex, "Kolkata", "kolkata") {
+		t.Error("a removed cluster still excludes its cities, or the home city is offered")
+	}
+} 
\ No newline at end of file
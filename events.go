@@ -0,0 +1,39 @@
+package handler
+
+import "time"
+
+// EventKind names something that happened to a registration
+type EventKind string
+
+const (
+	EventRegistrationCreated    EventKind = "registration.created"
+	EventRegistrationReassigned EventKind = "registration.reassigned"
+	EventRegistrationCancelled  EventKind = "registration.cancelled"
+	EventWaitlistPromoted       EventKind = "waitlist.promoted"
+	EventExamReminder           EventKind = "exam.reminder"
+)
+
+// Event is published to subscribers after the change it describes
+type Event struct {
+	Kind           EventKind
+	Time           time.Time
+	Registration   ExamRegistration
+	PreviousCenter string // reassignments only
+	PreviousCity   string
+	Reason         string // reassignments only: why the center changed, when the candidate did not ask for it
+}
+
+// Subscribe registers fn to be called, in order, for every event
+func (h *ExamCenterHandler) Subscribe(fn func(Event)) {
+	h.subscribers = append(h.subscribers, fn)
+}
+
+// emit publishes an event to all subscribers
+func (h *ExamCenterHandler) emit(ev Event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	for _, fn := range h.subscribers {
+		fn(ev)
+	}
+} 
\ No newline at end of file
@@ -0,0 +1,338 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Exam types are stored as data, one ExamType per edition (e.g. JEE 2025 and JEE 2026), each with its own
+// schedule. PredefinedExamTypes only seeds new data directories. Registrations keep a copy of the edition
+// they were made for, so editing or adding an edition never changes existing records.
+
+// seedExamTypes returns the built-in exam types as editions of the year they start in
+func seedExamTypes() []ExamType {
+	exams := make([]ExamType, 0, len(PredefinedExamTypes))
+	for _, ex := range PredefinedExamTypes {
+		ex.Edition = editionOf(ex)
+		ex.Schedule.TimeSlots = append([]string(nil), ex.Schedule.TimeSlots...)
+		exams = append(exams, ex)
+	}
+	sortExamTypes(exams)
+	return exams
+}
+
+// editionOf returns the edition of an exam type, deriving it from the start date for records
+// made before exam types had editions
+func editionOf(ex ExamType) int {
+	if ex.Edition != 0 {
+		return ex.Edition
+	}
+	if t, err := time.Parse("2006-01-02", ex.Schedule.StartDate); err == nil {
+		return t.Year()
+	}
+	return 0
+}
+
+func sortExamTypes(exams []ExamType) {
+	sort.Slice(exams, func(i, j int) bool {
+		if exams[i].Code != exams[j].Code {
+			return exams[i].Code < exams[j].Code
+		}
+		return exams[i].Edition < exams[j].Edition
+	})
+}
+
+// Label names the edition, e.g. "JEE 2025"
+func (e ExamType) Label() string {
+	if e.Edition == 0 {
+		return e.Code
+	}
+	return fmt.Sprintf("%s %d", e.Code, e.Edition)
+}
+
+// SameEdition reports whether two exam types are the same edition of the same exam
+func (e ExamType) SameEdition(o ExamType) bool {
+	return e.Code == o.Code && e.Edition == o.Edition
+}
+
+// ParseExamRef splits "JEE", "JEE 2025", "JEE-2025" or "jee/2025" into a code and an edition (0 when absent)
+func ParseExamRef(ref string) (string, int, error) {
+	fields := strings.FieldsFunc(strings.TrimSpace(ref), func(r rune) bool { return r == ' ' || r == '-' || r == '/' })
+	switch len(fields) {
+	case 1:
+		return strings.ToUpper(fields[0]), 0, nil
+	case 2:
+		edition, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return "", 0, fmt.Errorf("'%s' is not an edition year", fields[1])
+		}
+		return strings.ToUpper(fields[0]), edition, nil
+	}
+	return "", 0, fmt.Errorf("exam type '%s' not found", ref)
+}
+
+// ExamEditions returns every edition of an exam type, oldest first; an empty code returns all exam types
+func (h *ExamCenterHandler) ExamEditions(code string) []ExamType {
+	var list []ExamType
+	for _, ex := range h.exams {
+		if code == "" || strings.EqualFold(ex.Code, strings.TrimSpace(code)) {
+			list = append(list, ex)
+		}
+	}
+	return list
+}
+
+// ExamEdition returns one edition of an exam type
+func (h *ExamCenterHandler) ExamEdition(code string, edition int) (ExamType, error) {
+	editions := h.ExamEditions(code)
+	if len(editions) == 0 {
+		return ExamType{}, fmt.Errorf("exam type '%s' not found", code)
+	}
+	for _, ex := range editions {
+		if ex.Edition == edition {
+			return ex, nil
+		}
+	}
+	return ExamType{}, fmt.Errorf("%s has no %d edition", editions[0].Code, edition)
+}
+
+// currentEdition returns the oldest edition still open for registration, or the newest when all are closed
+func (h *ExamCenterHandler) currentEdition(code string) (ExamType, bool) {
+	editions := h.ExamEditions(code)
+	if len(editions) == 0 {
+		return ExamType{}, false
+	}
+	for _, ex := range editions {
+		if !ex.Closed {
+			return ex, true
+		}
+	}
+	return editions[len(editions)-1], true
+}
+
+// editionOn returns the edition of an exam type whose schedule includes date (YYYY-MM-DD)
+func (h *ExamCenterHandler) editionOn(code, date string) (ExamType, bool) {
+	for _, ex := range h.ExamEditions(code) {
+		for _, d := range examDates(ex.Schedule) {
+			if d == date {
+				return ex, true
+			}
+		}
+	}
+	return ExamType{}, false
+}
+
+// examCode checks that an exam type exists and returns its code in canonical case
+func (h *ExamCenterHandler) examCode(code string) (string, error) {
+	ex, ok := h.currentEdition(code)
+	if !ok {
+		return "", fmt.Errorf("exam type '%s' not found", code)
+	}
+	return ex.Code, nil
+}
+
+// OpenExamEditions returns the editions taking registrations, by code and year
+func (h *ExamCenterHandler) OpenExamEditions() []ExamType {
+	var list []ExamType
+	for _, ex := range h.exams {
+		if !ex.Closed {
+			list = append(list, ex)
+		}
+	}
+	return list
+}
+
+// validateExamType checks an edition before it is saved and puts its fields in canonical form
+func validateExamType(ex *ExamType) error {
+	ex.Code = strings.ToUpper(strings.TrimSpace(ex.Code))
+	ex.Name = strings.TrimSpace(ex.Name)
+	ex.Description = strings.TrimSpace(ex.Description)
+	if ex.Code == "" || len(ex.Code) > 12 || strings.IndexFunc(ex.Code, func(r rune) bool { return (r < 'A' || r > 'Z') && (r < '0' || r > '9') }) >= 0 {
+		return fmt.Errorf("exam code '%s' must be 1 to 12 letters or digits", ex.Code)
+	}
+	if ex.Name == "" {
+		return fmt.Errorf("exam name cannot be empty")
+	}
+	if ex.Edition < 2000 || ex.Edition > 2100 {
+		return fmt.Errorf("edition %d is not a year", ex.Edition)
+	}
+	if ex.Duration <= 0 || ex.Duration > 12*time.Hour {
+		return fmt.Errorf("duration %s must be between 1 minute and 12 hours", ex.Duration)
+	}
+	if ex.MaxCenters < 1 || ex.MaxCenters > 20 {
+		return fmt.Errorf("max centers must be between 1 and 20, not %d", ex.MaxCenters)
+	}
+	if ex.WomenRadius < 0 {
+		return fmt.Errorf("women's radius cannot be negative")
+	}
+	rule, err := ParseAllocationRule(string(ex.Allocation))
+	if err != nil {
+		return err
+	}
+	ex.Allocation = rule
+	if rule == AllocateWithinZone {
+		if _, ok := ZoneSchemes[ex.Zones]; !ok {
+			return fmt.Errorf("zone scheme '%s' not found", ex.Zones)
+		}
+	}
+	if ex.HomeCity, err = ParseHomeCityPolicy(string(ex.HomeCity)); err != nil {
+		return err
+	}
+	return validateSchedule(ex)
+}
+
+// validateSchedule checks the dates and time slots of an edition
+func validateSchedule(ex *ExamType) error {
+	s := &ex.Schedule
+	s.StartDate, s.EndDate = strings.TrimSpace(s.StartDate), strings.TrimSpace(s.EndDate)
+	start, err := time.Parse("2006-01-02", s.StartDate)
+	if err != nil {
+		return fmt.Errorf("start date '%s' is not in YYYY-MM-DD format", s.StartDate)
+	}
+	end, err := time.Parse("2006-01-02", s.EndDate)
+	if err != nil {
+		return fmt.Errorf("end date '%s' is not in YYYY-MM-DD format", s.EndDate)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("exam dates %s to %s end before they start", s.StartDate, s.EndDate)
+	}
+	if start.Year() != ex.Edition {
+		return fmt.Errorf("the %d edition cannot start in %d", ex.Edition, start.Year())
+	}
+	s.RegistrationDeadline = strings.TrimSpace(s.RegistrationDeadline)
+	if s.RegistrationDeadline == "" {
+		return fmt.Errorf("registration deadline cannot be empty (a date, or text such as 'Rolling basis')")
+	}
+	if d, err := time.Parse("2006-01-02", s.RegistrationDeadline); err == nil && d.After(end) {
+		return fmt.Errorf("registration deadline %s is after the last exam day", s.RegistrationDeadline)
+	}
+	var slots []string
+	for _, slot := range s.TimeSlots {
+		slot = strings.ReplaceAll(strings.TrimSpace(slot), " ", "")
+		if slot == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(slot, "-")
+		t1, err1 := time.Parse("15:04", from)
+		t2, err2 := time.Parse("15:04", to)
+		if !ok || err1 != nil || err2 != nil || !t2.After(t1) {
+			return fmt.Errorf("time slot '%s' is not in HH:MM-HH:MM format", slot)
+		}
+		for _, seen := range slots {
+			if seen == slot {
+				return fmt.Errorf("time slot %s is listed twice", slot)
+			}
+		}
+		slots = append(slots, slot)
+	}
+	if len(slots) == 0 {
+		return fmt.Errorf("an exam needs at least one time slot")
+	}
+	s.TimeSlots = slots
+	return nil
+}
+
+// SaveExamEdition adds an exam type edition or replaces one with the same code and year.
+// Existing registrations keep the schedule they were made with.
+func (h *ExamCenterHandler) SaveExamEdition(actor string, ex ExamType) (ExamType, error) {
+	if err := validateExamType(&ex); err != nil {
+		return ExamType{}, err
+	}
+	action := "exam.add"
+	for i, old := range h.exams {
+		if old.SameEdition(ex) {
+			h.exams = append(h.exams[:i], h.exams[i+1:]...)
+			action = "exam.update"
+			break
+		}
+	}
+	h.exams = append(h.exams, ex)
+	sortExamTypes(h.exams)
+	details := fmt.Sprintf("%s to %s, slots %s", ex.Schedule.StartDate, ex.Schedule.EndDate, strings.Join(ex.Schedule.TimeSlots, ", "))
+	return ex, h.commit(actor, action, ex.Label(), details)
+}
+
+// NewExamEdition drafts the next year of an existing exam type as a copy of its latest edition.
+// The draft is not saved; give it a schedule and pass it to SaveExamEdition.
+func (h *ExamCenterHandler) NewExamEdition(code string, edition int) (ExamType, error) {
+	editions := h.ExamEditions(code)
+	if len(editions) == 0 {
+		return ExamType{}, fmt.Errorf("exam type '%s' not found", code)
+	}
+	for _, ex := range editions {
+		if ex.Edition == edition {
+			return ExamType{}, fmt.Errorf("%s already exists", ex.Label())
+		}
+	}
+	ex := editions[len(editions)-1]
+	ex.Edition = edition
+	ex.Schedule.TimeSlots = append([]string(nil), ex.Schedule.TimeSlots...)
+	ex.Closed = false
+	return ex, nil
+}
+
+// SetExamEditionClosed stops or resumes registrations for one edition
+func (h *ExamCenterHandler) SetExamEditionClosed(actor, code string, edition int, closed bool) error {
+	for i, ex := range h.exams {
+		if strings.EqualFold(ex.Code, code) && ex.Edition == edition {
+			h.exams[i].Closed = closed
+			action := "exam.open"
+			if closed {
+				action = "exam.close"
+			}
+			return h.commit(actor, action, ex.Label(), "")
+		}
+	}
+	_, err := h.ExamEdition(code, edition)
+	return err
+}
+
+// RemoveExamEdition deletes an edition nobody has registered, waitlisted or been queued for
+func (h *ExamCenterHandler) RemoveExamEdition(actor, code string, edition int) error {
+	ex, err := h.ExamEdition(code, edition)
+	if err != nil {
+		return err
+	}
+	used := 0
+	for _, reg := range h.registrations {
+		if reg.ExamType.SameEdition(ex) {
+			used++
+		}
+	}
+	for _, e := range h.waitlist {
+		if e.ExamType.SameEdition(ex) {
+			used++
+		}
+	}
+	for _, q := range h.importQueue {
+		if q.ExamType.SameEdition(ex) {
+			used++
+		}
+	}
+	if used > 0 {
+		return fmt.Errorf("%s has %d registrations, waitlist places or queued candidates; close it instead", ex.Label(), used)
+	}
+	for i, old := range h.exams {
+		if old.SameEdition(ex) {
+			h.exams = append(h.exams[:i], h.exams[i+1:]...)
+			break
+		}
+	}
+	return h.commit(actor, "exam.remove", ex.Label(), "")
+}
+
+// backfillEditions sets the edition on records saved before exam types had editions
+func (h *ExamCenterHandler) backfillEditions() {
+	for i := range h.registrations {
+		h.registrations[i].ExamType.Edition = editionOf(h.registrations[i].ExamType)
+	}
+	for i := range h.waitlist {
+		h.waitlist[i].ExamType.Edition = editionOf(h.waitlist[i].ExamType)
+	}
+	for i := range h.importQueue {
+		h.importQueue[i].ExamType.Edition = editionOf(h.importQueue[i].ExamType)
+	}
+} 
\ No newline at end of file
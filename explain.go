@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Every assignment records why the candidate got their center, worked out by the allocation engine
+// (policy.go) just before the seat is booked, so grievances can be answered from the registration
+// even after seats, policies or cities have changed.
+
+// SkipReason groups why a center was passed over
+type SkipReason string
+
+const (
+	SkipFull         SkipReason = "full"              // no seat open to the candidate, or a quota is used up
+	SkipIneligible   SkipReason = "ineligible"        // excluded by the exam's rules, e.g. home city, state or access
+	SkipMaxDistance  SkipReason = "over_max_distance" // beyond the distance or travel limit
+	SkipRankedLower  SkipReason = "ranked_lower"      // eligible, but the policy ranked it below the assigned center
+	SkipRankedHigher SkipReason = "ranked_higher"     // eligible and ranked above the assigned center, which was chosen instead
+)
+
+// Label describes the reason for candidates
+func (r SkipReason) Label() string {
+	switch r {
+	case SkipFull:
+		return "full"
+	case SkipIneligible:
+		return "not eligible"
+	case SkipMaxDistance:
+		return "over the distance limit"
+	case SkipRankedLower:
+		return "ranked lower"
+	case SkipRankedHigher:
+		return "ranked higher, not chosen"
+	}
+	return string(r)
+}
+
+// maxSkipped is how many passed-over centers an explanation lists; the rest are only counted
+const maxSkipped = 10
+
+// SkippedCenter is a center the candidate did not get: one ranked above the assigned center, or one at
+// least as near as it
+type SkippedCenter struct {
+	Center   string
+	City     string
+	Distance float64
+	Reason   SkipReason
+	Rule     string // the policy rule or exclusion behind the reason, see policy.go
+	Detail   string
+}
+
+// AssignmentExplanation records why a registration was given its center
+type AssignmentExplanation struct {
+	Policy          string          // "default", or the exam code when the exam has a policy of its own
+	Rank            int             // the assigned center's place in the policy's ranking; 1 is the first choice
+	Offered         int             // centers offered to the candidate
+	Distance        float64         // km from the home city
+	MaxDistance     float64         // the candidate's limit in km; 0 for none
+	Skipped         []SkippedCenter // the centers passed over: those ranked higher, then the nearest
+	Full            int             // centers excluded as full, near or far
+	Ineligible      int
+	OverMaxDistance int
+	ExplainedAt     time.Time
+}
+
+// skipReason groups an engine verdict
+func skipReason(v CenterVerdict) SkipReason {
+	switch v.Rule {
+	case "", RuleMaxCenters:
+		return SkipRankedLower
+	case FilterHasSeats, RuleQuota:
+		return SkipFull
+	case FilterMaxDistance, FilterMaxTravel:
+		return SkipMaxDistance
+	}
+	return SkipIneligible
+}
+
+// explainAssignment records why a candidate sitting the exam on date in slot is given center under
+// the exam's policy. It is called before the seat is booked so the engine sees what the candidate saw.
+func (h *ExamCenterHandler) explainAssignment(homeCity string, ex ExamType, prefs StudentPreference, center, date, slot string) *AssignmentExplanation {
+	policy, custom := h.AllocationPolicyFor(ex.Code)
+	res, err := h.allocateAt(policy, homeCity, ex, prefs, date, slot)
+	if err != nil {
+		return nil
+	}
+	e := &AssignmentExplanation{Policy: "default", MaxDistance: prefs.MaxDistance, ExplainedAt: time.Now()}
+	if custom {
+		e.Policy = ex.Code
+	}
+	for _, v := range res.Verdicts {
+		if v.Center.Name == center {
+			e.Rank, e.Distance = v.Rank, v.Distance
+		}
+		if v.Rank > 0 {
+			e.Offered++
+		}
+	}
+	for _, v := range res.Verdicts {
+		if v.Center.Name == center {
+			continue
+		}
+		if v.Rule == "" && (e.Rank == 0 || v.Rank < e.Rank) {
+			// offered ahead of the assigned center, which was still the one chosen: say what separates them
+			detail := fmt.Sprintf("ranked %d of %d", v.Rank, e.Offered)
+			if e.Rank > 0 {
+				_, why := policy.compare(res.offers[v.Rank-1], res.offers[e.Rank-1])
+				if why == "" {
+					why = "listed later in its city"
+				}
+				detail += fmt.Sprintf(", ahead of the assigned center (%s)", why)
+			}
+			e.Skipped = append(e.Skipped, SkippedCenter{Center: v.Center.Name, City: v.City, Distance: v.Distance, Reason: SkipRankedHigher, Detail: detail})
+			continue
+		}
+		reason := skipReason(v)
+		switch reason {
+		case SkipFull:
+			e.Full++
+		case SkipIneligible:
+			e.Ineligible++
+		case SkipMaxDistance:
+			e.OverMaxDistance++
+		}
+		if v.Distance <= e.Distance {
+			detail := v.Reason
+			if v.Rule == "" {
+				detail = fmt.Sprintf("ranked %d of %d: %s", v.Rank, e.Offered, v.Behind)
+			}
+			e.Skipped = append(e.Skipped, SkippedCenter{Center: v.Center.Name, City: v.City, Distance: v.Distance, Reason: reason, Rule: v.Rule, Detail: detail})
+		}
+	}
+	sort.SliceStable(e.Skipped, func(i, j int) bool {
+		a, b := e.Skipped[i], e.Skipped[j]
+		if (a.Reason == SkipRankedHigher) != (b.Reason == SkipRankedHigher) {
+			return a.Reason == SkipRankedHigher
+		}
+		return a.Distance < b.Distance
+	})
+	if len(e.Skipped) > maxSkipped {
+		e.Skipped = e.Skipped[:maxSkipped]
+	}
+	return e
+}
+
+// Summary explains the assignment in one sentence
+func (e AssignmentExplanation) Summary() string {
+	s := fmt.Sprintf("Choice %d of %d centers offered under the %s policy, %.1f km from home", e.Rank, e.Offered, e.Policy, e.Distance)
+	if e.MaxDistance > 0 {
+		s += fmt.Sprintf(" (limit %.0f km)", e.MaxDistance)
+	}
+	return s + fmt.Sprintf(". Excluded: %d full, %d not eligible, %d over the distance limit.", e.Full, e.Ineligible, e.OverMaxDistance)
+}
+
+// DisplayExplanation prints an explanation for the console
+func DisplayExplanation(e AssignmentExplanation) {
+	fmt.Println(e.Summary())
+	if len(e.Skipped) > 0 {
+		fmt.Println("Centers passed over:")
+	}
+	for _, s := range e.Skipped {
+		fmt.Printf("   • %s, %s (%.1f km): %s — %s\n", s.Center, s.City, s.Distance, s.Reason.Label(), s.Detail)
+	}
+} 
\ No newline at end of file
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplainAssignment(t *testing.T) {
+	type skip struct {
+		reason SkipReason
+		detail string // part of the detail
+	}
+	tests := []struct {
+		name     string
+		setup    func(h *ExamCenterHandler, ex ExamType)
+		center   string
+		rank     int
+		offered  int
+		skipped  []string // in the order listed
+		reasons  map[string]skip
+		full     int
+		overDist int
+	}{
+		{
+			name:     "first choice",
+			center:   "Pune University Center",
+			rank:     1,
+			offered:  5,
+			skipped:  []string{"Shivaji Nagar Exam Hall", "Kothrud Sports Complex"},
+			reasons:  map[string]skip{"Shivaji Nagar Exam Hall": {SkipRankedLower, "ranked 2 of 5: listed later in its city"}},
+			overDist: 98,
+		},
+		{
+			name: "chosen below a tie",
+			setup: func(h *ExamCenterHandler, ex ExamType) {
+				h.policies[ex.Code] = AllocationPolicy{Exam: ex.Code, Filters: DefaultPolicy(ex.Code).Filters, Weights: PolicyWeights{DistanceKm: 1}, TieBreakers: []string{TieName}}
+			},
+			center:  "Shivaji Nagar Exam Hall",
+			rank:    3,
+			offered: 5,
+			skipped: []string{"Kothrud Sports Complex", "Pune University Center"},
+			reasons: map[string]skip{
+				"Kothrud Sports Complex": {SkipRankedHigher, "ranked 1 of 5, ahead of the assigned center (same score, later by name)"},
+				"Pune University Center": {SkipRankedHigher, "ranked 2 of 5"},
+			},
+			overDist: 98,
+		},
+		{
+			name:    "farther city",
+			center:  "Navi Mumbai University Center",
+			rank:    5,
+			offered: 5,
+			skipped: []string{"Pune University Center", "Shivaji Nagar Exam Hall", "Kothrud Sports Complex", "Navi Mumbai Central Exam Center"},
+			reasons: map[string]skip{
+				"Pune University Center":          {SkipRankedHigher, "ahead of the assigned center (score 104.1 against 0.0)"},
+				"Navi Mumbai Central Exam Center": {SkipRankedHigher, "listed later in its city"},
+			},
+			overDist: 98,
+		},
+		{
+			name: "nearer center full",
+			setup: func(h *ExamCenterHandler, ex ExamType) {
+				h.centerCapacity["Pune University Center"] = CenterCapacity{TotalSeats: 100, BookedSeats: 100}
+			},
+			center:   "Shivaji Nagar Exam Hall",
+			rank:     1,
+			offered:  4,
+			skipped:  []string{"Kothrud Sports Complex", "Pune University Center"},
+			reasons:  map[string]skip{"Pune University Center": {SkipFull, "full"}},
+			full:     1,
+			overDist: 98,
+		},
+		{
+			name: "nearer center closed",
+			setup: func(h *ExamCenterHandler, ex ExamType) {
+				h.closures = append(h.closures, CenterClosure{Center: "Pune University Center", City: "Pune", Date: "2024-06-02", Reason: "flooded"})
+			},
+			center:   "Shivaji Nagar Exam Hall",
+			rank:     1,
+			offered:  4,
+			skipped:  []string{"Kothrud Sports Complex", "Pune University Center"},
+			reasons:  map[string]skip{"Pune University Center": {SkipIneligible, "flooded"}},
+			overDist: 98,
+		},
+	}
+	for _, tt := range tests {
+		h, ex := allocationHandler(t)
+		if tt.setup != nil {
+			tt.setup(h, ex)
+		}
+		e := h.explainAssignment("Pune", ex, StudentPreference{MaxDistance: 200}, tt.center, "2024-06-02", "09:30-12:30")
+		if e == nil {
+			t.Fatalf("%s: no explanation", tt.name)
+		}
+		if e.Rank != tt.rank || e.Offered != tt.offered || e.Full != tt.full || e.OverMaxDistance != tt.overDist {
+			t.Errorf("%s: %s", tt.name, e.Summary())
+		}
+		var names []string
+		for _, s := range e.Skipped {
+			names = append(names, s.Center)
+			if want, ok := tt.reasons[s.Center]; ok && (s.Reason != want.reason || !strings.Contains(s.Detail, want.detail)) {
+				t.Errorf("%s: %s skipped as %s (%s), want %s (%s)", tt.name, s.Center, s.Reason, s.Detail, want.reason, want.detail)
+			}
+		}
+		if strings.Join(names, ", ") != strings.Join(tt.skipped, ", ") {
+			t.Errorf("%s: skipped %v, want %v", tt.name, names, tt.skipped)
+		}
+	}
+}
+func TestRegistrationIsExplainedAtItsOwnSitting(t *testing.T) {
+	h, ex := allocationHandler(t)
+	pune, ok := h.findCenter("Pune University Center")
+	if !ok {
+		t.Fatal("Pune University Center not found")
+	}
+	student := StudentInfo{Name: "Asha Rao", RollNumber: "R1", Phone: "9876543210"}
+	// The candidate already sits another exam in the first slot, so the new registration moves to the afternoon
+	h.registrations = append(h.registrations, ExamRegistration{ID: "CAT-R1-1", CandidateID: "C000001", StudentName: student.Name,
+		RollNumber: student.RollNumber, Phone: student.Phone, ExamType: ExamType{Code: "CAT"}, AssignedCenter: pune.Name,
+		AssignedCity: "Pune", ExamDate: "2024-06-02", TimeSlot: "09:30-12:30"})
+	h.closures = append(h.closures, CenterClosure{Center: "Shivaji Nagar Exam Hall", City: "Pune", Date: "2024-06-02", Slot: "14:30-17:30", Reason: "power cut"})
+
+	reg := h.createRegistration(student, ex, CityDistance{City: h.cities["Pune"], Centers: []ExamCenter{pune}}, "Pune", StudentPreference{MaxDistance: 200})
+	if reg.ExamDate != "2024-06-02" || reg.TimeSlot != "14:30-17:30" {
+		t.Fatalf("registered for %s %s, want the afternoon slot", reg.ExamDate, reg.TimeSlot)
+	}
+	if reg.Explanation == nil {
+		t.Fatal("no explanation")
+	}
+	for _, s := range reg.Explanation.Skipped {
+		if s.Center == "Shivaji Nagar Exam Hall" {
+			if s.Reason != SkipIneligible || !strings.Contains(s.Detail, "power cut") {
+				t.Errorf("Shivaji Nagar Exam Hall skipped as %s (%s), want closed for the afternoon", s.Reason, s.Detail)
+			}
+			return
+		}
+	}
+	t.Errorf("Shivaji Nagar Exam Hall not listed in %s", reg.Explanation.Summary())
+} 
\ No newline at end of file
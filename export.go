@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RegistrationFilter selects registrations; empty fields match everything
+type RegistrationFilter struct {
+	Exam     string // exam code
+	Edition  int    // exam year; 0 matches every edition
+	City     string // assigned city
+	Center   string // assigned center
+	HomeCity string
+	From     string // first exam date, YYYY-MM-DD
+	To       string // last exam date, YYYY-MM-DD
+	Name     string // part of the candidate name, any case
+}
+
+// Validate checks the date range
+func (f RegistrationFilter) Validate() error {
+	for _, d := range []string{f.From, f.To} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return fmt.Errorf("date '%s' is not in YYYY-MM-DD format", d)
+		}
+	}
+	if f.From != "" && f.To != "" && f.From > f.To {
+		return fmt.Errorf("date range %s to %s ends before it starts", f.From, f.To)
+	}
+	return nil
+}
+
+// Match reports whether a registration passes the filter
+func (f RegistrationFilter) Match(reg ExamRegistration) bool {
+	if f.Exam != "" && !strings.EqualFold(f.Exam, reg.ExamType.Code) {
+		return false
+	}
+	if f.Edition != 0 && f.Edition != reg.ExamType.Edition {
+		return false
+	}
+	if f.City != "" && !strings.EqualFold(f.City, reg.AssignedCity) {
+		return false
+	}
+	if f.Center != "" && !strings.EqualFold(f.Center, reg.AssignedCenter) {
+		return false
+	}
+	if f.HomeCity != "" && !strings.EqualFold(f.HomeCity, reg.StudentCity) {
+		return false
+	}
+	if f.Name != "" && !strings.Contains(strings.ToLower(reg.StudentName), strings.ToLower(strings.TrimSpace(f.Name))) {
+		return false
+	}
+	// YYYY-MM-DD dates compare correctly as strings
+	if f.From != "" && reg.ExamDate < f.From {
+		return false
+	}
+	if f.To != "" && reg.ExamDate > f.To {
+		return false
+	}
+	return true
+}
+
+// FilterRegistrations returns the registrations matching f, in registration order.
+// allowed limits the result to what the caller may see; nil allows all.
+func (h *ExamCenterHandler) FilterRegistrations(f RegistrationFilter, allowed func(ExamRegistration) bool) []ExamRegistration {
+	var list []ExamRegistration
+	for _, reg := range h.registrations {
+		if f.Match(reg) && (allowed == nil || allowed(reg)) {
+			list = append(list, reg)
+		}
+	}
+	return list
+}
+
+// Table is a named grid of values ready for export. Cells are strings, ints, float64s or bools.
+type Table struct {
+	Name    string
+	Columns []string
+	Rows    [][]any
+}
+
+// RegistrationsTable lays out registrations one per row
+func RegistrationsTable(regs []ExamRegistration) Table {
+	t := Table{
+		Name: "Registrations",
+		Columns: []string{"registration_id", "roll_number", "candidate_id", "student_name", "exam", "edition", "home_city", "assigned_city",
+			"assigned_center", "exam_date", "time_slot", "room", "seat", "distance_km", "category", "scribe", "phone", "email", "registered_at"},
+	}
+	for _, r := range regs {
+		t.Rows = append(t.Rows, []any{r.ID, r.RollNumber, r.CandidateID, r.StudentName, r.ExamType.Code, r.ExamType.Edition, r.StudentCity, r.AssignedCity, r.AssignedCenter,
+			r.ExamDate, r.TimeSlot, r.Room, r.SeatNumber, roundKm(r.Distance), r.Preferences.categoryCodes(), r.Preferences.NeedsScribe, r.Phone, r.Email,
+			r.RegistrationTime.Format(time.RFC3339)})
+	}
+	return t
+}
+
+// UtilizationTable lists seat totals per center in f.City (all cities when empty) together with how
+// many of regs are assigned there, so an exam or date filter shows its share of each center
+func (h *ExamCenterHandler) UtilizationTable(f RegistrationFilter, regs []ExamRegistration) Table {
+	t := Table{
+		Name: "Utilization",
+		Columns: []string{"center", "city", "total_seats", "booked_seats", "available_seats", "fill_percent", "matching_registrations", "disabled",
+			"accessible", "scribes", "priority_seats"},
+	}
+	matching := make(map[string]int)
+	for _, r := range regs {
+		matching[r.AssignedCenter]++
+	}
+	for _, c := range h.ListCenters(f.City) {
+		fill := 0.0
+		if c.Capacity.TotalSeats > 0 {
+			fill = float64(c.Capacity.BookedSeats) / float64(c.Capacity.TotalSeats) * 100
+		}
+		t.Rows = append(t.Rows, []any{c.Center.Name, c.Center.City, c.Capacity.TotalSeats, c.Capacity.BookedSeats, c.Capacity.AvailableSeats,
+			roundKm(fill), matching[c.Center.Name], c.Center.Disabled, c.Center.Accessible, c.Center.Scribes, c.Center.PrioritySeats})
+	}
+	return t
+}
+
+// roundKm keeps one decimal place
+func roundKm(v float64) float64 {
+	f, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'f', 1, 64), 64)
+	return f
+}
+
+// ExportFormat is a file format for tables
+type ExportFormat string
+
+const (
+	FormatCSV   ExportFormat = "csv"
+	FormatJSONL ExportFormat = "jsonl"
+	FormatXLSX  ExportFormat = "xlsx"
+)
+
+// ParseExportFormat reads a format name
+func ParseExportFormat(s string) (ExportFormat, error) {
+	switch f := ExportFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatCSV, FormatJSONL, FormatXLSX:
+		return f, nil
+	case "json":
+		return FormatJSONL, nil
+	}
+	return "", fmt.Errorf("unknown export format '%s' (use csv, jsonl or xlsx)", s)
+}
+
+// ContentType is the MIME type for downloads
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// WriteTable writes t in the given format
+func WriteTable(w io.Writer, t Table, format ExportFormat) error {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write(t.Columns)
+		for _, row := range t.Rows {
+			rec := make([]string, len(row))
+			for i, v := range row {
+				rec[i] = cellText(v)
+			}
+			_ = cw.Write(rec)
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("error writing CSV: %v", err)
+		}
+		return nil
+	case FormatJSONL:
+		// Objects are built by hand so keys keep the column order
+		for _, row := range t.Rows {
+			var b strings.Builder
+			b.WriteByte('{')
+			for i, v := range row {
+				key, _ := json.Marshal(t.Columns[i])
+				val, err := json.Marshal(v)
+				if err != nil {
+					return fmt.Errorf("error writing JSON: %v", err)
+				}
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				b.Write(key)
+				b.WriteByte(':')
+				b.Write(val)
+			}
+			b.WriteString("}\n")
+			if _, err := io.WriteString(w, b.String()); err != nil {
+				return fmt.Errorf("error writing JSON: %v", err)
+			}
+		}
+		return nil
+	case FormatXLSX:
+		return writeXLSX(w, t)
+	}
+	return fmt.Errorf("unknown export format '%s'", format)
+}
+
+func cellText(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case int:
+		return strconv.Itoa(x)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	return fmt.Sprint(v)
+} 
\ No newline at end of file
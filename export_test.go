@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestRegistrationFilter(t *testing.T) {
+	reg := ExamRegistration{ID: "NEET-2024-R1-1", StudentName: "Asha Verma", StudentCity: "Pune", ExamType: ExamType{Code: "NEET", Edition: 2024},
+		ExamDate: "2024-05-05", AssignedCity: "Navi Mumbai", AssignedCenter: "Navi Mumbai Central Exam Center"}
+	tests := []struct {
+		name   string
+		filter RegistrationFilter
+		want   bool
+	}{
+		{"empty", RegistrationFilter{}, true},
+		{"exam in any case", RegistrationFilter{Exam: "neet", Edition: 2024}, true},
+		{"other edition", RegistrationFilter{Exam: "NEET", Edition: 2025}, false},
+		{"assigned city", RegistrationFilter{City: "navi mumbai"}, true},
+		{"home city is not the assigned city", RegistrationFilter{City: "Pune"}, false},
+		{"home city", RegistrationFilter{HomeCity: "Pune"}, true},
+		{"center", RegistrationFilter{Center: "Pune University Center"}, false},
+		{"part of the name", RegistrationFilter{Name: " verm "}, true},
+		{"other name", RegistrationFilter{Name: "Rahul"}, false},
+		{"inside the dates", RegistrationFilter{From: "2024-05-05", To: "2024-05-05"}, true},
+		{"before the dates", RegistrationFilter{From: "2024-05-06"}, false},
+		{"after the dates", RegistrationFilter{To: "2024-05-04"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(reg); got != tt.want {
+			t.Errorf("%s: Match = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	for _, f := range []RegistrationFilter{{From: "05/05/2024"}, {From: "2024-05-06", To: "2024-05-05"}} {
+		if err := f.Validate(); err == nil {
+			t.Errorf("%+v: no error, want one", f)
+		}
+	}
+}
+
+func TestParseExportFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ExportFormat
+		wantErr bool
+	}{
+		{"csv", FormatCSV, false},
+		{" XLSX ", FormatXLSX, false},
+		{"json", FormatJSONL, false},
+		{"jsonl", FormatJSONL, false},
+		{"pdf", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseExportFormat(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseExportFormat(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func exportTable() Table {
+	return Table{
+		Name:    "Utilization",
+		Columns: []string{"center", "seats", "fill_percent", "disabled"},
+		Rows: [][]any{
+			{"Pune University Center", 120, 37.5, false},
+			{`Hall "A" & <B>, east`, 0, 0.0, true},
+		},
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	tests := []struct {
+		format ExportFormat
+		want   string
+	}{
+		{FormatCSV, "center,seats,fill_percent,disabled\n" +
+			"Pune University Center,120,37.5,false\n" +
+			"\"Hall \"\"A\"\" & <B>, east\",0,0,true\n"},
+		{FormatJSONL, `{"center":"Pune University Center","seats":120,"fill_percent":37.5,"disabled":false}` + "\n" +
+			`{"center":"Hall \"A\" \u0026 \u003cB\u003e, east","seats":0,"fill_percent":0,"disabled":true}` + "\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := WriteTable(&buf, exportTable(), tt.format); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s:\n%s\nwant:\n%s", tt.format, buf.String(), tt.want)
+		}
+	}
+	if err := WriteTable(io.Discard, exportTable(), "ods"); err == nil {
+		t.Error("unknown format written, want an error")
+	}
+}
+
+func TestWriteTableXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTable(&buf, exportTable(), FormatXLSX); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/workbook.xml"} {
+		var root struct{ XMLName xml.Name }
+		if err := xml.Unmarshal(parts[name], &root); err != nil {
+			t.Errorf("part %s: %v", name, err)
+		}
+	}
+	if !bytes.Contains(parts["xl/workbook.xml"], []byte(`<sheet name="Utilization"`)) {
+		t.Errorf("workbook does not name the sheet: %s", parts["xl/workbook.xml"])
+	}
+
+	var sheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Style  int    `xml:"s,attr"`
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet); err != nil {
+		t.Fatal(err)
+	}
+	var got [][]string
+	for _, row := range sheet.Rows {
+		var cells []string
+		for _, c := range row.Cells {
+			text := c.Value
+			if c.Type == "inlineStr" {
+				text = c.Inline
+			}
+			cells = append(cells, c.Ref+"|"+c.Type+"|"+text)
+		}
+		if row.R == 1 && row.Cells[0].Style != 1 {
+			t.Errorf("header row is not bold")
+		}
+		got = append(got, cells)
+	}
+	want := [][]string{
+		{"A1|inlineStr|center", "B1|inlineStr|seats", "C1|inlineStr|fill_percent", "D1|inlineStr|disabled"},
+		{"A2|inlineStr|Pune University Center", "B2||120", "C2||37.5", "D2|b|0"},
+		{`A3|inlineStr|Hall "A" & <B>, east`, "B3||0", "C3||0", "D3|b|1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sheet cells %q, want %q", got, want)
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumn(i); got != want {
+			t.Errorf("xlsxColumn(%d) = %s, want %s", i, got, want)
+		}
+	}
+} 
\ No newline at end of file
@@ -0,0 +1,612 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GeoFormat is a file format for exchanging city and center locations with GIS tools such as QGIS
+type GeoFormat string
+
+const (
+	FormatGeoJSON GeoFormat = "geojson"
+	FormatKML     GeoFormat = "kml"
+)
+
+// ParseGeoFormat reads a format name. A file name works too and is judged by its extension.
+func ParseGeoFormat(s string) (GeoFormat, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	switch f := GeoFormat(name); f {
+	case FormatGeoJSON, FormatKML:
+		return f, nil
+	case "json":
+		return FormatGeoJSON, nil
+	}
+	return "", fmt.Errorf("unknown map format '%s' (use geojson or kml)", s)
+}
+
+// ContentType is the MIME type for downloads
+func (f GeoFormat) ContentType() string {
+	if f == FormatKML {
+		return "application/vnd.google-earth.kml+xml"
+	}
+	return "application/geo+json"
+}
+
+// Feature kinds in an exchange file
+const (
+	FeatureCity   = "city"
+	FeatureCenter = "center"
+)
+
+// GeoFeature is a city or an exam center as a point. Centers have no location of their own and
+// are placed at their city; moving a center means changing its city.
+type GeoFeature struct {
+	Kind           string
+	Name           string
+	City           string // the center's city; empty for cities
+	State          string // a center's state and district are its city's and are ignored on import
+	District       string
+	Lat            float64
+	Lng            float64
+	TotalSeats     int  // centers only
+	BookedSeats    int  // read-only
+	AvailableSeats int  // read-only
+	Eligible       bool // candidates can be assigned here; false disables it
+	hasSeats       bool // TotalSeats was given in an imported file
+}
+
+// GeoFeatures returns every city and center, disabled ones included, cities first
+func (h *ExamCenterHandler) GeoFeatures() []GeoFeature {
+	var list []GeoFeature
+	for _, c := range h.ListCities() {
+		list = append(list, GeoFeature{Kind: FeatureCity, Name: c.Name, State: c.State, District: c.District, Lat: c.Lat, Lng: c.Lng, Eligible: !c.Disabled})
+	}
+	for _, c := range h.ListCenters("") {
+		city := h.cities[c.Center.City]
+		list = append(list, GeoFeature{Kind: FeatureCenter, Name: c.Center.Name, City: c.Center.City, State: city.State, District: city.District,
+			Lat: city.Lat, Lng: city.Lng, TotalSeats: c.Capacity.TotalSeats, BookedSeats: c.Capacity.BookedSeats,
+			AvailableSeats: c.Capacity.AvailableSeats, Eligible: !c.Center.Disabled})
+	}
+	return list
+}
+
+// properties lists a feature's attributes in file order
+func (f GeoFeature) properties() [][2]string {
+	p := [][2]string{{"kind", f.Kind}, {"name", f.Name}}
+	if f.Kind == FeatureCenter {
+		p = append(p, [2]string{"city", f.City})
+	}
+	p = append(p, [2]string{"state", f.State}, [2]string{"district", f.District})
+	if f.Kind == FeatureCenter {
+		p = append(p, [2]string{"total_seats", strconv.Itoa(f.TotalSeats)}, [2]string{"booked_seats", strconv.Itoa(f.BookedSeats)},
+			[2]string{"available_seats", strconv.Itoa(f.AvailableSeats)})
+	}
+	return append(p, [2]string{"eligible", strconv.FormatBool(f.Eligible)})
+}
+
+type geoJSONCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string         `json:"type"`
+	ID         string         `json:"id,omitempty"`
+	Geometry   *geoJSONPoint  `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+type geoJSONPoint struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// WriteGeoJSON writes the features as a GeoJSON FeatureCollection of points
+func WriteGeoJSON(w io.Writer, features []GeoFeature) error {
+	fc := geoJSONCollection{Type: "FeatureCollection", Features: make([]geoJSONFeature, 0, len(features))}
+	for _, f := range features {
+		props := make(map[string]any)
+		for _, p := range f.properties() {
+			switch p[0] {
+			case "total_seats", "booked_seats", "available_seats":
+				n, _ := strconv.Atoi(p[1])
+				props[p[0]] = n
+			case "eligible":
+				props[p[0]] = f.Eligible
+			default:
+				props[p[0]] = p[1]
+			}
+		}
+		fc.Features = append(fc.Features, geoJSONFeature{Type: "Feature", ID: f.Kind + ":" + f.Name,
+			Geometry: &geoJSONPoint{Type: "Point", Coordinates: []float64{f.Lng, f.Lat}}, Properties: props})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(fc)
+}
+
+type kmlFile struct {
+	XMLName  xml.Name    `xml:"kml"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name    string      `xml:"name"`
+	Folders []kmlFolder `xml:"Folder"`
+}
+
+type kmlFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name         string           `xml:"name"`
+	ExtendedData *kmlExtendedData `xml:"ExtendedData"`
+	Point        *kmlPoint        `xml:"Point"`
+}
+
+type kmlExtendedData struct {
+	Data       []kmlData      `xml:"Data"`
+	SchemaData *kmlSchemaData `xml:"SchemaData"` // as written by QGIS
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlSchemaData struct {
+	SimpleData []kmlSimpleData `xml:"SimpleData"`
+}
+
+type kmlSimpleData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"` // lng,lat[,alt]
+}
+
+// WriteKML writes the features as KML placemarks in a Cities and a Centers folder,
+// with the attributes as ExtendedData
+func WriteKML(w io.Writer, features []GeoFeature) error {
+	doc := kmlFile{Xmlns: "http://www.opengis.net/kml/2.2", Document: kmlDocument{Name: "ExamCenterHub cities and centers"}}
+	cities := kmlFolder{Name: "Cities"}
+	centers := kmlFolder{Name: "Centers"}
+	for _, f := range features {
+		pm := kmlPlacemark{Name: f.Name, ExtendedData: &kmlExtendedData{}, Point: &kmlPoint{Coordinates: formatCoord(f.Lng) + "," + formatCoord(f.Lat)}}
+		for _, p := range f.properties() {
+			pm.ExtendedData.Data = append(pm.ExtendedData.Data, kmlData{Name: p[0], Value: p[1]})
+		}
+		if f.Kind == FeatureCity {
+			cities.Placemarks = append(cities.Placemarks, pm)
+		} else {
+			centers.Placemarks = append(centers.Placemarks, pm)
+		}
+	}
+	doc.Document.Folders = []kmlFolder{cities, centers}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func formatCoord(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+
+// ReadGeo reads cities and centers from a GeoJSON or KML file. Read-only attributes are ignored.
+func ReadGeo(r io.Reader, format GeoFormat) ([]GeoFeature, error) {
+	if format == FormatKML {
+		return readKML(r)
+	}
+	return readGeoJSON(r)
+}
+
+func readGeoJSON(r io.Reader) ([]GeoFeature, error) {
+	var fc geoJSONCollection
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, fmt.Errorf("error decoding GeoJSON: %v", err)
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("GeoJSON must be a FeatureCollection, not '%s'", fc.Type)
+	}
+	var list []GeoFeature
+	for i, gf := range fc.Features {
+		if gf.Geometry == nil || gf.Geometry.Type != "Point" || len(gf.Geometry.Coordinates) < 2 {
+			return nil, fmt.Errorf("feature %d: geometry must be a Point", i+1)
+		}
+		props := make(map[string]string)
+		for k, v := range gf.Properties {
+			switch v := v.(type) {
+			case string:
+				props[k] = v
+			case float64:
+				props[k] = strconv.FormatFloat(v, 'f', -1, 64)
+			case bool:
+				props[k] = strconv.FormatBool(v)
+			}
+		}
+		f, err := newGeoFeature(props, gf.Geometry.Coordinates[1], gf.Geometry.Coordinates[0])
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %v", i+1, err)
+		}
+		list = append(list, f)
+	}
+	return list, nil
+}
+
+// readKML collects every Placemark, however deeply it is nested in folders
+func readKML(r io.Reader) ([]GeoFeature, error) {
+	dec := xml.NewDecoder(r)
+	var list []GeoFeature
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error decoding KML: %v", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "Placemark" {
+			continue
+		}
+		var pm kmlPlacemark
+		if err := dec.DecodeElement(&pm, &start); err != nil {
+			return nil, fmt.Errorf("error decoding KML: %v", err)
+		}
+		n := len(list) + 1
+		if pm.Point == nil {
+			return nil, fmt.Errorf("placemark %d: geometry must be a Point", n)
+		}
+		coords := strings.Split(strings.TrimSpace(pm.Point.Coordinates), ",")
+		if len(coords) < 2 {
+			return nil, fmt.Errorf("placemark %d: bad coordinates '%s'", n, pm.Point.Coordinates)
+		}
+		lng, errLng := strconv.ParseFloat(strings.TrimSpace(coords[0]), 64)
+		lat, errLat := strconv.ParseFloat(strings.TrimSpace(coords[1]), 64)
+		if errLng != nil || errLat != nil {
+			return nil, fmt.Errorf("placemark %d: bad coordinates '%s'", n, pm.Point.Coordinates)
+		}
+		props := map[string]string{"name": strings.TrimSpace(pm.Name)}
+		if ext := pm.ExtendedData; ext != nil {
+			for _, d := range ext.Data {
+				props[d.Name] = strings.TrimSpace(d.Value)
+			}
+			if ext.SchemaData != nil {
+				for _, d := range ext.SchemaData.SimpleData {
+					props[d.Name] = strings.TrimSpace(d.Value)
+				}
+			}
+		}
+		f, err := newGeoFeature(props, lat, lng)
+		if err != nil {
+			return nil, fmt.Errorf("placemark %d: %v", n, err)
+		}
+		list = append(list, f)
+	}
+	return list, nil
+}
+
+// newGeoFeature builds a feature from its attributes. Without a kind, a feature with a city is a center.
+func newGeoFeature(props map[string]string, lat, lng float64) (GeoFeature, error) {
+	f := GeoFeature{Kind: strings.ToLower(strings.TrimSpace(props["kind"])), Name: strings.TrimSpace(props["name"]),
+		City: strings.TrimSpace(props["city"]), District: strings.TrimSpace(props["district"]), Lat: lat, Lng: lng, Eligible: true}
+	if f.Kind == "" {
+		f.Kind = FeatureCity
+		if f.City != "" {
+			f.Kind = FeatureCenter
+		}
+	}
+	if f.Kind != FeatureCity && f.Kind != FeatureCenter {
+		return f, fmt.Errorf("kind must be city or center, not '%s'", f.Kind)
+	}
+	if f.Name == "" {
+		return f, fmt.Errorf("name cannot be empty")
+	}
+	if f.Kind == FeatureCenter && f.City == "" {
+		return f, fmt.Errorf("center '%s' has no city", f.Name)
+	}
+	if err := validateCoordinates(lat, lng); err != nil {
+		return f, err
+	}
+	state, err := NormalizeState(props["state"])
+	if err != nil && f.Kind == FeatureCity {
+		return f, fmt.Errorf("city '%s': %v", f.Name, err)
+	}
+	f.State = state
+	if v := strings.TrimSpace(props["total_seats"]); v != "" && f.Kind == FeatureCenter {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || n < 0 || n != math.Trunc(n) {
+			return f, fmt.Errorf("total_seats of '%s' must be a whole number, not '%s'", f.Name, v)
+		}
+		f.TotalSeats, f.hasSeats = int(n), true
+	}
+	if v := strings.TrimSpace(props["eligible"]); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, fmt.Errorf("eligible of '%s' must be true or false, not '%s'", f.Name, v)
+		}
+		f.Eligible = b
+	}
+	return f, nil
+}
+
+// Kinds of difference between an imported file and the current data
+const (
+	GeoAdded   = "added"
+	GeoMoved   = "moved"
+	GeoUpdated = "updated"
+	GeoRemoved = "removed" // applied by disabling, since registrations may still refer to it
+)
+
+// GeoChange is one difference between an imported file and the current data
+type GeoChange struct {
+	Kind    string // FeatureCity or FeatureCenter
+	Name    string
+	Change  string
+	Details string
+}
+
+// GeoDiff is the review of an imported file. Any problem blocks applying it; notes do not.
+type GeoDiff struct {
+	Changes   []GeoChange
+	Unchanged int
+	Problems  []string
+	Notes     []string
+}
+
+// Count returns how many changes are of the given kind
+func (d GeoDiff) Count(change string) int {
+	n := 0
+	for _, c := range d.Changes {
+		if c.Change == change {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary describes the changes in a few words, e.g. "2 added, 1 moved, 0 updated, 3 removed"
+func (d GeoDiff) Summary() string {
+	return fmt.Sprintf("%d added, %d moved, %d updated, %d removed", d.Count(GeoAdded), d.Count(GeoMoved), d.Count(GeoUpdated), d.Count(GeoRemoved))
+}
+
+// Fingerprint identifies the set of changes, so a reviewed diff can be checked
+// against the data at the time it is applied
+func (d GeoDiff) Fingerprint() string {
+	sum := sha256.New()
+	for _, c := range d.Changes {
+		fmt.Fprintf(sum, "%s\x00%s\x00%s\x00%s\n", c.Kind, c.Name, c.Change, c.Details)
+	}
+	return hex.EncodeToString(sum.Sum(nil))[:16]
+}
+
+// Centers further than this from their city get a note, since their point is ignored
+const geoCenterSlackKm = 1.0
+
+// DiffGeo compares the features with the current cities and centers without changing anything.
+// Cities or centers missing from the file are removed, but only when the file has any of that kind.
+func (h *ExamCenterHandler) DiffGeo(features []GeoFeature) GeoDiff {
+	return h.reconcileGeo(features, false)
+}
+
+// ApplyGeo applies an imported file after checking it with DiffGeo. Removed cities and centers
+// are disabled rather than deleted so existing registrations keep pointing at them.
+func (h *ExamCenterHandler) ApplyGeo(actor, source string, features []GeoFeature) (GeoDiff, error) {
+	d := h.reconcileGeo(features, false)
+	if len(d.Problems) > 0 {
+		return d, fmt.Errorf("the file has %d problems; fix them and import it again", len(d.Problems))
+	}
+	if len(d.Changes) == 0 {
+		return d, nil
+	}
+	d = h.reconcileGeo(features, true)
+	h.PromoteWaitlist()
+	return d, h.commit(actor, "geo.import", source, d.Summary())
+}
+
+// reconcileGeo works out the changes a file makes, and makes them when apply is set.
+// Cities are handled before centers so a center can move to a city added by the same file.
+func (h *ExamCenterHandler) reconcileGeo(features []GeoFeature, apply bool) GeoDiff {
+	var d GeoDiff
+	change := func(kind, name, what, details string) {
+		d.Changes = append(d.Changes, GeoChange{Kind: kind, Name: name, Change: what, Details: details})
+	}
+	eligibility := func(eligible bool) string {
+		if eligible {
+			return "enabled"
+		}
+		return "disabled"
+	}
+
+	fileCities := make(map[string]GeoFeature) // lower-case name -> feature
+	fileCenters := make(map[string]bool)
+	hasCenters := false
+	for _, f := range features {
+		key := strings.ToLower(f.Name)
+		if f.Kind == FeatureCity {
+			if _, dup := fileCities[key]; dup {
+				d.Problems = append(d.Problems, fmt.Sprintf("city '%s' appears more than once", f.Name))
+			}
+			fileCities[key] = f
+			continue
+		}
+		hasCenters = true
+		if fileCenters[key] {
+			d.Problems = append(d.Problems, fmt.Sprintf("center '%s' appears more than once", f.Name))
+		}
+		fileCenters[key] = true
+	}
+
+	for _, f := range features {
+		if f.Kind != FeatureCity {
+			continue
+		}
+		city, ok := h.findCity(f.Name)
+		if !ok {
+			if f.State == "" {
+				d.Problems = append(d.Problems, fmt.Sprintf("new city '%s' has no state", f.Name))
+				continue
+			}
+			change(FeatureCity, f.Name, GeoAdded, fmt.Sprintf("%s, %s: %.4f, %.4f", f.District, f.State, f.Lat, f.Lng))
+			if apply {
+				h.cities[f.Name] = City{Name: f.Name, State: f.State, District: f.District, Lat: f.Lat, Lng: f.Lng, Disabled: !f.Eligible}
+			}
+			continue
+		}
+		changed := false
+		if f.State != "" && (f.State != city.State || f.District != city.District) {
+			change(FeatureCity, city.Name, GeoUpdated, fmt.Sprintf("%s, %s -> %s, %s", city.District, city.State, f.District, f.State))
+			city.State, city.District, changed = f.State, f.District, true
+		}
+		if moved := h.calculateDistance(city, City{Lat: f.Lat, Lng: f.Lng}); moved > 0.01 {
+			change(FeatureCity, city.Name, GeoMoved, fmt.Sprintf("%.1f km: %.4f, %.4f -> %.4f, %.4f", moved, city.Lat, city.Lng, f.Lat, f.Lng))
+			city.Lat, city.Lng, changed = f.Lat, f.Lng, true
+		}
+		if city.Disabled == f.Eligible {
+			change(FeatureCity, city.Name, GeoUpdated, eligibility(f.Eligible))
+			city.Disabled, changed = !f.Eligible, true
+		}
+		if !changed {
+			d.Unchanged++
+		} else if apply {
+			h.cities[city.Name] = city
+		}
+	}
+	if len(fileCities) > 0 {
+		for _, city := range h.ListCities() {
+			if _, ok := fileCities[strings.ToLower(city.Name)]; !ok && !city.Disabled {
+				change(FeatureCity, city.Name, GeoRemoved, "will be disabled")
+				if apply {
+					city.Disabled = true
+					h.cities[city.Name] = city
+				}
+			}
+		}
+	}
+
+	for _, f := range features {
+		if f.Kind != FeatureCenter {
+			continue
+		}
+		// The center's city as it will be once the file is applied
+		city, ok := h.findCity(f.City)
+		if fc, inFile := fileCities[strings.ToLower(f.City)]; inFile {
+			if ok {
+				fc.Name = city.Name
+			}
+			city, ok = City{Name: fc.Name, Lat: fc.Lat, Lng: fc.Lng}, true
+		}
+		if !ok {
+			d.Problems = append(d.Problems, fmt.Sprintf("center '%s': city '%s' not found", f.Name, f.City))
+			continue
+		}
+		if km := h.calculateDistance(city, City{Lat: f.Lat, Lng: f.Lng}); km > geoCenterSlackKm && !apply {
+			d.Notes = append(d.Notes, fmt.Sprintf("center '%s' is %.0f km from %s; its point is ignored, change its city to move it", f.Name, km, city.Name))
+		}
+		center, ok := h.findCenter(f.Name)
+		if !ok {
+			change(FeatureCenter, f.Name, GeoAdded, fmt.Sprintf("in %s, %d seats", city.Name, f.TotalSeats))
+			if apply {
+				h.examCenters[city.Name] = append(h.examCenters[city.Name], ExamCenter{Name: f.Name, City: city.Name, Disabled: !f.Eligible})
+				h.centerCapacity[f.Name] = CenterCapacity{TotalSeats: f.TotalSeats, AvailableSeats: f.TotalSeats}
+			}
+			continue
+		}
+		changed := false
+		if center.City != city.Name {
+			if n := h.countRegistrations(center.Name); n > 0 {
+				d.Problems = append(d.Problems, fmt.Sprintf("center '%s' has %d registrations in %s; it cannot move to %s", center.Name, n, center.City, city.Name))
+				continue
+			}
+			change(FeatureCenter, center.Name, GeoMoved, center.City+" -> "+city.Name)
+			changed = true
+			if apply {
+				h.moveCenter(center, city.Name)
+				center.City = city.Name
+			}
+		}
+		capInfo := h.centerCapacity[center.Name]
+		if f.hasSeats && f.TotalSeats != capInfo.TotalSeats {
+			if f.TotalSeats < capInfo.BookedSeats {
+				d.Problems = append(d.Problems, fmt.Sprintf("%s already has %d booked seats; total cannot be %d", center.Name, capInfo.BookedSeats, f.TotalSeats))
+				continue
+			}
+			change(FeatureCenter, center.Name, GeoUpdated, fmt.Sprintf("seats %d -> %d", capInfo.TotalSeats, f.TotalSeats))
+			changed = true
+			if apply {
+				capInfo.TotalSeats = f.TotalSeats
+				capInfo.AvailableSeats = f.TotalSeats - capInfo.BookedSeats
+				h.centerCapacity[center.Name] = capInfo
+			}
+		}
+		if center.Disabled == f.Eligible {
+			change(FeatureCenter, center.Name, GeoUpdated, eligibility(f.Eligible))
+			changed = true
+			if apply {
+				h.updateCenter(center, func(c *ExamCenter) { c.Disabled = !f.Eligible })
+			}
+		}
+		if !changed {
+			d.Unchanged++
+		}
+	}
+	if hasCenters {
+		for _, c := range h.ListCenters("") {
+			if !fileCenters[strings.ToLower(c.Center.Name)] && !c.Center.Disabled {
+				change(FeatureCenter, c.Center.Name, GeoRemoved, fmt.Sprintf("will be disabled; %d seats booked", c.Capacity.BookedSeats))
+				if apply {
+					h.updateCenter(c.Center, func(c *ExamCenter) { c.Disabled = true })
+				}
+			}
+		}
+	}
+	sort.SliceStable(d.Changes, func(i, j int) bool { return d.Changes[i].Kind == FeatureCity && d.Changes[j].Kind != FeatureCity })
+	return d
+}
+
+// countRegistrations counts the registrations assigned to a center
+func (h *ExamCenterHandler) countRegistrations(center string) int {
+	n := 0
+	for _, r := range h.registrations {
+		if r.AssignedCenter == center {
+			n++
+		}
+	}
+	return n
+}
+
+// moveCenter moves a center into another city's list
+func (h *ExamCenterHandler) moveCenter(center ExamCenter, city string) {
+	centers := h.examCenters[center.City]
+	for i := range centers {
+		if centers[i].Name == center.Name {
+			centers = append(centers[:i], centers[i+1:]...)
+			break
+		}
+	}
+	h.examCenters[center.City] = centers
+	center.City = city
+	h.examCenters[city] = append(h.examCenters[city], center)
+} 
\ No newline at end of file
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// geoRoundTrip writes the features in a format and reads them back, the way an edited file arrives
+func geoRoundTrip(t *testing.T, features []GeoFeature, format GeoFormat) []GeoFeature {
+	t.Helper()
+	var buf bytes.Buffer
+	write := WriteGeoJSON
+	if format == FormatKML {
+		write = WriteKML
+	}
+	if err := write(&buf, features); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadGeo(&buf, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return read
+}
+
+func geoChanges(d GeoDiff) []string {
+	var list []string
+	for _, c := range d.Changes {
+		list = append(list, fmt.Sprintf("%s %s %s: %s", c.Kind, c.Name, c.Change, c.Details))
+	}
+	return list
+}
+
+func TestGeoExportReadsBackUnchanged(t *testing.T) {
+	for _, format := range []GeoFormat{FormatGeoJSON, FormatKML} {
+		h := analyticsHandler()
+		features := geoRoundTrip(t, h.GeoFeatures(), format)
+		if len(features) != 6 {
+			t.Fatalf("%s: read %d features, want 6", format, len(features))
+		}
+		d := h.DiffGeo(features)
+		if len(d.Changes) != 0 || d.Unchanged != 6 || len(d.Problems) != 0 || len(d.Notes) != 0 {
+			t.Errorf("%s: diff %+v, want 6 unchanged", format, d)
+		}
+	}
+}
+
+func TestApplyGeo(t *testing.T) {
+	h := analyticsHandler()
+	var features []GeoFeature
+	for _, f := range h.GeoFeatures() {
+		switch f.Name {
+		case "Pune":
+			f.Lat = 18.60
+		case "Pune Hall":
+			continue // left out of the file
+		case "Satara Hall":
+			f.City, f.Lat, f.Lng = "Karad", 17.29, 74.18
+		case "Surat Hall":
+			f.TotalSeats, f.Lat = 8, 21.5
+		}
+		features = append(features, f)
+	}
+	features = append(features,
+		GeoFeature{Kind: FeatureCity, Name: "Karad", State: "Maharashtra", District: "Satara", Lat: 17.29, Lng: 74.18, Eligible: true},
+		GeoFeature{Kind: FeatureCenter, Name: "Karad Hall", City: "karad", Lat: 17.29, Lng: 74.18, TotalSeats: 30, Eligible: true})
+	features = geoRoundTrip(t, features, FormatGeoJSON)
+
+	d := h.DiffGeo(features)
+	want := []string{
+		"city Pune moved: 8.9 km: 18.5200, 73.8600 -> 18.6000, 73.8600",
+		"city Karad added: Satara, Maharashtra: 17.2900, 74.1800",
+		"center Satara Hall moved: Satara -> Karad",
+		"center Surat Hall updated: seats 4 -> 8",
+		"center Karad Hall added: in Karad, 30 seats",
+		"center Pune Hall removed: will be disabled; 5 seats booked",
+	}
+	if got := geoChanges(d); !reflect.DeepEqual(got, want) {
+		t.Errorf("changes:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if d.Summary() != "2 added, 2 moved, 1 updated, 1 removed" || d.Unchanged != 2 || len(d.Problems) != 0 {
+		t.Errorf("summary %q, %d unchanged, problems %q", d.Summary(), d.Unchanged, d.Problems)
+	}
+	if len(d.Notes) != 1 || !strings.HasPrefix(d.Notes[0], "center 'Surat Hall' is 37 km from Surat") {
+		t.Errorf("notes %q, want one about the Surat Hall point", d.Notes)
+	}
+	if again := h.DiffGeo(features); again.Fingerprint() != d.Fingerprint() {
+		t.Error("fingerprint changed between two diffs of the same data")
+	}
+
+	applied, err := h.ApplyGeo("admin", "centers.geojson", features)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied.Fingerprint() != d.Fingerprint() {
+		t.Errorf("applied changes %q, want the reviewed ones", geoChanges(applied))
+	}
+	var karad []string
+	for _, c := range h.examCenters["Karad"] {
+		karad = append(karad, c.Name)
+	}
+	if !reflect.DeepEqual(karad, []string{"Satara Hall", "Karad Hall"}) || len(h.examCenters["Satara"]) != 0 {
+		t.Errorf("Karad centers %v, Satara centers %v", karad, h.examCenters["Satara"])
+	}
+	if c, _ := h.findCenter("Pune Hall"); !c.Disabled {
+		t.Error("Pune Hall left out of the file is still enabled")
+	}
+	if got, want := h.centerCapacity["Surat Hall"], (CenterCapacity{TotalSeats: 8, AvailableSeats: 4, BookedSeats: 4}); got != want {
+		t.Errorf("Surat Hall capacity %+v, want %+v", got, want)
+	}
+	if after := h.DiffGeo(features); len(after.Changes) != 0 {
+		t.Errorf("changes after applying: %q", geoChanges(after))
+	}
+}
+
+func TestApplyGeoProblems(t *testing.T) {
+	h := analyticsHandler()
+	h.registrations[0].AssignedCenter = "Pune Hall"
+	features := []GeoFeature{
+		{Kind: FeatureCity, Name: "Pune", State: "Maharashtra", Lat: 18.52, Lng: 73.86, Eligible: true},
+		{Kind: FeatureCity, Name: "pune", State: "Maharashtra", Lat: 18.52, Lng: 73.86, Eligible: true},
+		{Kind: FeatureCity, Name: "Karad", Lat: 17.29, Lng: 74.18, Eligible: true},
+		{Kind: FeatureCenter, Name: "Pune Hall", City: "Surat", TotalSeats: 10, Eligible: true, hasSeats: true},
+		{Kind: FeatureCenter, Name: "Surat Hall", City: "Surat", TotalSeats: 3, Eligible: true, hasSeats: true},
+		{Kind: FeatureCenter, Name: "Wai Hall", City: "Wai", Eligible: true},
+		{Kind: FeatureCenter, Name: "Wai Hall", City: "Wai", Eligible: true},
+	}
+	want := []string{
+		"city 'pune' appears more than once",
+		"center 'Wai Hall' appears more than once",
+		"new city 'Karad' has no state",
+		"center 'Pune Hall' has 1 registrations in Pune; it cannot move to Surat",
+		"Surat Hall already has 4 booked seats; total cannot be 3",
+		"center 'Wai Hall': city 'Wai' not found",
+		"center 'Wai Hall': city 'Wai' not found",
+	}
+	d, err := h.ApplyGeo("admin", "centers.geojson", features)
+	if err == nil {
+		t.Error("file with problems applied, want an error")
+	}
+	if !reflect.DeepEqual(d.Problems, want) {
+		t.Errorf("problems:\n%s\nwant:\n%s", strings.Join(d.Problems, "\n"), strings.Join(want, "\n"))
+	}
+	if c, _ := h.findCenter("Pune Hall"); c.City != "Pune" || h.centerCapacity["Surat Hall"].TotalSeats != 4 {
+		t.Error("a file with problems changed the data")
+	}
+}
+
+func TestReadGeoRejectsBadFeatures(t *testing.T) {
+	feature := func(props string) string {
+		return `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[73.86,18.52]},"properties":{` + props + `}}]}`
+	}
+	tests := []struct {
+		name, file, err string
+	}{
+		{"not a collection", `{"type":"Feature"}`, "GeoJSON must be a FeatureCollection, not 'Feature'"},
+		{"no geometry", `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":null,"properties":{"name":"Pune"}}]}`,
+			"feature 1: geometry must be a Point"},
+		{"unknown kind", feature(`"kind":"district","name":"Pune"`), "feature 1: kind must be city or center, not 'district'"},
+		{"no name", feature(`"kind":"city","state":"Maharashtra"`), "feature 1: name cannot be empty"},
+		{"center without a city", feature(`"kind":"center","name":"Pune Hall"`), "feature 1: center 'Pune Hall' has no city"},
+		{"unknown state", feature(`"name":"Pune","state":"Deccan"`), "feature 1: city 'Pune': 'Deccan' is not a state or union territory"},
+		{"part of a seat", feature(`"name":"Pune Hall","city":"Pune","total_seats":12.5`), "feature 1: total_seats of 'Pune Hall' must be a whole number, not '12.5'"},
+		{"eligible is not a flag", feature(`"name":"Pune","state":"Maharashtra","eligible":"sometimes"`), "feature 1: eligible of 'Pune' must be true or false, not 'sometimes'"},
+	}
+	for _, tt := range tests {
+		_, err := ReadGeo(strings.NewReader(tt.file), FormatGeoJSON)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: error %v, want %q", tt.name, err, tt.err)
+		}
+	}
+
+	kml := `<kml xmlns="http://www.opengis.net/kml/2.2"><Document><Folder><Placemark><name>Pune</name><Point><coordinates>73.86,118.52</coordinates></Point></Placemark></Folder></Document></kml>`
+	if _, err := ReadGeo(strings.NewReader(kml), FormatKML); err == nil || !strings.HasPrefix(err.Error(), "placemark 1: coordinates") {
+		t.Errorf("KML with a latitude of 118: error %v", err)
+	}
+}
+
+func TestParseGeoFormat(t *testing.T) {
+	tests := []struct {
+		in   string
+		want GeoFormat
+	}{
+		{"geojson", FormatGeoJSON},
+		{"centers.json", FormatGeoJSON},
+		{"Centers.KML", FormatKML},
+		{"centers.shp", ""},
+	}
+	for _, tt := range tests {
+		got, err := ParseGeoFormat(tt.in)
+		if got != tt.want || (err != nil) != (tt.want == "") {
+			t.Errorf("ParseGeoFormat(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+} 
\ No newline at end of file
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
 	"fmt"
 	"math"
 	"os"
@@ -17,6 +19,26 @@ type ExamCenterHandler struct {
 	examCenters    map[string][]ExamCenter
 	centerCapacity map[string]CenterCapacity
 	registrations  []ExamRegistration
+	attendance     map[string]AttendanceRecord // by registration ID
+	users          map[string]User // by lower-case user name
+	loginCodes     map[string]loginChallenge
+	waitlist       []WaitlistEntry
+	importQueue    []QueuedCandidate // imported rows waiting for RunBatchAllocation
+	deliveries     []Delivery
+	webhooks       []WebhookSubscription
+	webhookLog     []WebhookDelivery
+	clusters       []MetroCluster              // sorted by name
+	exams          []ExamType                  // every edition, by code then edition
+	policies       map[string]AllocationPolicy // by exam code; exams without one use DefaultPolicy
+	closures       []CenterClosure             // centers closed for a date or slot
+	idStamp        string                      // latest second used in a registration ID; never goes back
+	idsIssued      map[string]int              // IDs issued in idStamp, by ID without suffix, so none is reused
+	notifier       Notifier
+	senders        map[string]Notifier // per-channel overrides of notifier
+	subscribers    []func(Event)
+	signingKey     ed25519.PrivateKey
+	dataDir        string // where state is persisted; empty keeps everything in memory
+	stateInfo      os.FileInfo // the state file as last read or written, to notice saves by other processes
 }
 
 // StudentInfo holds user-provided student data for a run
@@ -24,6 +46,8 @@ type StudentInfo struct {
 	Name       string
 	ExamType   string
 	RollNumber string
+	Phone      string
+	Email      string
 }
 
 // CityDistance ties a city, distance and its centers
@@ -40,11 +64,19 @@ func NewExamCenterHandler() *ExamCenterHandler {
 		examCenters:    make(map[string][]ExamCenter),
 		centerCapacity: make(map[string]CenterCapacity),
 		registrations:  make([]ExamRegistration, 0),
+		attendance:     make(map[string]AttendanceRecord),
+		users:          make(map[string]User),
+		loginCodes:     make(map[string]loginChallenge),
+		senders:        make(map[string]Notifier),
+		clusters:       seedClusters(),
+		exams:          seedExamTypes(),
+		policies:       make(map[string]AllocationPolicy),
 	}
 
 	h.initializeCities()
 	h.initializeExamCenters()
 	h.initializeCenterCapacity()
+	_, h.signingKey, _ = ed25519.GenerateKey(rand.Reader)
 	return h
 }
 
@@ -103,6 +135,9 @@ func (h *ExamCenterHandler) initializeCities() {
 		"Aligarh":    {Name: "Aligarh", Lat: 27.8974, Lng: 78.0880},
 		"Jalandhar":  {Name: "Jalandhar", Lat: 31.3260, Lng: 75.5762},
 	}
+	for name, c := range h.cities {
+		h.cities[name] = seedRegion(c)
+	}
 }
 
 // initializeExamCenters populates the exam centers map
@@ -169,6 +204,10 @@ func (h *ExamCenterHandler) initializeExamCenters() {
 			}
 		}
 	}
+	// The first center of each city has accessible halls and scribe seating
+	for _, centers := range h.examCenters {
+		centers[0].Accessible, centers[0].Scribes, centers[0].PrioritySeats = true, true, 10
+	}
 }
 
 // initializeCenterCapacity initializes capacity for all centers
@@ -241,11 +280,15 @@ func (h *ExamCenterHandler) FindNearestCities(homeCity string, count int) ([]Cit
 	}
 	var distances []CityDistance
 	for cityName, cityData := range h.cities {
-		if strings.EqualFold(cityName, homeCity) {
+		if strings.EqualFold(cityName, homeCity) || cityData.Disabled {
+			continue
+		}
+		centers := h.activeCenters(cityName)
+		if len(centers) == 0 {
 			continue
 		}
 		distance := h.calculateDistance(homeCityData, cityData)
-		cityDistance := CityDistance{City: cityData, Distance: distance, Centers: h.examCenters[cityName]}
+		cityDistance := CityDistance{City: cityData, Distance: distance, Centers: centers}
 		distances = append(distances, cityDistance)
 	}
 	sort.Slice(distances, func(i, j int) bool { return distances[i].Distance < distances[j].Distance })
@@ -255,48 +298,35 @@ func (h *ExamCenterHandler) FindNearestCities(homeCity string, count int) ([]Cit
 	return distances, nil
 }
 
-// Advanced: find nearest applying preferences and capacity
+// Advanced: find nearest applying preferences, capacity and the exam's allocation policy (see policy.go)
 func (h *ExamCenterHandler) FindNearestCitiesAdvanced(homeCity string, examType ExamType, preferences StudentPreference) ([]CityDistance, error) {
-	homeCityData, exists := h.cities[homeCity]
-	if !exists {
-		return nil, fmt.Errorf("home city '%s' not found", homeCity)
-	}
-	var distances []CityDistance
-	for cityName, cityData := range h.cities {
-		if strings.EqualFold(cityName, homeCity) {
-			continue
-		}
-		distance := h.calculateDistance(homeCityData, cityData)
-		if preferences.MaxDistance > 0 && distance > preferences.MaxDistance {
-			continue
-		}
-		available := h.getAvailableCenters(cityName)
-		if len(available) == 0 {
-			continue
+	policy, _ := h.AllocationPolicyFor(examType.Code)
+	res, err := h.allocate(policy, homeCity, examType, preferences)
+	return res.Nearest, err
+}
+
+// activeCenters returns the centers of a city that have not been disabled
+func (h *ExamCenterHandler) activeCenters(cityName string) []ExamCenter {
+	var active []ExamCenter
+	for _, c := range h.examCenters[cityName] {
+		if !c.Disabled {
+			active = append(active, c)
 		}
-		distances = append(distances, CityDistance{City: cityData, Distance: distance, Centers: available})
 	}
-	sort.Slice(distances, func(i, j int) bool { return distances[i].Distance < distances[j].Distance })
-	if max := examType.MaxCenters; max > 0 && len(distances) > max {
-		distances = distances[:max]
-	}
-	return distances, nil
+	return active
 }
 
-// getAvailableCenters returns centers with available seats
-func (h *ExamCenterHandler) getAvailableCenters(cityName string) []ExamCenter {
-	centers := h.examCenters[cityName]
-	var available []ExamCenter
-	for _, c := range centers {
-		if capInfo, ok := h.centerCapacity[c.Name]; ok {
-			if capInfo.AvailableSeats > 0 {
-				available = append(available, c)
+// findCenter looks up an exam center by name (case-insensitive)
+func (h *ExamCenterHandler) findCenter(name string) (ExamCenter, bool) {
+	name = strings.TrimSpace(name)
+	for _, centers := range h.examCenters {
+		for _, c := range centers {
+			if strings.EqualFold(c.Name, name) {
+				return c, true
 			}
-		} else {
-			available = append(available, c)
 		}
 	}
-	return available
+	return ExamCenter{}, false
 }
 
 // calculateDistance calculates the distance between two cities using Haversine formula
@@ -383,27 +413,50 @@ func (h *ExamCenterHandler) DisplayResults(student StudentInfo, homeCity string,
 func (h *ExamCenterHandler) DisplayExamTypes() {
 	fmt.Println("Available Exam Types:")
 	fmt.Println("=====================")
-	for code, exam := range PredefinedExamTypes {
-		fmt.Printf("%s - %s\n", code, exam.Name)
-		fmt.Printf("   Duration: %s | Max Centers: %d\n", exam.Duration.String(), exam.MaxCenters)
+	for _, exam := range h.OpenExamEditions() {
+		fmt.Printf("%s - %s\n", exam.Label(), exam.Name)
+		fmt.Printf("   Duration: %s | Max Centers: %d | Allocation: %s | Home city: %s\n", exam.Duration.String(), exam.MaxCenters, exam.Allocation, exam.HomeCity)
+		fmt.Printf("   Dates: %s to %s | Slots: %s\n", exam.Schedule.StartDate, exam.Schedule.EndDate, strings.Join(exam.Schedule.TimeSlots, ", "))
+		if exam.WomenRadius > 0 {
+			fmt.Printf("   Women are placed within %.0f km where a center has room\n", exam.WomenRadius)
+		}
 		fmt.Printf("   %s\n\n", exam.Description)
 	}
 }
 
+// GetExamTypeDetails returns the edition to register for: "JEE 2026" names one, a bare code gives the current edition
 func (h *ExamCenterHandler) GetExamTypeDetails(examCode string) (ExamType, error) {
-	ex, ok := PredefinedExamTypes[strings.ToUpper(examCode)]
+	code, edition, err := ParseExamRef(examCode)
+	if err != nil { return ExamType{}, err }
+	ex, ok := h.currentEdition(code)
 	if !ok { return ExamType{}, fmt.Errorf("exam type '%s' not found", examCode) }
+	if edition != 0 {
+		if ex, err = h.ExamEdition(code, edition); err != nil { return ExamType{}, err }
+	}
+	if ex.Closed { return ExamType{}, fmt.Errorf("registration for %s is closed", ex.Label()) }
 	return ex, nil
 }
 
+// GetExamTypes returns the current edition of each exam type, sorted by code
+func (h *ExamCenterHandler) GetExamTypes() []ExamType {
+	var exams []ExamType
+	for _, ex := range h.exams {
+		if len(exams) == 0 || exams[len(exams)-1].Code != ex.Code {
+			cur, _ := h.currentEdition(ex.Code)
+			exams = append(exams, cur)
+		}
+	}
+	return exams
+}
+
 func (h *ExamCenterHandler) ProcessAdvancedExamAssignment() error {
 	fmt.Println("=== Advanced Exam Center Assignment ===")
 	h.DisplayExamTypes()
-	examInput, err := h.GetUserInput("Enter exam type (e.g., JEE, NEET, UPSC): ")
+	examInput, err := h.GetUserInput("Enter exam type (e.g., JEE, NEET, UPSC, or JEE 2026 for a later edition): ")
 	if err != nil { return fmt.Errorf("error reading exam type: %v", err) }
 	exType, err := h.GetExamTypeDetails(examInput)
 	if err != nil { return err }
-	fmt.Printf("\nSelected: %s - %s\n", exType.Code, exType.Name)
+	fmt.Printf("\nSelected: %s - %s\n", exType.Label(), exType.Name)
 	fmt.Printf("Duration: %s\n\n", exType.Duration.String())
 	h.DisplayCityList()
 	cityInput, err := h.GetUserInput("Enter your home city (name or number): ")
@@ -416,12 +469,25 @@ func (h *ExamCenterHandler) ProcessAdvancedExamAssignment() error {
 	if err != nil { return fmt.Errorf("error reading roll number: %v", err) }
 	student, err := h.ValidateStudentInfo(name, exType.Code, roll)
 	if err != nil { return err }
+	phone, err := h.GetUserInput("Mobile number for updates (optional): ")
+	if err != nil { return fmt.Errorf("error reading mobile number: %v", err) }
+	email, err := h.GetUserInput("Email for updates (optional): ")
+	if err != nil { return fmt.Errorf("error reading email: %v", err) }
+	if student.Phone, student.Email, err = ValidateContacts(phone, email); err != nil { return err }
 	prefs, err := h.GetStudentPreferences()
 	if err != nil { return err }
 	nearest, err := h.FindNearestCitiesAdvanced(homeCity, exType, prefs)
 	if err != nil { return err }
-	if len(nearest) == 0 { return fmt.Errorf("no suitable exam centers found within your preferences") }
-	reg := h.CreateRegistration(student, exType, nearest[0], homeCity)
+	if len(nearest) == 0 {
+		join, err := h.GetUserInput("No exam centers have free seats within your preferences. Join the waitlist? (y/n): ")
+		if err != nil { return fmt.Errorf("error reading answer: %v", err) }
+		if strings.ToLower(join) != "y" && strings.ToLower(join) != "yes" {
+			return fmt.Errorf("no suitable exam centers found within your preferences")
+		}
+		h.DisplayWaitlistEntry(h.JoinWaitlist(student, exType, homeCity, prefs))
+		return nil
+	}
+	reg := h.CreateRegistration(student, exType, nearest[0], homeCity, prefs)
 	h.DisplayAdvancedResults(reg, nearest, prefs)
 	return nil
 }
@@ -443,22 +509,45 @@ func (h *ExamCenterHandler) GetStudentPreferences() (StudentPreference, error) {
 	acc, err := h.GetUserInput("Need accommodation? (y/n) [default: n]: ")
 	if err != nil { return p, err }
 	p.AccommodationNeeded = strings.ToLower(acc) == "y" || strings.ToLower(acc) == "yes"
-	return p, nil
+	cats, err := h.GetUserInput("Priority category: pwd, woman, special_needs, comma separated (optional): ")
+	if err != nil { return p, err }
+	scribe := false
+	if cats != "" {
+		ans, err := h.GetUserInput("Need a scribe? PwD candidates only (y/n) [default: n]: ")
+		if err != nil { return p, err }
+		scribe = strings.ToLower(ans) == "y" || strings.ToLower(ans) == "yes"
+	}
+	return p.WithCategories(cats, scribe)
 }
 
 // Registration helpers
-func (h *ExamCenterHandler) CreateRegistration(student StudentInfo, examType ExamType, assigned CityDistance, homeCity string) ExamRegistration {
+func (h *ExamCenterHandler) CreateRegistration(student StudentInfo, examType ExamType, assigned CityDistance, homeCity string, prefs StudentPreference) ExamRegistration {
+	reg := h.createRegistration(student, examType, assigned, homeCity, prefs)
+	h.emit(Event{Kind: EventRegistrationCreated, Registration: reg})
+	return reg
+}
+
+// createRegistration books the first center of assigned without publishing an event
+func (h *ExamCenterHandler) createRegistration(student StudentInfo, examType ExamType, assigned CityDistance, homeCity string, prefs StudentPreference) ExamRegistration {
 	reg := ExamRegistration{
-		ID:               h.generateRegistrationID(examType.Code, student.RollNumber),
+		ID:               h.generateRegistrationID(examType, student.RollNumber),
 		StudentName:      student.Name,
+		RollNumber:       student.RollNumber,
 		StudentCity:      homeCity,
+		Phone:            student.Phone,
+		Email:            student.Email,
 		ExamType:         examType,
 		AssignedCenter:   assigned.Centers[0].Name,
 		AssignedCity:     assigned.City.Name,
 		Distance:         assigned.Distance,
 		RegistrationTime: time.Now(),
-		Preferences:      StudentPreference{MaxDistance: 1000, PreferredTransport: "any", AccommodationNeeded: false},
+		Preferences:      prefs,
 	}
+	reg.ExamDate, reg.TimeSlot = firstSitting(examType)
+	reg.CandidateID = h.candidateFor(student)
+	reg.ExamDate, reg.TimeSlot, _ = h.chooseSlot(reg)
+	// Explained at the sitting the candidate ends up in, as closures there decide what was offered
+	reg.Explanation = h.explainAssignment(homeCity, examType, prefs, reg.AssignedCenter, reg.ExamDate, reg.TimeSlot)
 	h.registrations = append(h.registrations, reg)
 	if capInfo, ok := h.centerCapacity[reg.AssignedCenter]; ok {
 		capInfo.AvailableSeats--
@@ -468,8 +557,61 @@ func (h *ExamCenterHandler) CreateRegistration(student StudentInfo, examType Exa
 	return reg
 }
 
-func (h *ExamCenterHandler) generateRegistrationID(examCode, roll string) string {
-	return fmt.Sprintf("%s-%s-%s", examCode, roll, time.Now().Format("20060102150405"))
+// GetRegistration returns the registration with the given ID
+func (h *ExamCenterHandler) GetRegistration(id string) (ExamRegistration, error) {
+	id = strings.TrimSpace(id)
+	for _, reg := range h.registrations {
+		if reg.ID == id {
+			return reg, nil
+		}
+	}
+	return ExamRegistration{}, fmt.Errorf("registration '%s' not found", id)
+}
+
+// generateRegistrationID names a registration after its exam edition, roll number and time, e.g.
+// NEET-2025-R123-20250110093000. An ID already in use gets a counter: -2, -3 and so on.
+func (h *ExamCenterHandler) generateRegistrationID(examType ExamType, roll string) string {
+	exam := examType.Code
+	if examType.Edition != 0 {
+		exam = fmt.Sprintf("%s-%d", examType.Code, examType.Edition)
+	}
+	// IDs are counted per second, so one given to a registration that was cancelled since, and that
+	// may still be in the delivery, webhook or audit logs, is not issued again. The count is saved
+	// with the state and a clock set back keeps counting in the latest second.
+	stamp := time.Now().Format("20060102150405")
+	if stamp < h.idStamp {
+		stamp = h.idStamp
+	}
+	if stamp != h.idStamp || h.idsIssued == nil {
+		h.idStamp, h.idsIssued = stamp, make(map[string]int)
+	}
+	base := fmt.Sprintf("%s-%s-%s", exam, roll, stamp)
+	n := h.idsIssued[base] + 1
+	id := base
+	if n > 1 {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	for h.registrationIDTaken(id) {
+		n++
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	h.idsIssued[base] = n
+	return id
+}
+
+// registrationIDTaken reports whether a registration or waitlist entry already uses an ID
+func (h *ExamCenterHandler) registrationIDTaken(id string) bool {
+	for _, reg := range h.registrations {
+		if reg.ID == id {
+			return true
+		}
+	}
+	for _, e := range h.waitlist {
+		if e.ID == "WL-"+id {
+			return true
+		}
+	}
+	return false
 }
 
 func (h *ExamCenterHandler) DisplayAdvancedResults(reg ExamRegistration, nearest []CityDistance, prefs StudentPreference) {
@@ -478,7 +620,7 @@ func (h *ExamCenterHandler) DisplayAdvancedResults(reg ExamRegistration, nearest
 	fmt.Println(strings.Repeat("=", 70))
 	fmt.Printf("Registration ID: %s\n", reg.ID)
 	fmt.Printf("Student Name: %s\n", reg.StudentName)
-	fmt.Printf("Exam: %s - %s\n", reg.ExamType.Code, reg.ExamType.Name)
+	fmt.Printf("Exam: %s - %s\n", reg.ExamType.Label(), reg.ExamType.Name)
 	fmt.Printf("Duration: %s\n", reg.ExamType.Duration.String())
 	fmt.Printf("Registered: %s\n", reg.RegistrationTime.Format("2006-01-02 15:04:05"))
 	fmt.Println("\n" + strings.Repeat("-", 70))
@@ -486,9 +628,18 @@ func (h *ExamCenterHandler) DisplayAdvancedResults(reg ExamRegistration, nearest
 	fmt.Printf("🏢 Center: %s\n", reg.AssignedCenter)
 	fmt.Printf("🏙️  City: %s\n", reg.AssignedCity)
 	fmt.Printf("📏 Distance: %.1f km from your home city\n", reg.Distance)
+	fmt.Printf("📅 Date & Slot: %s, %s (report by %s)\n", reg.ExamDate, reg.TimeSlot, ReportingTime(reg.TimeSlot))
 	if capInfo, ok := h.centerCapacity[reg.AssignedCenter]; ok {
 		fmt.Printf("💺 Capacity: %d total, %d available, %d booked\n", capInfo.TotalSeats, capInfo.AvailableSeats, capInfo.BookedSeats)
 	}
+	for _, c := range h.RegistrationClashes(reg.ID) {
+		fmt.Printf("⚠️  Clash with your other exam: %s\n", c.Detail)
+	}
+	if reg.Explanation != nil {
+		fmt.Println("\n" + strings.Repeat("-", 70))
+		fmt.Println("WHY THIS CENTER:")
+		DisplayExplanation(*reg.Explanation)
+	}
 	fmt.Println("\n" + strings.Repeat("-", 70))
 	fmt.Println("ALTERNATIVE OPTIONS:")
 	for i, cd := range nearest {
@@ -511,6 +662,12 @@ func (h *ExamCenterHandler) DisplayAdvancedResults(reg ExamRegistration, nearest
 	if prefs.AccommodationNeeded {
 		fmt.Println("• Accommodation: Required")
 	}
+	if prefs.Priority() {
+		fmt.Printf("• Priority category: %s\n", prefs.CategoryList())
+	}
+	if prefs.NeedsScribe {
+		fmt.Println("• Scribe: Allowed at this center")
+	}
 	fmt.Println("\n📋 IMPORTANT INSTRUCTIONS:")
 	fmt.Println("• Save your Registration ID for future reference")
 	fmt.Println("• Carry this assignment along with your admit card")
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateRegistrationIDIsUnique(t *testing.T) {
+	h := NewExamCenterHandler()
+	neet2025 := ExamType{Code: "NEET", Edition: 2025}
+	neet2026 := ExamType{Code: "NEET", Edition: 2026}
+	seen := make(map[string]bool)
+	for _, tt := range []struct {
+		exam   ExamType
+		prefix string
+	}{
+		{neet2025, "NEET-2025-R1-"},
+		{neet2026, "NEET-2026-R1-"},
+		{neet2025, "NEET-2025-R1-"},
+		{neet2025, "NEET-2025-R1-"},
+		{ExamType{Code: "OLD"}, "OLD-R1-"},
+	} {
+		id := h.generateRegistrationID(tt.exam, "R1")
+		if !strings.HasPrefix(id, tt.prefix) {
+			t.Errorf("ID %s, want it to start with %s", id, tt.prefix)
+		}
+		if seen[id] {
+			t.Errorf("ID %s handed out twice", id)
+		}
+		seen[id] = true
+		h.registrations = append(h.registrations, ExamRegistration{ID: id})
+	}
+	wait := h.generateRegistrationID(neet2026, "R2")
+	h.waitlist = append(h.waitlist, WaitlistEntry{ID: "WL-" + wait})
+	if id := h.generateRegistrationID(neet2026, "R2"); id == wait {
+		t.Errorf("ID %s is already used by a waitlist entry", id)
+	}
+}
+func TestRegistrationIDIsNotReusedAfterCancellation(t *testing.T) {
+	dir := t.TempDir()
+	h, err := OpenExamCenterHandler(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	neet := ExamType{Code: "NEET"}
+	// Pin the second so every ID below shares it, as they would when issued back to back
+	h.idStamp = "29990101090000"
+	first := h.generateRegistrationID(neet, "R1")
+	// The registration was cancelled, so only the logs still mention its ID
+	if id := h.generateRegistrationID(neet, "R1"); id == first {
+		t.Fatalf("ID %s issued again after its registration was cancelled", id)
+	}
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := OpenExamCenterHandler(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := reopened.generateRegistrationID(neet, "R1"); id != first+"-3" {
+		t.Errorf("after a restart got %s, want %s-3", id, first)
+	}
+	if !strings.Contains(first, "-29990101090000") {
+		t.Errorf("ID %s went back in time with the clock, want the latest second kept", first)
+	}
+} 
\ No newline at end of file
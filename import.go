@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportMode says what happens to valid rows of a candidate import
+type ImportMode string
+
+const (
+	ImportCheck    ImportMode = "check"    // validate only, change nothing
+	ImportAllocate ImportMode = "allocate" // register each valid row straight away
+	ImportQueue    ImportMode = "queue"    // hold valid rows for RunBatchAllocation
+)
+
+// ParseImportMode reads an import mode name
+func ParseImportMode(s string) (ImportMode, error) {
+	switch m := ImportMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case ImportCheck, ImportAllocate, ImportQueue:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown import mode '%s' (use check, allocate or queue)", s)
+}
+
+// DefaultImportDistance is the search radius for rows without max_distance, as on the web form
+const DefaultImportDistance = 1000
+
+// ImportColumns are the recognised CSV header names; the first four are required
+var ImportColumns = []string{"name", "roll_number", "exam", "home_city", "phone", "email", "max_distance", "category", "scribe"}
+
+// QueuedCandidate is a validated import row waiting for the batch allocator
+type QueuedCandidate struct {
+	Student     StudentInfo
+	ExamType    ExamType
+	HomeCity    string
+	Preferences StudentPreference
+	Source      string // file the row came from
+	Row         int
+	QueuedAt    time.Time
+}
+
+// ImportResult summarises a candidate import
+type ImportResult struct {
+	Rows       int // data rows read, excluding the header
+	Valid      int
+	Registered int
+	Waitlisted int
+	Queued     int
+	Errors     []RowError
+}
+
+// BatchResult summarises a run of the batch allocator
+type BatchResult struct {
+	Registered int
+	Waitlisted int
+}
+
+// ImportCandidatesCSV validates candidate rows from a spreadsheet export and registers or queues them.
+// The header row names the columns (see ImportColumns) in any order. Rows are checked with
+// ValidateStudentInfo and ValidateCity; valid rows are applied even when other rows fail.
+// allowed limits which exams the importing user may add candidates for; nil allows all.
+func (h *ExamCenterHandler) ImportCandidatesCSV(actor string, r io.Reader, source string, mode ImportMode, allowed func(ExamType) bool) (ImportResult, error) {
+	var res ImportResult
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return res, fmt.Errorf("the file is empty")
+	}
+	if err != nil {
+		return res, fmt.Errorf("error reading header: %v", err)
+	}
+	col := make(map[string]int)
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range ImportColumns[:4] {
+		if _, ok := col[name]; !ok {
+			return res, fmt.Errorf("missing column '%s' (the header needs %s)", name, strings.Join(ImportColumns[:4], ", "))
+		}
+	}
+
+	seen := make(map[string]int) // exam edition + roll number -> first row in this file
+	for row := 2; ; row++ {
+		rec, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			res.Errors = append(res.Errors, RowError{Row: row, Message: err.Error()})
+			break
+		}
+		field := func(name string) string {
+			if i, ok := col[name]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		if strings.Join(rec, "") == "" {
+			continue
+		}
+		res.Rows++
+		q, err := h.importRow(field, allowed)
+		if err == nil {
+			key := q.ExamType.Label() + "/" + q.Student.RollNumber
+			if first, dup := seen[key]; dup {
+				err = fmt.Errorf("roll number %s for %s already appears on row %d", q.Student.RollNumber, q.ExamType.Label(), first)
+			} else {
+				seen[key] = row
+			}
+		}
+		if err != nil {
+			res.Errors = append(res.Errors, RowError{Row: row, Message: err.Error()})
+			continue
+		}
+		res.Valid++
+		q.Source, q.Row = source, row
+		switch mode {
+		case ImportAllocate:
+			if h.allocateQueued(q) {
+				res.Registered++
+			} else {
+				res.Waitlisted++
+			}
+		case ImportQueue:
+			q.QueuedAt = time.Now()
+			h.importQueue = append(h.importQueue, q)
+			res.Queued++
+		}
+	}
+	if mode == ImportCheck {
+		return res, nil
+	}
+	details := fmt.Sprintf("%d rows: %d registered, %d waitlisted, %d queued, %d errors", res.Rows, res.Registered, res.Waitlisted, res.Queued, len(res.Errors))
+	return res, h.commit(actor, "import.candidates", source, details)
+}
+
+// importRow validates one row
+func (h *ExamCenterHandler) importRow(field func(string) string, allowed func(ExamType) bool) (QueuedCandidate, error) {
+	var q QueuedCandidate
+	student, err := h.ValidateStudentInfo(field("name"), field("exam"), field("roll_number"))
+	if err != nil {
+		return q, err
+	}
+	exam, err := h.GetExamTypeDetails(student.ExamType)
+	if err != nil {
+		return q, err
+	}
+	if allowed != nil && !allowed(exam) {
+		return q, fmt.Errorf("exam %s is outside your scope", exam.Code)
+	}
+	student.ExamType = exam.Code
+	homeCity, err := h.ValidateCity(field("home_city"))
+	if err != nil {
+		return q, err
+	}
+	if student.Phone, student.Email, err = ValidateContacts(field("phone"), field("email")); err != nil {
+		return q, err
+	}
+	prefs := StudentPreference{MaxDistance: DefaultImportDistance, PreferredTransport: "any"}
+	if text := field("max_distance"); text != "" {
+		if prefs.MaxDistance, err = strconv.ParseFloat(text, 64); err != nil || prefs.MaxDistance <= 0 {
+			return q, fmt.Errorf("max_distance '%s' is not a positive number", text)
+		}
+	}
+	scribe, err := parseYesNo(field("scribe"))
+	if err != nil {
+		return q, fmt.Errorf("scribe %v", err)
+	}
+	if prefs, err = prefs.WithCategories(field("category"), scribe); err != nil {
+		return q, err
+	}
+	if h.isRegistered(exam, student.RollNumber) {
+		return q, fmt.Errorf("roll number %s is already registered or waiting for %s", student.RollNumber, exam.Label())
+	}
+	return QueuedCandidate{Student: student, ExamType: exam, HomeCity: homeCity, Preferences: prefs}, nil
+}
+
+// parseYesNo reads a yes/no column; empty means no
+func parseYesNo(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "", "n", "no", "false", "0":
+		return false, nil
+	case "y", "yes", "true", "1":
+		return true, nil
+	}
+	return false, fmt.Errorf("'%s' is not yes or no", s)
+}
+
+// isRegistered reports whether a roll number already has a registration, waitlist place or queued import for an exam edition
+func (h *ExamCenterHandler) isRegistered(exam ExamType, roll string) bool {
+	for _, reg := range h.registrations {
+		if reg.ExamType.SameEdition(exam) && reg.RollNumber == roll {
+			return true
+		}
+	}
+	for _, e := range h.waitlist {
+		if e.ExamType.SameEdition(exam) && e.Student.RollNumber == roll {
+			return true
+		}
+	}
+	for _, q := range h.importQueue {
+		if q.ExamType.SameEdition(exam) && q.Student.RollNumber == roll {
+			return true
+		}
+	}
+	return false
+}
+
+// allocateQueued registers a candidate at the nearest center with room, or waitlists them.
+// It reports whether a seat was assigned.
+func (h *ExamCenterHandler) allocateQueued(q QueuedCandidate) bool {
+	nearest, err := h.FindNearestCitiesAdvanced(q.HomeCity, q.ExamType, q.Preferences)
+	if err != nil || len(nearest) == 0 {
+		h.JoinWaitlist(q.Student, q.ExamType, q.HomeCity, q.Preferences)
+		return false
+	}
+	h.CreateRegistration(q.Student, q.ExamType, nearest[0], q.HomeCity, q.Preferences)
+	return true
+}
+
+// QueuedCandidates returns the candidates waiting for the batch allocator, oldest first
+func (h *ExamCenterHandler) QueuedCandidates() []QueuedCandidate {
+	return append([]QueuedCandidate(nil), h.importQueue...)
+}
+
+// RunBatchAllocation allocates queued candidates in the order they were imported, priority candidates
+// (see categories.go) before the rest so they get the accessible and nearby seats. Candidates with no
+// free seat in range join the waitlist. allowed limits the run to some exams; nil runs all.
+func (h *ExamCenterHandler) RunBatchAllocation(actor string, allowed func(ExamType) bool) (BatchResult, error) {
+	var res BatchResult
+	var run []QueuedCandidate
+	remaining := h.importQueue[:0]
+	for _, q := range h.importQueue {
+		if allowed != nil && !allowed(q.ExamType) {
+			remaining = append(remaining, q)
+		} else {
+			run = append(run, q)
+		}
+	}
+	sort.SliceStable(run, func(i, j int) bool {
+		return run[i].Preferences.Priority() && !run[j].Preferences.Priority()
+	})
+	for _, q := range run {
+		if h.allocateQueued(q) {
+			res.Registered++
+		} else {
+			res.Waitlisted++
+		}
+	}
+	h.importQueue = remaining
+	if res.Registered+res.Waitlisted == 0 {
+		return res, nil
+	}
+	return res, h.commit(actor, "import.allocate", "", fmt.Sprintf("%d registered, %d waitlisted", res.Registered, res.Waitlisted))
+} 
\ No newline at end of file
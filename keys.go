@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DefaultDataDir is where the CLI and web UI keep server state
+const DefaultDataDir = "data"
+
+// SigningKeyFile is the admit card signing key inside the data directory
+const SigningKeyFile = "admitcard_ed25519.pem"
+
+// PublicKeyFile is the matching public key handed out to exam centers
+const PublicKeyFile = "admitcard_ed25519.pub"
+
+// LoadOrCreateSigningKey reads the Ed25519 signing key from dir, generating and saving one on first use
+func LoadOrCreateSigningKey(dir string) (ed25519.PrivateKey, error) {
+	path := filepath.Join(dir, SigningKeyFile)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil || block.Type != "PRIVATE KEY" {
+			return nil, fmt.Errorf("%s is not a PEM private key", path)
+		}
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %v", path, err)
+		}
+		priv, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s is not an Ed25519 key", path)
+		}
+		return priv, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading signing key: %v", err)
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("error generating signing key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating data directory: %v", err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return nil, fmt.Errorf("error writing signing key: %v", err)
+	}
+	pubPEM, err := EncodePublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, PublicKeyFile), pubPEM, 0o644); err != nil {
+		return nil, fmt.Errorf("error writing public key: %v", err)
+	}
+	return priv, nil
+}
+
+// EncodePublicKey returns the PEM (PKIX) encoding of an Ed25519 public key
+func EncodePublicKey(pub ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding public key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// LoadPublicKey reads a PEM encoded Ed25519 public key
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading public key: %v", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("%s is not a PEM public key", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an Ed25519 key", path)
+	}
+	return pub, nil
+}
+
+// SetSigningKey replaces the key used to sign admit cards
+func (h *ExamCenterHandler) SetSigningKey(key ed25519.PrivateKey) { h.signingKey = key }
+
+// PublicKey returns the public half of the admit card signing key
+func (h *ExamCenterHandler) PublicKey() ed25519.PublicKey {
+	return h.signingKey.Public().(ed25519.PublicKey)
+} 
\ No newline at end of file
@@ -1,13 +1,20 @@
 package main
 
 import (
+	"crypto/ed25519"
 	"embed"
+	"errors"
+	"flag"
 	"fmt"
 	"html/template"
 	"io/fs"
 	"log"
 	"net/http"
+	"os"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	handlerpkg "exam-center-assignment/internal/handler"
 )
@@ -16,17 +23,43 @@ import (
 var content embed.FS
 
 type Server struct {
-	h *handlerpkg.ExamCenterHandler
-	t *template.Template
+	h         *handlerpkg.ExamCenterHandler
+	t         *template.Template
+	verifyKey ed25519.PublicKey // key used by /verify; the server's own unless -pubkey is given
+	mu        sync.Mutex        // the handler keeps its state in plain maps, so requests run one at a time
+	sessions  map[string]session
+	wake      chan struct{}     // nudges runNotifications after a request that may have queued messages
 }
 
-func newServer() *Server {
+func newServer(dataDir, pubKeyPath string) (*Server, error) {
 	// Parse templates from embedded FS
-	tmpl := template.Must(template.ParseFS(content, "templates/*.html"))
-	return &Server{
-		h: handlerpkg.NewExamCenterHandler(),
-		t: tmpl,
+	tmpl := template.Must(template.New("").Funcs(templateFuncs).ParseFS(content, "templates/*.html"))
+	h, err := handlerpkg.OpenExamCenterHandler(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		h:        h,
+		t:        tmpl,
+		sessions: make(map[string]session),
+		wake:     make(chan struct{}, 1),
+	}
+	if pubKeyPath != "" {
+		// Verification-only deployment at a center gate: no signing key needed
+		pub, err := handlerpkg.LoadPublicKey(pubKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		s.verifyKey = pub
+		return s, nil
+	}
+	key, err := handlerpkg.LoadOrCreateSigningKey(dataDir)
+	if err != nil {
+		return nil, err
 	}
+	s.h.SetSigningKey(key)
+	s.verifyKey = s.h.PublicKey()
+	return s, nil
 }
 
 func (s *Server) routes() http.Handler {
@@ -36,9 +69,71 @@ func (s *Server) routes() http.Handler {
 	staticFS, _ := fs.Sub(content, "static")
 	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticFS))))
 
-	mux.HandleFunc("/", s.handleHome)
-	mux.HandleFunc("/search", s.handleSearch)
-	return mux
+	national := handlerpkg.RoleNationalAdmin
+	examAdmin := handlerpkg.RoleExamAdmin
+	superintendent := handlerpkg.RoleSuperintendent
+	candidate := handlerpkg.RoleCandidate
+
+	mux.HandleFunc("/", s.public(s.handleHome))
+	mux.HandleFunc("/search", s.public(s.handleSearch))
+	mux.HandleFunc("/login", s.public(s.handleLogin))
+	mux.HandleFunc("/login/code", s.public(s.handleLoginCode))
+	mux.HandleFunc("/logout", s.public(s.handleLogout))
+	mux.HandleFunc("/signup", s.public(s.handleSignup))
+	mux.HandleFunc("/publickey", s.public(s.handlePublicKey))
+	mux.HandleFunc("/register", s.require(s.handleRegister, candidate))
+	mux.HandleFunc("/my", s.require(s.handleMyRegistrations, candidate, examAdmin, superintendent, national))
+	mux.HandleFunc("/my/registration", s.require(s.handleManageRegistration, candidate))
+	mux.HandleFunc("/admitcard", s.require(s.handleAdmitCard, candidate, examAdmin, superintendent, national))
+	mux.HandleFunc("/verify", s.require(s.handleVerify, superintendent, national))
+	mux.HandleFunc("/attendance", s.require(s.handleAttendance, superintendent, national))
+	mux.HandleFunc("/api/attendance", s.require(s.handleAttendanceAPI, superintendent, national))
+	mux.HandleFunc("/api/exams", s.require(s.handleExamsAPI, examAdmin, national))
+	mux.HandleFunc("/api/registration", s.require(s.handleRegistrationAPI, candidate, examAdmin, superintendent, national))
+	mux.HandleFunc("/seating", s.require(s.handleSeating, superintendent, national))
+	mux.HandleFunc("/reports/sheets", s.require(s.handleSheets, superintendent, national))
+	mux.HandleFunc("/reports/manifest", s.require(s.handleManifest, examAdmin, superintendent, national))
+	mux.HandleFunc("/import", s.require(s.handleImport, examAdmin, national))
+	mux.HandleFunc("/admin/export", s.require(s.handleExport, examAdmin, national))
+	mux.HandleFunc("/admin/registrations", s.require(s.handleRegistrations, examAdmin, superintendent, national))
+	mux.HandleFunc("/admin/analytics", s.require(s.handleAnalytics, examAdmin, national))
+	mux.HandleFunc("/admin/map", s.require(s.handleDemandMap, examAdmin, national))
+	mux.HandleFunc("/admin/planning", s.require(s.handlePlanning, examAdmin, national))
+	mux.HandleFunc("/admin", s.require(s.handleAdmin, national))
+	mux.HandleFunc("/admin/city", s.require(s.handleAdminCity, national))
+	mux.HandleFunc("/admin/center", s.require(s.handleAdminCenter, national))
+	mux.HandleFunc("/admin/closure", s.require(s.handleAdminClosure, national))
+	mux.HandleFunc("/admin/cluster", s.require(s.handleAdminCluster, national))
+	mux.HandleFunc("/admin/geo", s.require(s.handleAdminGeo, national))
+	mux.HandleFunc("/admin/users", s.require(s.handleAdminUsers, national))
+	mux.HandleFunc("/admin/notifications", s.require(s.handleAdminNotifications, national))
+	mux.HandleFunc("/admin/webhooks", s.require(s.handleAdminWebhooks, national))
+	return s.serialize(mux)
+}
+
+// serialize runs requests one at a time and saves the state after any request that may have changed it
+func (s *Server) serialize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		// Pick up changes saved by CLI commands run next to the server
+		if err := s.h.Reload(); err != nil {
+			log.Printf("reloading state: %v", err)
+		}
+		next.ServeHTTP(w, r)
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			if err := s.h.Save(); err != nil {
+				log.Printf("saving state: %v", err)
+			}
+			s.wakeSender()
+		}
+	})
+}
+
+var templateFuncs = template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+	"dec": func(i int) int { return i - 1 },
+	"join": strings.Join,
 }
 
 type HomePageData struct {
@@ -55,9 +150,33 @@ type ResultCity struct {
 }
 
 type ResultsPageData struct {
-	Title     string
-	HomeCity  string
-	Results   []ResultCity
+	Title      string
+	HomeCity   string
+	Results    []ResultCity
+	Exams      []handlerpkg.ExamType
+	RollNumber string // set when a candidate is signed in
+	Contact    string // and the contact they verified
+	Map        template.HTML
+}
+
+type RegisteredPageData struct {
+	Title         string
+	Registration  handlerpkg.ExamRegistration
+	ReportingTime string
+	Waitlist      *handlerpkg.WaitlistEntry
+	Position      int
+	Map           template.HTML
+	Clashes       []handlerpkg.Clash
+}
+
+type VerifyPageData struct {
+	Title   string
+	Payload string
+	Center  string
+	Date    string
+	Checked bool
+	Error   string
+	Result  handlerpkg.GateCheck
 }
 
 func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
@@ -98,10 +217,137 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 			Centers:  centers,
 		})
 	}
-	data := ResultsPageData{Title: "Results — ExamCenterHub", HomeCity: homeCity, Results: results}
+	data := ResultsPageData{Title: "Results — ExamCenterHub", HomeCity: homeCity, Results: results, Exams: s.h.OpenExamEditions()}
+	// The SVG is generated from escaped text by handlerpkg.IndiaMap
+	data.Map = template.HTML(s.h.SuggestionMap(homeCity, nearest).SVG())
+	if u, ok := currentUser(r); ok && u.Role == handlerpkg.RoleCandidate {
+		data.RollNumber = u.RollNumber
+		data.Contact = u.Contact
+	}
 	_ = s.t.ExecuteTemplate(w, "results.html", data)
 }
 
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, "/?error="+urlQueryEscape("Invalid form submission"), http.StatusSeeOther)
+		return
+	}
+	u, _ := currentUser(r)
+	categories := strings.Join(r.Form["category"], ",")
+	phone, email := u.CandidateContacts(r.FormValue("phone"), r.FormValue("email"))
+	reg, wait, err := s.register(r.FormValue("home_city"), r.FormValue("name"), r.FormValue("exam_type"), u.RollNumber, phone, email, categories, r.FormValue("scribe") != "")
+	if err != nil {
+		http.Redirect(w, r, "/?error="+urlQueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	data := RegisteredPageData{Title: "Registered — ExamCenterHub", Registration: reg}
+	if wait != nil {
+		data.Title = "Waitlisted — ExamCenterHub"
+		data.Waitlist = wait
+		data.Position = s.h.WaitlistPosition(wait.ID)
+	} else {
+		data.ReportingTime = handlerpkg.ReportingTime(reg.TimeSlot)
+		data.Map = template.HTML(s.h.RegistrationMap(reg).SVG())
+		data.Clashes = s.h.RegistrationClashes(reg.ID)
+	}
+	_ = s.t.ExecuteTemplate(w, "registered.html", data)
+}
+
+// register runs the advanced assignment flow with default preferences and the candidate's priority
+// categories. When no center has room the candidate joins the waitlist instead.
+func (s *Server) register(cityInput, name, examInput, roll, phone, email, categories string, scribe bool) (handlerpkg.ExamRegistration, *handlerpkg.WaitlistEntry, error) {
+	homeCity, err := s.h.ValidateCity(cityInput)
+	if err != nil {
+		return handlerpkg.ExamRegistration{}, nil, err
+	}
+	exType, err := s.h.GetExamTypeDetails(examInput)
+	if err != nil {
+		return handlerpkg.ExamRegistration{}, nil, err
+	}
+	student, err := s.h.ValidateStudentInfo(name, exType.Code, roll)
+	if err != nil {
+		return handlerpkg.ExamRegistration{}, nil, err
+	}
+	if student.Phone, student.Email, err = handlerpkg.ValidateContacts(phone, email); err != nil {
+		return handlerpkg.ExamRegistration{}, nil, err
+	}
+	if student.Phone == "" && student.Email == "" {
+		return handlerpkg.ExamRegistration{}, nil, fmt.Errorf("give a mobile number or an email address")
+	}
+	prefs, err := handlerpkg.StudentPreference{MaxDistance: 1000, PreferredTransport: "any"}.WithCategories(categories, scribe)
+	if err != nil {
+		return handlerpkg.ExamRegistration{}, nil, err
+	}
+	nearest, err := s.h.FindNearestCitiesAdvanced(homeCity, exType, prefs)
+	if err != nil {
+		return handlerpkg.ExamRegistration{}, nil, err
+	}
+	if len(nearest) == 0 {
+		entry := s.h.JoinWaitlist(student, exType, homeCity, prefs)
+		return handlerpkg.ExamRegistration{}, &entry, nil
+	}
+	return s.h.CreateRegistration(student, exType, nearest[0], homeCity, prefs), nil, nil
+}
+
+func (s *Server) handleAdmitCard(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	reg, err := s.h.GetRegistration(id)
+	if u, _ := currentUser(r); err != nil || !u.CanSeeRegistration(reg) {
+		// Do not reveal whether registrations outside the user's scope exist
+		http.Error(w, fmt.Sprintf("registration '%s' not found", id), http.StatusNotFound)
+		return
+	}
+	pdf, err := s.h.GenerateAdmitCard(reg.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", handlerpkg.AdmitCardFileName(id)))
+	_, _ = w.Write(pdf)
+}
+
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	data := VerifyPageData{Title: "Verify Admit Card — ExamCenterHub", Date: time.Now().Format("2006-01-02"), Center: scopedCenter(r, "")}
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			http.Redirect(w, r, "/verify", http.StatusSeeOther)
+			return
+		}
+		data.Payload = r.FormValue("payload")
+		data.Center = scopedCenter(r, r.FormValue("center"))
+		if d := r.FormValue("date"); d != "" {
+			data.Date = d
+		}
+		res, err := s.h.CheckAdmitCardAtGate(data.Payload, s.verifyKey, data.Center, data.Date)
+		switch {
+		case errors.Is(err, handlerpkg.ErrInvalidAdmitCard):
+			data.Error = "Forged or damaged card: the signature does not verify."
+		case err != nil:
+			data.Error = err.Error()
+		default:
+			data.Result = res
+		}
+		data.Checked = true
+	}
+	_ = s.t.ExecuteTemplate(w, "verify.html", data)
+}
+
+func (s *Server) handlePublicKey(w http.ResponseWriter, r *http.Request) {
+	pem, err := handlerpkg.EncodePublicKey(s.verifyKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-pem-file")
+	w.Header().Set("Content-Disposition", "attachment; filename="+handlerpkg.PublicKeyFile)
+	_, _ = w.Write(pem)
+}
+
 func urlQueryEscape(s string) string {
 	// Simple replacement to avoid importing net/url just for this
 	replacer := map[string]string{
@@ -134,10 +380,49 @@ func indexOf(s, sub string) int {
 }
 
 func main() {
-	srv := newServer()
-	addr := ":8080"
-	log.Printf("ExamCenterHub web UI listening on %s", addr)
-	if err := http.ListenAndServe(addr, srv.routes()); err != nil {
+	addr := flag.String("addr", ":8080", "listen address")
+	dataDir := flag.String("data", handlerpkg.DefaultDataDir, "data directory for server state and keys")
+	pubKey := flag.String("pubkey", "", "run as a gate verifier using only this public key (PEM)")
+	adminUser := flag.String("admin-user", "admin", "national admin account created when no users exist yet")
+	smtpAddr := flag.String("smtp", "", "send candidate messages through this SMTP server (host:port) instead of the outbox directory")
+	smtpFrom := flag.String("smtp-from", "noreply@examcenterhub.local", "sender address for -smtp")
+	smtpUser := flag.String("smtp-user", "", "SMTP user name; the password is read from EXAMHUB_SMTP_PASSWORD")
+	smsGateway := flag.String("sms-gateway", "", "email-to-SMS gateway domain used by -smtp for mobile numbers")
+	notifyEvery := flag.Duration("notify-every", time.Minute, "how often to send due exam reminders and retry failed messages and webhooks")
+	flag.Parse()
+
+	srv, err := newServer(*dataDir, *pubKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *smtpAddr != "" {
+		srv.h.SetNotifier(handlerpkg.SMTPNotifier{
+			Addr:       *smtpAddr,
+			From:       *smtpFrom,
+			Username:   *smtpUser,
+			Password:   os.Getenv("EXAMHUB_SMTP_PASSWORD"),
+			SMSGateway: *smsGateway,
+		})
+	}
+	if !srv.h.HasUsers() {
+		// First start: create the national admin who can then add everyone else
+		password := os.Getenv("EXAMHUB_ADMIN_PASSWORD")
+		if password == "" {
+			if password, err = randomPassword(); err != nil {
+				log.Fatal(err)
+			}
+			log.Printf("EXAMHUB_ADMIN_PASSWORD not set; initial password for %s is %s", *adminUser, password)
+		}
+		admin := handlerpkg.User{Username: *adminUser, Role: handlerpkg.RoleNationalAdmin}
+		if err := srv.h.AddUser("setup", admin, password); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *pubKey == "" {
+		go srv.runNotifications(*notifyEvery)
+	}
+	log.Printf("ExamCenterHub web UI listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, srv.routes()); err != nil {
 		log.Fatal(err)
 	}
 } 
\ No newline at end of file
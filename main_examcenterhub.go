@@ -10,11 +10,24 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
 	fmt.Println("=== Welcome to ExamCenterHub ===")
 	fmt.Println("Indian Examination Center Assignment System")
 	fmt.Println()
 
-	examHandler := handler.NewExamCenterHandler()
+	examHandler, err := handler.OpenExamCenterHandler(dataDir())
+	if err != nil {
+		fmt.Printf("⚠️  %v (changes in this session will not be saved)\n", err)
+		examHandler = handler.NewExamCenterHandler()
+	}
+	if key, err := handler.LoadOrCreateSigningKey(dataDir()); err != nil {
+		fmt.Printf("⚠️  %v (admit cards will be signed with a temporary key)\n", err)
+	} else {
+		examHandler.SetSigningKey(key)
+	}
 	scanner := bufio.NewScanner(os.Stdin)
 
 	for {
@@ -23,14 +36,22 @@ func main() {
 		fmt.Println("2. Advanced Assignment with Preferences")
 		fmt.Println("3. View Available Exam Types")
 		fmt.Println("4. View Registration Summary")
-		fmt.Println("5. Exit")
-		fmt.Print("\nSelect an option (1-5): ")
+		fmt.Println("5. Download Admit Card (PDF)")
+		fmt.Println("6. Exam-Day Attendance")
+		fmt.Println("7. Room & Seat Allocation")
+		fmt.Println("8. Attendance Sheets & Dispatch Manifest")
+		fmt.Println("9. Exit")
+		fmt.Print("\nSelect an option (1-9): ")
 
 		if !scanner.Scan() {
 			fmt.Println("\nInput error. Exiting...")
 			return
 		}
 		choice := strings.TrimSpace(scanner.Text())
+		// Another session or the web server may have saved since the last option
+		if err := examHandler.Reload(); err != nil {
+			fmt.Printf("\n⚠️  %v\n", err)
+		}
 
 		switch choice {
 		case "1":
@@ -42,15 +63,34 @@ func main() {
 		case "4":
 			examHandler.ShowRegistrationSummary()
 		case "5":
+			if err := examHandler.ProcessAdmitCardDownload(); err != nil {
+				fmt.Printf("\n❌ %v\n", err)
+			}
+		case "6":
+			if err := examHandler.ProcessAttendance(); err != nil {
+				fmt.Printf("\n❌ %v\n", err)
+			}
+		case "7":
+			if err := examHandler.ProcessSeatAllocation(); err != nil {
+				fmt.Printf("\n❌ %v\n", err)
+			}
+		case "8":
+			if err := examHandler.ProcessReports(); err != nil {
+				fmt.Printf("\n❌ %v\n", err)
+			}
+		case "9":
 			fmt.Println("\n👋 Thank you for using ExamCenterHub!")
 			fmt.Println("Good luck with your exams! 🎯")
 			return
 		default:
-			fmt.Println("\n❌ Invalid option. Please select 1-5.")
+			fmt.Println("\n❌ Invalid option. Please select 1-9.")
+		}
+		if err := examHandler.Save(); err != nil {
+			fmt.Printf("\n⚠️  %v\n", err)
 		}
 
 		// Wait for user to press Enter before showing menu again
-		if choice != "5" {
+		if choice != "9" {
 			fmt.Print("\nPress Enter to continue...")
 			scanner.Scan()
 		}
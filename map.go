@@ -0,0 +1,332 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Marker kinds on a map
+const (
+	MarkerHome     = "home"
+	MarkerCenter   = "center"   // a suggested exam city
+	MarkerAssigned = "assigned" // the exam city a candidate was given
+	MarkerDemand   = "demand"   // a city on the demand heatmap
+)
+
+var markerColours = map[string]string{
+	MarkerHome:     "#dc2626",
+	MarkerCenter:   "#2563eb",
+	MarkerAssigned: "#16a34a",
+	MarkerDemand:   "#6b7280",
+}
+
+// MapMarker is a point drawn on the India map
+type MapMarker struct {
+	Name   string
+	Lat    float64
+	Lng    float64
+	Kind   string
+	Note   string  // tooltip text after the name, e.g. "412 km"
+	Radius float64 // 0 uses the default marker size
+	Colour string  // overrides the kind's colour
+	Label  bool    // print the name next to the marker
+}
+
+// MapLine joins two points, e.g. a home city and an exam city
+type MapLine struct {
+	FromLat float64
+	FromLng float64
+	ToLat   float64
+	ToLng   float64
+	Dashed  bool
+	Note    string
+}
+
+// LegendEntry explains one marker colour
+type LegendEntry struct {
+	Label  string
+	Colour string
+}
+
+// IndiaMap is an outline map of India rendered to SVG on the server; no tile service is involved
+type IndiaMap struct {
+	Title   string
+	Markers []MapMarker
+	Lines   []MapLine
+	Legend  []LegendEntry
+}
+
+// Map layout. The projection is equirectangular over the bounding box, with longitude
+// shrunk by cos 22° so shapes are true near the middle of the country.
+const (
+	mapMinLat  = 6.0
+	mapMaxLat  = 37.5
+	mapMinLng  = 67.5
+	mapMaxLng  = 98.0
+	mapScale   = 20.0 // SVG units per degree of latitude
+	mapTitleH  = 26
+	mapMarkerR = 5.0
+)
+
+var mapLngScale = mapScale * math.Cos(22*math.Pi/180)
+
+// indiaOutline is a coarse mainland border as lng, lat pairs, clockwise from the Rann of Kutch
+var indiaOutline = [][2]float64{
+	{68.2, 23.7}, {68.8, 24.3}, {70.0, 24.6}, {71.1, 24.4}, {70.6, 25.7}, {70.2, 26.5}, {69.5, 27.0},
+	{70.4, 28.0}, {71.9, 27.9}, {72.9, 29.0}, {73.4, 29.9}, {74.5, 30.9}, {74.6, 31.9}, {75.3, 32.3},
+	{74.6, 32.8}, {74.0, 33.5}, {73.9, 34.4}, {74.3, 35.0}, {75.7, 35.5}, {77.0, 35.7}, {78.0, 35.4},
+	{78.9, 34.3}, {78.7, 33.2}, {79.4, 32.5}, {78.9, 31.4}, {79.9, 30.9}, {80.5, 30.4}, {80.1, 28.8},
+	{81.2, 28.4}, {82.5, 27.5}, {83.4, 27.4}, {84.6, 27.3}, {85.5, 26.7}, {86.8, 26.4}, {88.1, 26.5},
+	{88.2, 27.1}, {88.8, 28.1}, {88.9, 27.3}, {89.8, 26.7}, {92.1, 26.9}, {92.0, 27.8}, {94.0, 28.8},
+	{95.4, 29.3}, {96.6, 28.8}, {97.4, 28.2}, {96.5, 27.3}, {95.2, 26.6}, {94.6, 25.2}, {94.2, 23.9},
+	{93.3, 23.0}, {93.1, 22.0}, {92.6, 21.9}, {92.3, 23.7}, {91.8, 23.1}, {91.2, 23.7}, {91.6, 24.2},
+	{92.2, 24.9}, {90.0, 25.3}, {89.8, 26.0}, {88.7, 26.3}, {88.3, 25.3}, {88.9, 24.3}, {88.7, 23.0},
+	{89.0, 22.0}, {88.2, 21.6}, {87.0, 21.5}, {86.5, 20.3}, {85.0, 19.4}, {84.0, 18.3}, {82.3, 16.6},
+	{81.2, 15.9}, {80.2, 15.1}, {80.3, 13.4}, {79.8, 11.8}, {79.8, 10.3}, {78.9, 9.3}, {78.1, 8.4},
+	{77.5, 8.1}, {76.5, 9.0}, {75.8, 11.2}, {74.8, 12.9}, {74.1, 14.8}, {73.4, 16.5}, {72.8, 19.0},
+	{72.7, 20.5}, {72.6, 21.4}, {72.3, 22.3}, {72.1, 21.2}, {70.9, 20.7}, {69.0, 22.3}, {70.0, 22.9},
+	{68.6, 23.2},
+}
+
+// project converts a coordinate to SVG units
+func project(lat, lng float64) (x, y float64) {
+	return (lng - mapMinLng) * mapLngScale, mapTitleH + (mapMaxLat-lat)*mapScale
+}
+
+// onMap reports whether a coordinate falls inside the drawn area
+func onMap(lat, lng float64) bool {
+	return lat >= mapMinLat && lat <= mapMaxLat && lng >= mapMinLng && lng <= mapMaxLng
+}
+
+// SVG renders the map as a standalone <svg> element that scales to its container.
+// Markers outside the bounding box are left out.
+func (m IndiaMap) SVG() string {
+	width, _ := project(mapMinLat, mapMaxLng)
+	_, height := project(mapMinLat, mapMinLng)
+	var s strings.Builder
+	fmt.Fprintf(&s, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %.0f %.0f" width="100%%" role="img" aria-label="%s" font-family="sans-serif" font-size="11" fill="currentColor">`,
+		width, height, xmlEscape(m.Title))
+	fmt.Fprintf(&s, `<text x="0" y="16" font-size="14" font-weight="bold">%s</text>`, xmlEscape(m.Title))
+	s.WriteString(`<path d="`)
+	for i, p := range indiaOutline {
+		x, y := project(p[1], p[0])
+		cmd := "L"
+		if i == 0 {
+			cmd = "M"
+		}
+		fmt.Fprintf(&s, "%s%.1f %.1f ", cmd, x, y)
+	}
+	s.WriteString(`Z" fill="currentColor" fill-opacity="0.06" stroke="currentColor" stroke-opacity="0.35" stroke-linejoin="round"/>`)
+
+	for _, l := range m.Lines {
+		if !onMap(l.FromLat, l.FromLng) || !onMap(l.ToLat, l.ToLng) {
+			continue
+		}
+		x1, y1 := project(l.FromLat, l.FromLng)
+		x2, y2 := project(l.ToLat, l.ToLng)
+		dash := ""
+		if l.Dashed {
+			dash = ` stroke-dasharray="4 3"`
+		}
+		fmt.Fprintf(&s, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="currentColor" stroke-opacity="0.6" stroke-width="1.5"%s>`, x1, y1, x2, y2, dash)
+		if l.Note != "" {
+			fmt.Fprintf(&s, `<title>%s</title>`, xmlEscape(l.Note))
+		}
+		s.WriteString("</line>")
+	}
+
+	// Large markers first so small ones stay visible on top
+	markers := append([]MapMarker(nil), m.Markers...)
+	sort.SliceStable(markers, func(i, j int) bool { return markers[i].radius() > markers[j].radius() })
+	for _, mk := range markers {
+		if !onMap(mk.Lat, mk.Lng) {
+			continue
+		}
+		x, y := project(mk.Lat, mk.Lng)
+		colour := mk.Colour
+		if colour == "" {
+			colour = markerColours[mk.Kind]
+		}
+		tip := mk.Name
+		if mk.Note != "" {
+			tip += " · " + mk.Note
+		}
+		fmt.Fprintf(&s, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s" fill-opacity="0.75" stroke="%s"><title>%s</title></circle>`,
+			x, y, mk.radius(), colour, colour, xmlEscape(tip))
+		if mk.Label {
+			fmt.Fprintf(&s, `<text x="%.1f" y="%.1f">%s</text>`, x+mk.radius()+3, y+4, xmlEscape(mk.Name))
+		}
+	}
+
+	// The legend sits in the Arabian Sea, bottom left
+	for i, e := range m.Legend {
+		y := height - float64(len(m.Legend)-i)*16
+		fmt.Fprintf(&s, `<circle cx="8" cy="%.1f" r="5" fill="%s" fill-opacity="0.75"/><text x="18" y="%.1f">%s</text>`, y, e.Colour, y+4, xmlEscape(e.Label))
+	}
+	s.WriteString("</svg>")
+	return s.String()
+}
+
+func (mk MapMarker) radius() float64 {
+	if mk.Radius > 0 {
+		return mk.Radius
+	}
+	return mapMarkerR
+}
+
+// SuggestionMap shows a home city, the exam cities suggested for it and a dashed line to each
+func (h *ExamCenterHandler) SuggestionMap(homeCity string, nearest []CityDistance) IndiaMap {
+	m := IndiaMap{
+		Title:  "Nearest exam cities from " + homeCity,
+		Legend: []LegendEntry{{"Home city", markerColours[MarkerHome]}, {"Suggested exam city", markerColours[MarkerCenter]}},
+	}
+	home, ok := h.cities[homeCity]
+	if !ok {
+		return m
+	}
+	for _, cd := range nearest {
+		note := fmt.Sprintf("%.0f km · %d centers", cd.Distance, len(cd.Centers))
+		m.Markers = append(m.Markers, MapMarker{Name: cd.City.Name, Lat: cd.City.Lat, Lng: cd.City.Lng, Kind: MarkerCenter, Note: note, Label: true})
+		m.Lines = append(m.Lines, MapLine{FromLat: home.Lat, FromLng: home.Lng, ToLat: cd.City.Lat, ToLng: cd.City.Lng, Dashed: true, Note: homeCity + " → " + cd.City.Name + ": " + note})
+	}
+	m.Markers = append(m.Markers, MapMarker{Name: home.Name, Lat: home.Lat, Lng: home.Lng, Kind: MarkerHome, Note: "home city", Label: true})
+	return m
+}
+
+// RegistrationMap shows a candidate's journey from their home city to the assigned center
+func (h *ExamCenterHandler) RegistrationMap(reg ExamRegistration) IndiaMap {
+	m := IndiaMap{
+		Title:  "Your exam center",
+		Legend: []LegendEntry{{"Home city", markerColours[MarkerHome]}, {"Exam city", markerColours[MarkerAssigned]}},
+	}
+	home, okHome := h.cities[reg.StudentCity]
+	exam, okExam := h.cities[reg.AssignedCity]
+	if okHome && okExam {
+		m.Lines = append(m.Lines, MapLine{FromLat: home.Lat, FromLng: home.Lng, ToLat: exam.Lat, ToLng: exam.Lng,
+			Note: fmt.Sprintf("%s → %s: %.0f km", home.Name, exam.Name, reg.Distance)})
+	}
+	if okExam {
+		m.Markers = append(m.Markers, MapMarker{Name: exam.Name, Lat: exam.Lat, Lng: exam.Lng, Kind: MarkerAssigned, Note: reg.AssignedCenter, Label: true})
+	}
+	if okHome {
+		m.Markers = append(m.Markers, MapMarker{Name: home.Name, Lat: home.Lat, Lng: home.Lng, Kind: MarkerHome, Note: "home city", Label: true})
+	}
+	return m
+}
+
+// CityDemand compares how many candidates live in a city with the seats it has
+type CityDemand struct {
+	City     string
+	Demand   int     // registrations whose home city this is
+	Capacity int     // seats in the city's active centers
+	Booked   int     // of Capacity
+	Ratio    float64 // Demand / Capacity; 0 when the city has no seats
+}
+
+// DemandByCity counts the registrations matching f by home city against each city's seats.
+// allowed limits them to what the caller may see; nil allows all. Cities with neither
+// demand nor seats are left out. The busiest cities come first.
+func (h *ExamCenterHandler) DemandByCity(f RegistrationFilter, allowed func(ExamRegistration) bool) []CityDemand {
+	byCity := make(map[string]*CityDemand)
+	get := func(city string) *CityDemand {
+		if d, ok := byCity[city]; ok {
+			return d
+		}
+		d := &CityDemand{City: city}
+		byCity[city] = d
+		return d
+	}
+	for _, r := range h.FilterRegistrations(f, allowed) {
+		get(r.StudentCity).Demand++
+	}
+	for _, c := range h.ListCenters("") {
+		if c.Center.Disabled || c.Capacity.TotalSeats == 0 {
+			continue
+		}
+		d := get(c.Center.City)
+		d.Capacity += c.Capacity.TotalSeats
+		d.Booked += c.Capacity.BookedSeats
+	}
+	list := make([]CityDemand, 0, len(byCity))
+	for _, d := range byCity {
+		if d.Capacity > 0 {
+			d.Ratio = float64(d.Demand) / float64(d.Capacity)
+		}
+		list = append(list, *d)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Demand != list[j].Demand {
+			return list[i].Demand > list[j].Demand
+		}
+		if list[i].Capacity != list[j].Capacity {
+			return list[i].Capacity > list[j].Capacity
+		}
+		return list[i].City < list[j].City
+	})
+	return list
+}
+
+// demandBands colour a city by its demand as a share of its seats
+var demandBands = []struct {
+	upTo   float64
+	label  string
+	colour string
+}{
+	{0.25, "Under 25% of seats", "#16a34a"},
+	{0.5, "25–50%", "#84cc16"},
+	{0.75, "50–75%", "#eab308"},
+	{1, "75–100%", "#f97316"},
+	{math.Inf(1), "Over 100% of seats", "#dc2626"},
+}
+
+// noSeatsColour marks cities that have candidates but no seats
+const noSeatsColour = "#7f1d1d"
+
+// DemandBand returns the heatmap colour and legend label for a city
+func DemandBand(d CityDemand) (colour, label string) {
+	if d.Capacity == 0 {
+		return noSeatsColour, "No seats in the city"
+	}
+	for _, b := range demandBands {
+		if d.Ratio <= b.upTo {
+			return b.colour, b.label
+		}
+	}
+	return noSeatsColour, ""
+}
+
+// mapLabels is how many of the busiest cities are named on the heatmap
+const mapLabels = 8
+
+// DemandMap draws demand as circles sized by candidates and coloured by demand over seats.
+// Cities with seats but no candidates are small grey dots.
+func (h *ExamCenterHandler) DemandMap(title string, demand []CityDemand) IndiaMap {
+	m := IndiaMap{Title: title}
+	for _, b := range demandBands {
+		m.Legend = append(m.Legend, LegendEntry{b.label, b.colour})
+	}
+	m.Legend = append(m.Legend, LegendEntry{"No seats in the city", noSeatsColour}, LegendEntry{"No candidates", markerColours[MarkerDemand]})
+	top := 0
+	for _, d := range demand {
+		top = max(top, d.Demand)
+	}
+	labelled := 0
+	for _, d := range demand {
+		c, ok := h.cities[d.City]
+		if !ok {
+			continue
+		}
+		mk := MapMarker{Name: d.City, Lat: c.Lat, Lng: c.Lng, Kind: MarkerDemand, Radius: 2.5,
+			Note: fmt.Sprintf("%d candidates · %d seats", d.Demand, d.Capacity)}
+		if d.Demand > 0 {
+			mk.Radius = 3 + 15*math.Sqrt(float64(d.Demand)/float64(top))
+			mk.Colour, _ = DemandBand(d)
+			mk.Label = labelled < mapLabels
+			labelled++
+		}
+		m.Markers = append(m.Markers, mk)
+	}
+	return m
+} 
\ No newline at end of file
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDemandByCity(t *testing.T) {
+	h := analyticsHandler()
+	want := []CityDemand{
+		{City: "Surat", Demand: 2, Capacity: 4, Booked: 4, Ratio: 0.5},
+		{City: "Satara", Demand: 2}, // its only center is closed
+		{City: "Pune", Capacity: 10, Booked: 5},
+	}
+	if got := h.DemandByCity(RegistrationFilter{}, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("demand %+v, want %+v", got, want)
+	}
+	want = []CityDemand{{City: "Satara", Demand: 2}, {City: "Pune", Capacity: 10, Booked: 5}, {City: "Surat", Capacity: 4, Booked: 4}}
+	if got := h.DemandByCity(RegistrationFilter{Exam: "UPSC"}, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("UPSC demand %+v, want %+v", got, want)
+	}
+}
+
+func TestDemandBand(t *testing.T) {
+	tests := []struct {
+		demand CityDemand
+		label  string
+	}{
+		{CityDemand{Demand: 3}, "No seats in the city"},
+		{CityDemand{Demand: 1, Capacity: 4, Ratio: 0.25}, "Under 25% of seats"},
+		{CityDemand{Demand: 2, Capacity: 4, Ratio: 0.5}, "25–50%"},
+		{CityDemand{Demand: 3, Capacity: 4, Ratio: 0.75}, "50–75%"},
+		{CityDemand{Demand: 4, Capacity: 4, Ratio: 1}, "75–100%"},
+		{CityDemand{Demand: 5, Capacity: 4, Ratio: 1.25}, "Over 100% of seats"},
+	}
+	for _, tt := range tests {
+		if _, label := DemandBand(tt.demand); label != tt.label {
+			t.Errorf("%+v: band %q, want %q", tt.demand, label, tt.label)
+		}
+	}
+}
+
+func TestDemandMap(t *testing.T) {
+	h := analyticsHandler()
+	m := h.DemandMap("Demand", h.DemandByCity(RegistrationFilter{}, nil))
+	type marker struct {
+		Name   string
+		Radius float64
+		Colour string
+		Label  bool
+	}
+	var got []marker
+	for _, mk := range m.Markers {
+		got = append(got, marker{mk.Name, mk.Radius, mk.Colour, mk.Label})
+	}
+	want := []marker{{"Surat", 18, "#84cc16", true}, {"Satara", 18, noSeatsColour, true}, {"Pune", 2.5, "", false}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("markers %+v, want %+v", got, want)
+	}
+}
+
+func TestIndiaMapSVG(t *testing.T) {
+	m := IndiaMap{
+		Title: "Pune & around",
+		Markers: []MapMarker{
+			{Name: "Pune", Lat: 18.52, Lng: 73.86, Kind: MarkerHome, Note: "home city", Label: true},
+			{Name: "Mumbai", Lat: 19.08, Lng: 72.88, Kind: MarkerCenter, Radius: 12},
+			{Name: "Colombo", Lat: 5.9, Lng: 79.86, Kind: MarkerCenter}, // south of the drawn area
+		},
+		Lines:  []MapLine{{FromLat: 18.52, FromLng: 73.86, ToLat: 19.08, ToLng: 72.88, Dashed: true, Note: "Pune → Mumbai"}},
+		Legend: []LegendEntry{{"Home city", markerColours[MarkerHome]}},
+	}
+	svg := m.SVG()
+	if !strings.HasPrefix(svg, "<svg ") || !strings.HasSuffix(svg, "</svg>") {
+		t.Fatalf("not an SVG element: %.40s", svg)
+	}
+	for _, part := range []string{`aria-label="Pune &amp; around"`, `<title>Pune · home city</title>`, `<text x="`, `stroke-dasharray="4 3"`, `<title>Pune → Mumbai</title>`} {
+		if !strings.Contains(svg, part) {
+			t.Errorf("SVG is missing %s", part)
+		}
+	}
+	if strings.Contains(svg, "Colombo") {
+		t.Error("marker outside the map was drawn")
+	}
+	// two markers and one legend dot; the larger Mumbai marker goes underneath
+	if n := strings.Count(svg, "<circle"); n != 3 {
+		t.Errorf("%d circles, want 3", n)
+	}
+	if strings.Index(svg, "<title>Mumbai</title>") > strings.Index(svg, "<title>Pune · home city</title>") {
+		t.Error("large marker drawn over the small one")
+	}
+
+	x, y := project(mapMaxLat, mapMinLng)
+	if x != 0 || y != mapTitleH {
+		t.Errorf("top-left corner projects to %v, %v", x, y)
+	}
+}
+
+func TestRegistrationMap(t *testing.T) {
+	h := NewExamCenterHandler()
+	reg := ExamRegistration{StudentCity: "Pune", AssignedCity: "Navi Mumbai", AssignedCenter: "Navi Mumbai Central Exam Center", Distance: 105}
+	m := h.RegistrationMap(reg)
+	if len(m.Markers) != 2 || len(m.Lines) != 1 || m.Lines[0].Note != "Pune → Navi Mumbai: 105 km" {
+		t.Errorf("map %+v, want two markers joined by a line", m)
+	}
+	reg.StudentCity = "Atlantis"
+	if m := h.RegistrationMap(reg); len(m.Markers) != 1 || len(m.Lines) != 0 {
+		t.Errorf("unknown home city: %+v, want only the exam city", m)
+	}
+} 
\ No newline at end of file
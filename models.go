@@ -4,25 +4,46 @@ import "time"
 
 // City represents a city with its coordinates
 type City struct {
-	Name string
-	Lat  float64
-	Lng  float64
+	Name     string
+	State    string // state or union territory, one of IndianStates
+	District string
+	Lat      float64
+	Lng      float64
+	Disabled bool // still valid as a home city, but never offered as an exam city
 }
 
 // ExamCenter represents an examination center
 type ExamCenter struct {
-	Name string
-	City string
+	Name          string
+	City          string
+	Rooms         []Room // declared exam halls; derived from capacity when empty
+	Disabled      bool
+	Accessible    bool // ground-floor or wheelchair-accessible halls, required for PwD candidates
+	Scribes       bool // can seat candidates who write with a scribe
+	PrioritySeats int  // free seats held back for priority candidates, see categories.go
 }
 
-// ExamType represents different types of examinations
+// Room is an exam hall inside a center, laid out as rows of seats
+type Room struct {
+	Name        string
+	Rows        int
+	SeatsPerRow int
+}
+
+// ExamType represents one edition (year) of an examination
 type ExamType struct {
 	Code        string
+	Edition     int // year of this edition, e.g. 2025; see exams.go
 	Name        string
 	Description string
 	Duration    time.Duration
 	Schedule    ExamSchedule
-	MaxCenters  int // Max number of nearby cities to suggest
+	MaxCenters  int            // Max number of nearby cities to suggest
+	Allocation  AllocationRule // which exam cities a candidate may be given
+	Zones       string         // zone scheme for AllocateWithinZone, a key of ZoneSchemes
+	HomeCity    HomeCityPolicy // whether the home city or its metro cluster can be the exam city
+	Closed      bool           // no longer taking registrations
+	WomenRadius float64        // km; women are placed within this radius when a center there has room (0: no guarantee)
 }
 
 // ExamSchedule represents the schedule information for an exam
@@ -45,22 +66,50 @@ type StudentPreference struct {
 	MaxDistance        float64 // km
 	PreferredTransport string  // "train" | "bus" | "flight" | "any"
 	AccommodationNeeded bool
+	Categories         []Category // priority categories, see categories.go
+	NeedsScribe        bool       // PwD candidate who writes with a scribe
 }
 
 // ExamRegistration represents a completed exam registration
 type ExamRegistration struct {
 	ID               string
 	StudentName      string
+	RollNumber       string
+	CandidateID      string // the same person across exams, see candidates.go
 	StudentCity      string
+	Phone            string // 10-digit mobile number, see NormalizeContact
+	Email            string
 	ExamType         ExamType
 	AssignedCity     string
 	AssignedCenter   string
+	ExamDate         string // YYYY-MM-DD
+	TimeSlot         string // e.g. "09:00-12:00"
+	Room             string // set by seat allocation
+	SeatNumber       string // e.g. "C4": row C, seat 4
 	Distance         float64
 	RegistrationTime time.Time
 	Preferences      StudentPreference
+	Explanation      *AssignmentExplanation // why this center; nil for registrations from before explanations
+}
+
+// AttendanceStatus records whether a candidate sat the exam
+type AttendanceStatus string
+
+const (
+	AttendancePresent AttendanceStatus = "present"
+	AttendanceAbsent  AttendanceStatus = "absent"
+)
+
+// AttendanceRecord is an invigilator's exam-day mark for one registration
+type AttendanceRecord struct {
+	RegistrationID string
+	Status         AttendanceStatus
+	MarkedBy       string
+	MarkedAt       time.Time
 }
 
-// PredefinedExamTypes contains commonly available exam types in India
+// PredefinedExamTypes contains commonly available exam types in India. They seed the exam
+// catalogue of a new data directory; after that exam types are edited as data.
 var PredefinedExamTypes = map[string]ExamType{
 	"JEE": {
 		Code:        "JEE",
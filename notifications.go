@@ -0,0 +1,307 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Delivery statuses
+const (
+	DeliveryPending = "pending" // waiting for its next attempt
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed" // gave up after MaxDeliveryAttempts
+)
+
+// MaxDeliveryAttempts is how often a message is tried before it is marked failed
+const MaxDeliveryAttempts = 5
+
+// ReminderLeadDays is how many days before the exam candidates are reminded
+const ReminderLeadDays = 3
+
+// deliveryBackoff is the wait after each failed attempt; the last value repeats
+var deliveryBackoff = []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute, time.Hour}
+
+// Delivery is one message in the delivery log
+type Delivery struct {
+	ID             string
+	Event          EventKind
+	RegistrationID string
+	Channel        string
+	To             string
+	Subject        string
+	Body           string
+	Status         string
+	Attempts       int
+	LastError      string
+	CreatedAt      time.Time
+	NextAttempt    time.Time
+	SentAt         time.Time
+}
+
+// notificationTemplate holds the text/template sources for one event
+type notificationTemplate struct {
+	subject string
+	email   string
+	sms     string
+}
+
+// notificationData is what the templates can use
+type notificationData struct {
+	Registration   ExamRegistration
+	ReportingTime  string
+	PreviousCenter string
+	PreviousCity   string
+	Reason         string
+	DaysLeft       int
+}
+
+var notificationTemplates = map[EventKind]notificationTemplate{
+	EventRegistrationCreated: {
+		subject: "{{ .Registration.ExamType.Code }} registration confirmed: {{ .Registration.ID }}",
+		email: `Dear {{ .Registration.StudentName }},
+
+Your registration for {{ .Registration.ExamType.Name }} is confirmed.
+
+Registration ID: {{ .Registration.ID }}
+Exam center:     {{ .Registration.AssignedCenter }}, {{ .Registration.AssignedCity }}
+Date and slot:   {{ .Registration.ExamDate }}, {{ .Registration.TimeSlot }}
+Reporting time:  {{ .ReportingTime }}
+
+Download your admit card after signing in to ExamCenterHub.`,
+		sms: "{{ .Registration.ExamType.Code }} registration {{ .Registration.ID }} confirmed. Center: {{ .Registration.AssignedCenter }}, {{ .Registration.AssignedCity }} on {{ .Registration.ExamDate }} {{ .Registration.TimeSlot }}, report by {{ .ReportingTime }}.",
+	},
+	EventRegistrationReassigned: {
+		subject: "Exam center changed for {{ .Registration.ID }}",
+		email: `Dear {{ .Registration.StudentName }},
+
+Your exam center for {{ .Registration.ExamType.Name }} has changed.{{ if .Reason }} {{ .Reason }}{{ end }}
+
+Previous center: {{ .PreviousCenter }}, {{ .PreviousCity }}
+New center:      {{ .Registration.AssignedCenter }}, {{ .Registration.AssignedCity }}
+Date and slot:   {{ .Registration.ExamDate }}, {{ .Registration.TimeSlot }}
+Reporting time:  {{ .ReportingTime }}
+
+Your old admit card is no longer valid. Download the new one after signing in to ExamCenterHub.`,
+		sms: "{{ .Registration.ExamType.Code }} {{ .Registration.ID }}: your center is now {{ .Registration.AssignedCenter }}, {{ .Registration.AssignedCity }} (was {{ .PreviousCenter }}). Download the new admit card.",
+	},
+	EventWaitlistPromoted: {
+		subject: "A seat is now confirmed for {{ .Registration.ExamType.Code }}: {{ .Registration.ID }}",
+		email: `Dear {{ .Registration.StudentName }},
+
+A seat has become available and you have been moved off the waitlist for {{ .Registration.ExamType.Name }}.
+
+Registration ID: {{ .Registration.ID }}
+Exam center:     {{ .Registration.AssignedCenter }}, {{ .Registration.AssignedCity }}
+Date and slot:   {{ .Registration.ExamDate }}, {{ .Registration.TimeSlot }}
+Reporting time:  {{ .ReportingTime }}
+
+Download your admit card after signing in to ExamCenterHub.`,
+		sms: "{{ .Registration.ExamType.Code }} waitlist: seat confirmed at {{ .Registration.AssignedCenter }}, {{ .Registration.AssignedCity }} on {{ .Registration.ExamDate }}. Registration {{ .Registration.ID }}.",
+	},
+	EventExamReminder: {
+		subject: "{{ .Registration.ExamType.Code }} in {{ .DaysLeft }} day(s): report by {{ .ReportingTime }}",
+		email: `Dear {{ .Registration.StudentName }},
+
+This is a reminder that your {{ .Registration.ExamType.Name }} exam is on {{ .Registration.ExamDate }}.
+
+Exam center:    {{ .Registration.AssignedCenter }}, {{ .Registration.AssignedCity }}
+Time slot:      {{ .Registration.TimeSlot }}
+Reporting time: {{ .ReportingTime }}{{ if .Registration.Room }}
+Room / seat:    {{ .Registration.Room }} / {{ .Registration.SeatNumber }}{{ end }}
+
+Carry your printed admit card and a valid photo ID. Late arrivals will not be admitted.`,
+		sms: "Reminder: {{ .Registration.ExamType.Code }} on {{ .Registration.ExamDate }} at {{ .Registration.AssignedCenter }}, {{ .Registration.AssignedCity }}. Report by {{ .ReportingTime }} with admit card and photo ID.",
+	},
+}
+
+// SetChannelNotifier sends one channel (ChannelEmail or ChannelSMS) through n instead of the default notifier
+func (h *ExamCenterHandler) SetChannelNotifier(channel string, n Notifier) { h.senders[channel] = n }
+
+// sender returns the notifier for a channel
+func (h *ExamCenterHandler) sender(channel string) Notifier {
+	if n, ok := h.senders[channel]; ok {
+		return n
+	}
+	return h.notifier
+}
+
+// notifyCandidate turns an event into templated messages on every channel the candidate gave and
+// queues them in the delivery log. Nothing is sent here: the caller may be holding a lock, so
+// RunNotifications or the server's sender delivers them.
+func (h *ExamCenterHandler) notifyCandidate(ev Event) {
+	tmpl, ok := notificationTemplates[ev.Kind]
+	if !ok {
+		return
+	}
+	reg := ev.Registration
+	data := notificationData{
+		Registration:   reg,
+		ReportingTime:  ReportingTime(reg.TimeSlot),
+		PreviousCenter: ev.PreviousCenter,
+		PreviousCity:   ev.PreviousCity,
+		Reason:         ev.Reason,
+		DaysLeft:       daysUntil(ev.Time, reg.ExamDate),
+	}
+	now := ev.Time
+	for _, target := range []struct{ channel, to string }{{ChannelEmail, reg.Email}, {ChannelSMS, reg.Phone}} {
+		if target.to == "" {
+			continue
+		}
+		d := Delivery{
+			ID:             fmt.Sprintf("D%06d", len(h.deliveries)+1),
+			Event:          ev.Kind,
+			RegistrationID: reg.ID,
+			Channel:        target.channel,
+			To:             target.to,
+			Status:         DeliveryPending,
+			CreatedAt:      now,
+			NextAttempt:    now,
+		}
+		var err error
+		if target.channel == ChannelEmail {
+			if d.Subject, err = renderNotification(tmpl.subject, data); err == nil {
+				d.Body, err = renderNotification(tmpl.email, data)
+			}
+		} else {
+			d.Body, err = renderNotification(tmpl.sms, data)
+		}
+		if err != nil {
+			d.Status, d.LastError = DeliveryFailed, err.Error()
+		}
+		h.deliveries = append(h.deliveries, d)
+	}
+}
+
+func renderNotification(src string, data notificationData) (string, error) {
+	t, err := template.New("").Parse(src)
+	if err != nil {
+		return "", fmt.Errorf("error parsing notification template: %v", err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("error rendering notification: %v", err)
+	}
+	return b.String(), nil
+}
+
+// DeliveryAttempt is a due message taken out of the delivery log together with the notifier for its
+// channel, so it can be sent by SendDeliveries without holding whatever guards the handler
+type DeliveryAttempt struct {
+	index    int
+	delivery Delivery
+	sender   Notifier
+	err      error
+}
+
+// DueDeliveries returns the pending messages whose backoff has passed
+func (h *ExamCenterHandler) DueDeliveries(now time.Time) []DeliveryAttempt {
+	var due []DeliveryAttempt
+	for i, d := range h.deliveries {
+		if d.Status == DeliveryPending && !now.Before(d.NextAttempt) {
+			due = append(due, DeliveryAttempt{index: i, delivery: d, sender: h.sender(d.Channel)})
+		}
+	}
+	return due
+}
+
+// SendDeliveries sends due messages and returns the attempts made. It touches no handler state.
+func SendDeliveries(due []DeliveryAttempt) []DeliveryAttempt {
+	tried := make([]DeliveryAttempt, 0, len(due))
+	for _, a := range due {
+		a.err = fmt.Errorf("no notifier configured")
+		if a.sender != nil {
+			d := a.delivery
+			a.err = a.sender.Send(Message{Channel: d.Channel, To: d.To, Subject: d.Subject, Body: d.Body})
+		}
+		tried = append(tried, a)
+	}
+	return tried
+}
+
+// RecordDeliveries writes the outcome of SendDeliveries back to the delivery log and returns how
+// many attempts were recorded
+func (h *ExamCenterHandler) RecordDeliveries(tried []DeliveryAttempt, now time.Time) int {
+	recorded := 0
+	for _, a := range tried {
+		if a.index >= len(h.deliveries) {
+			continue
+		}
+		d := &h.deliveries[a.index]
+		if d.ID != a.delivery.ID || d.Status != DeliveryPending || d.Attempts != a.delivery.Attempts {
+			continue
+		}
+		recordDeliveryAttempt(d, a.err, now)
+		recorded++
+	}
+	return recorded
+}
+
+// recordDeliveryAttempt counts one attempt and schedules the next one on failure
+func recordDeliveryAttempt(d *Delivery, err error, now time.Time) {
+	d.Attempts++
+	if err == nil {
+		d.Status, d.SentAt, d.LastError = DeliverySent, now, ""
+		return
+	}
+	d.LastError = err.Error()
+	if d.Attempts >= MaxDeliveryAttempts {
+		d.Status = DeliveryFailed
+		return
+	}
+	d.NextAttempt = now.Add(deliveryBackoff[min(d.Attempts, len(deliveryBackoff))-1])
+}
+
+// daysUntil counts calendar days from now to a YYYY-MM-DD date; -1 if the date cannot be parsed
+func daysUntil(now time.Time, date string) int {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return -1
+	}
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return int(day.Sub(today).Hours() / 24)
+}
+
+// RunNotifications queues exam reminders that have become due and sends the messages whose time
+// has come, new ones and retries alike. It returns how many reminders were queued and how many
+// messages were tried. It sends while holding the handler, so the web server uses QueueReminders,
+// DueDeliveries, SendDeliveries and RecordDeliveries instead.
+func (h *ExamCenterHandler) RunNotifications(now time.Time) (reminders, sent int) {
+	reminders = h.QueueReminders(now)
+	return reminders, h.RecordDeliveries(SendDeliveries(h.DueDeliveries(now)), now)
+}
+
+// QueueReminders queues a reminder for every registration whose exam is at most ReminderLeadDays
+// away and that has not been reminded yet, and returns how many were queued
+func (h *ExamCenterHandler) QueueReminders(now time.Time) (reminders int) {
+	reminded := make(map[string]bool)
+	for _, d := range h.deliveries {
+		if d.Event == EventExamReminder {
+			reminded[d.RegistrationID] = true
+		}
+	}
+	for _, reg := range h.registrations {
+		if reminded[reg.ID] || (reg.Phone == "" && reg.Email == "") {
+			continue
+		}
+		if days := daysUntil(now, reg.ExamDate); days >= 0 && days <= ReminderLeadDays {
+			h.emit(Event{Kind: EventExamReminder, Time: now, Registration: reg})
+			reminders++
+		}
+	}
+	return reminders
+}
+
+// Deliveries returns up to limit delivery log entries, newest first. A limit of 0 returns all of them.
+func (h *ExamCenterHandler) Deliveries(limit int) []Delivery {
+	var list []Delivery
+	for i := len(h.deliveries) - 1; i >= 0; i-- {
+		if limit > 0 && len(list) == limit {
+			break
+		}
+		list = append(list, h.deliveries[i])
+	}
+	return list
+} 
\ No newline at end of file
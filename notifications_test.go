@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNotifyCandidateQueuesUntilRun(t *testing.T) {
+	h := NewExamCenterHandler()
+	n := &captureNotifier{}
+	h.SetNotifier(n)
+	reg := ExamRegistration{ID: "NEET-R1-1", StudentName: "Asha", Email: "asha@example.org", Phone: "9876543210", ExamType: ExamType{Code: "NEET"}}
+	now := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+	h.notifyCandidate(Event{Kind: EventRegistrationCreated, Time: now, Registration: reg})
+	if len(n.sent) != 0 || len(h.deliveries) != 2 {
+		t.Fatalf("after the event: %d sent, %d queued; want 0 sent, 2 queued", len(n.sent), len(h.deliveries))
+	}
+	if _, sent := h.RunNotifications(now); sent != 2 || len(n.sent) != 2 {
+		t.Fatalf("RunNotifications sent %d (%d delivered), want 2", sent, len(n.sent))
+	}
+	for _, d := range h.deliveries {
+		if d.Status != DeliverySent || d.Attempts != 1 {
+			t.Errorf("%s to %s: %s after %d attempts, want sent after 1", d.ID, d.To, d.Status, d.Attempts)
+		}
+	}
+}
+
+func TestRunNotificationsBacksOff(t *testing.T) {
+	h := NewExamCenterHandler()
+	n := &captureNotifier{err: errors.New("mail server down")}
+	h.SetNotifier(n)
+	now := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+	h.notifyCandidate(Event{Kind: EventRegistrationCreated, Time: now, Registration: ExamRegistration{ID: "NEET-R1-1", Email: "asha@example.org"}})
+	for i, wait := range deliveryBackoff {
+		if _, sent := h.RunNotifications(now); sent != 1 {
+			t.Fatalf("attempt %d: sent %d, want 1", i+1, sent)
+		}
+		if _, sent := h.RunNotifications(now.Add(wait - time.Second)); sent != 0 {
+			t.Fatalf("attempt %d: retried before the %v backoff passed", i+1, wait)
+		}
+		now = now.Add(wait)
+	}
+	h.RunNotifications(now)
+	if d := h.deliveries[0]; d.Status != DeliveryFailed || d.Attempts != MaxDeliveryAttempts || d.LastError != "mail server down" {
+		t.Errorf("delivery %s after %d attempts (%q), want failed after %d", d.Status, d.Attempts, d.LastError, MaxDeliveryAttempts)
+	}
+} 
\ No newline at end of file
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"fmt"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Message channels
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+)
+
+// Message is a notification to one candidate
+type Message struct {
+	Channel string // ChannelEmail or ChannelSMS
+	To      string // email address or 10-digit mobile number
+	Subject string // email only
+	Body    string
+}
+
+// Notifier delivers messages to candidates
+type Notifier interface {
+	Send(msg Message) error
+}
+
+// OutboxNotifier writes each message to a text file in Dir instead of sending it.
+// It is the default, so development and offline centers need no mail setup.
+type OutboxNotifier struct {
+	Dir string
+}
+
+// Send writes the message to the outbox directory
+func (n OutboxNotifier) Send(msg Message) error {
+	if err := os.MkdirAll(n.Dir, 0o755); err != nil {
+		return fmt.Errorf("error creating outbox: %v", err)
+	}
+	safeTo := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, msg.To)
+	name := fmt.Sprintf("%s-%s-%s.txt", time.Now().Format("20060102-150405.000000"), msg.Channel, safeTo)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Channel: %s\nTo: %s\n", msg.Channel, msg.To)
+	if msg.Subject != "" {
+		fmt.Fprintf(&b, "Subject: %s\n", msg.Subject)
+	}
+	fmt.Fprintf(&b, "\n%s\n", msg.Body)
+	if err := os.WriteFile(filepath.Join(n.Dir, name), []byte(b.String()), 0o600); err != nil {
+		return fmt.Errorf("error writing outbox message: %v", err)
+	}
+	return nil
+}
+
+// SMTPNotifier sends email through an SMTP server. SMS goes out as email to
+// <number>@SMSGateway when a gateway domain is set.
+type SMTPNotifier struct {
+	Addr       string // host:port
+	From       string
+	Username   string // optional; PLAIN auth needs TLS unless the server is on localhost
+	Password   string
+	SMSGateway string
+}
+
+// Send delivers the message by SMTP
+func (n SMTPNotifier) Send(msg Message) error {
+	to := msg.To
+	if msg.Channel == ChannelSMS {
+		if n.SMSGateway == "" {
+			return fmt.Errorf("cannot send SMS to %s: no SMS gateway configured", msg.To)
+		}
+		to = msg.To + "@" + n.SMSGateway
+	}
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("invalid message header")
+	}
+	var auth smtp.Auth
+	if n.Username != "" {
+		host, _, _ := strings.Cut(n.Addr, ":")
+		auth = smtp.PlainAuth("", n.Username, n.Password, host)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\nTo: %s\r\n", n.From, to)
+	if msg.Subject != "" {
+		fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	}
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	if err := smtp.SendMail(n.Addr, auth, n.From, []string{to}, []byte(b.String())); err != nil {
+		return fmt.Errorf("error sending mail to %s: %v", to, err)
+	}
+	return nil
+}
+
+// SetNotifier replaces how messages reach candidates
+func (h *ExamCenterHandler) SetNotifier(n Notifier) { h.notifier = n }
+
+// NormalizeContact classifies a phone number or email address and puts it in canonical form:
+// lower-case email, or the last 10 digits of an Indian mobile number.
+func NormalizeContact(s string) (channel, value string, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", "", fmt.Errorf("contact cannot be empty")
+	}
+	if strings.Contains(s, "@") {
+		at := strings.LastIndex(s, "@")
+		if at == 0 || at == len(s)-1 || strings.ContainsAny(s, " \t\r\n,;<>") {
+			return "", "", fmt.Errorf("'%s' is not a valid email address", s)
+		}
+		return ChannelEmail, strings.ToLower(s), nil
+	}
+	var digits strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' || r == '-' || r == ' ' || r == '(' || r == ')':
+		default:
+			return "", "", fmt.Errorf("'%s' is not a valid phone number", s)
+		}
+	}
+	d := digits.String()
+	if len(d) < 10 || len(d) > 12 {
+		return "", "", fmt.Errorf("'%s' is not a valid phone number", s)
+	}
+	return ChannelSMS, d[len(d)-10:], nil
+} 
\ No newline at end of file
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Login code limits
+const (
+	LoginCodeLifetime       = 10 * time.Minute
+	loginCodeResendInterval = time.Minute
+	maxLoginCodeAttempts    = 5
+)
+
+// ErrLoginCodeInvalid is returned for a wrong, expired or exhausted login code
+var ErrLoginCodeInvalid = errors.New("the code is wrong or has expired; request a new one")
+
+// loginChallenge is an outstanding one-time code. Only its hash is kept.
+type loginChallenge struct {
+	codeHash [sha256.Size]byte
+	sent     time.Time
+	expires  time.Time
+	attempts int
+}
+
+func loginCodeKey(roll, contact string) string { return roll + "|" + contact }
+
+func signupCodeKey(roll, contact string) string { return "signup|" + roll + "|" + contact }
+
+// RequestLoginCode sends a one-time sign-in code to a phone number or email address given on one
+// of the candidate's registrations. Unknown roll number and contact pairs get the same answer as
+// known ones, resend limit included, so the form cannot be used to find out who is registered.
+func (h *ExamCenterHandler) RequestLoginCode(roll, contact string) error {
+	roll = strings.TrimSpace(roll)
+	channel, value, err := NormalizeContact(contact)
+	if err != nil {
+		return err
+	}
+	if roll == "" {
+		return fmt.Errorf("roll number cannot be empty")
+	}
+	key := loginCodeKey(roll, value)
+	if err := h.codeAllowed(key, channel); err != nil {
+		return err
+	}
+	if !h.hasContact(roll, channel, value) {
+		h.holdCode(key)
+		return nil
+	}
+	err = h.sendCode(key, Message{Channel: channel, To: value, Subject: "Your ExamCenterHub sign-in code"}, func(code string) string {
+		return fmt.Sprintf("Your ExamCenterHub sign-in code for roll number %s is %s. It expires in %d minutes. Do not share it.", roll, code, int(LoginCodeLifetime.Minutes()))
+	})
+	if err != nil {
+		// Reporting the failure would tell the caller the pair is registered; they can ask again later
+		h.holdCode(key)
+	}
+	return nil
+}
+
+// VerifyLoginCode checks a code sent by RequestLoginCode and returns the candidate it signs in.
+// The session only reaches the registrations that list the verified contact.
+func (h *ExamCenterHandler) VerifyLoginCode(roll, contact, code string) (User, error) {
+	roll = strings.TrimSpace(roll)
+	_, value, err := NormalizeContact(contact)
+	if err != nil {
+		return User{}, err
+	}
+	if err := h.checkCode(loginCodeKey(roll, value), code); err != nil {
+		return User{}, err
+	}
+	return User{Username: roll, Role: RoleCandidate, RollNumber: roll, Contact: value}, nil
+}
+
+// RequestSignupCode sends a one-time code to the phone or email a candidate wants to sign up with,
+// which must be listed with the roll number on a registration or waitlist entry. Like RequestLoginCode
+// it answers unknown pairs the same as known ones. The account is only created once SignUpCandidate
+// sees the code, which proves the contact is theirs.
+func (h *ExamCenterHandler) RequestSignupCode(roll, contact string) error {
+	roll = strings.TrimSpace(roll)
+	channel, value, err := NormalizeContact(contact)
+	if err != nil {
+		return err
+	}
+	if roll == "" {
+		return fmt.Errorf("roll number cannot be empty")
+	}
+	key := signupCodeKey(roll, value)
+	if err := h.codeAllowed(key, channel); err != nil {
+		return err
+	}
+	if !h.hasContact(roll, channel, value) {
+		h.holdCode(key)
+		return nil
+	}
+	err = h.sendCode(key, Message{Channel: channel, To: value, Subject: "Confirm your ExamCenterHub account"}, func(code string) string {
+		return fmt.Sprintf("Your ExamCenterHub code to create an account for roll number %s is %s. It expires in %d minutes. Do not share it.", roll, code, int(LoginCodeLifetime.Minutes()))
+	})
+	if err != nil {
+		h.holdCode(key)
+	}
+	return nil
+}
+
+// SignUpCandidate checks a code sent by RequestSignupCode and creates a candidate account with the
+// chosen user name, bound to the roll number and the verified contact. Roll numbers are only unique
+// per exam, so the user name is the candidate's own choice rather than the roll number.
+func (h *ExamCenterHandler) SignUpCandidate(username, roll, contact, code, password string) (User, error) {
+	username, roll = strings.TrimSpace(username), strings.TrimSpace(roll)
+	channel, value, err := NormalizeContact(contact)
+	if err != nil {
+		return User{}, err
+	}
+	if username == "" {
+		return User{}, fmt.Errorf("choose a user name")
+	}
+	if _, taken := h.GetUser(username); taken {
+		return User{}, fmt.Errorf("user name '%s' is taken; choose another", username)
+	}
+	if len(password) < MinPasswordLength {
+		return User{}, fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+	if err := h.checkCode(signupCodeKey(roll, value), code); err != nil {
+		return User{}, err
+	}
+	if !h.hasContact(roll, channel, value) {
+		return User{}, fmt.Errorf("no registration or waitlist entry for roll number %s lists %s", roll, value)
+	}
+	u := User{Username: username, Role: RoleCandidate, RollNumber: roll, Contact: value}
+	if err := h.AddUser(username, u, password); err != nil {
+		return User{}, err
+	}
+	u, _ = h.GetUser(username)
+	return u, nil
+}
+
+// codeAllowed checks the resend limit for key and that codes can be delivered on the channel
+func (h *ExamCenterHandler) codeAllowed(key, channel string) error {
+	if prev, ok := h.loginCodes[key]; ok && time.Since(prev.sent) < loginCodeResendInterval {
+		return fmt.Errorf("a code was sent less than a minute ago; please wait before asking again")
+	}
+	if h.sender(channel) == nil {
+		return fmt.Errorf("no way to deliver one-time codes is configured")
+	}
+	return nil
+}
+
+// sendCode generates a code, sends it in msg with the body built around it and keeps its hash under key
+func (h *ExamCenterHandler) sendCode(key string, msg Message, body func(code string) string) error {
+	code, err := newCode()
+	if err != nil {
+		return err
+	}
+	msg.Body = body(code)
+	if err := h.sender(msg.Channel).Send(msg); err != nil {
+		return err
+	}
+	now := time.Now()
+	h.loginCodes[key] = loginChallenge{codeHash: sha256.Sum256([]byte(code)), sent: now, expires: now.Add(LoginCodeLifetime)}
+	return nil
+}
+
+// holdCode starts the resend interval for key with a code nobody was sent, so a pair that got no
+// message is indistinguishable from one that did
+func (h *ExamCenterHandler) holdCode(key string) {
+	code, _ := newCode()
+	now := time.Now()
+	h.loginCodes[key] = loginChallenge{codeHash: sha256.Sum256([]byte("unsent|" + code)), sent: now, expires: now.Add(LoginCodeLifetime)}
+}
+
+func newCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("error generating code: %v", err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// checkCode consumes the code kept under key, allowing a few wrong guesses
+func (h *ExamCenterHandler) checkCode(key, code string) error {
+	ch, ok := h.loginCodes[key]
+	if !ok || time.Now().After(ch.expires) {
+		delete(h.loginCodes, key)
+		return ErrLoginCodeInvalid
+	}
+	ch.attempts++
+	got := sha256.Sum256([]byte(strings.TrimSpace(code)))
+	if subtle.ConstantTimeCompare(got[:], ch.codeHash[:]) != 1 {
+		if ch.attempts >= maxLoginCodeAttempts {
+			delete(h.loginCodes, key)
+		} else {
+			h.loginCodes[key] = ch
+		}
+		return ErrLoginCodeInvalid
+	}
+	delete(h.loginCodes, key)
+	return nil
+}
+
+// hasContact reports whether a registration or waitlist entry for the roll number lists the contact
+func (h *ExamCenterHandler) hasContact(roll, channel, value string) bool {
+	matches := func(rollNumber, phone, email string) bool {
+		return rollNumber == roll && ((channel == ChannelEmail && email == value) || (channel == ChannelSMS && phone == value))
+	}
+	for _, reg := range h.registrations {
+		if matches(reg.RollNumber, reg.Phone, reg.Email) {
+			return true
+		}
+	}
+	for _, e := range h.waitlist {
+		if matches(e.Student.RollNumber, e.Student.Phone, e.Student.Email) {
+			return true
+		}
+	}
+	return false
+} 
\ No newline at end of file
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+// captureNotifier keeps every message instead of sending it
+type captureNotifier struct {
+	sent []Message
+	err  error
+}
+
+func (n *captureNotifier) Send(msg Message) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, msg)
+	return nil
+}
+
+var codePattern = regexp.MustCompile(`\b\d{6}\b`)
+
+// lastCode returns the code in the most recent message
+func (n *captureNotifier) lastCode(t *testing.T) string {
+	t.Helper()
+	if len(n.sent) == 0 {
+		t.Fatal("no message was sent")
+	}
+	code := codePattern.FindString(n.sent[len(n.sent)-1].Body)
+	if code == "" {
+		t.Fatalf("no code in %q", n.sent[len(n.sent)-1].Body)
+	}
+	return code
+}
+
+// sharedRollHandler has two registrations, for different exams, that share a roll number
+func sharedRollHandler() (*ExamCenterHandler, *captureNotifier) {
+	h := NewExamCenterHandler()
+	n := &captureNotifier{}
+	h.SetNotifier(n)
+	h.registrations = []ExamRegistration{
+		{ID: "NEET-R100-1", RollNumber: "R100", Email: "asha@example.org", ExamType: ExamType{Code: "NEET"}},
+		{ID: "JEE-R100-1", RollNumber: "R100", Phone: "9876543210", ExamType: ExamType{Code: "JEE"}},
+	}
+	return h, n
+}
+
+func TestVerifyLoginCodeScopesSessionToContact(t *testing.T) {
+	tests := []struct {
+		contact string
+		sees    map[string]bool
+	}{
+		{"Asha@Example.org", map[string]bool{"NEET-R100-1": true, "JEE-R100-1": false}},
+		{"+91 98765 43210", map[string]bool{"NEET-R100-1": false, "JEE-R100-1": true}},
+	}
+	for _, tt := range tests {
+		h, n := sharedRollHandler()
+		if err := h.RequestLoginCode("R100", tt.contact); err != nil {
+			t.Fatalf("%s: RequestLoginCode: %v", tt.contact, err)
+		}
+		u, err := h.VerifyLoginCode("R100", tt.contact, n.lastCode(t))
+		if err != nil {
+			t.Fatalf("%s: VerifyLoginCode: %v", tt.contact, err)
+		}
+		for _, reg := range h.registrations {
+			if got := u.CanSeeRegistration(reg); got != tt.sees[reg.ID] {
+				t.Errorf("%s: CanSeeRegistration(%s) = %v, want %v", tt.contact, reg.ID, got, tt.sees[reg.ID])
+			}
+		}
+	}
+}
+
+func TestVerifyLoginCodeRejectsWrongCodes(t *testing.T) {
+	h, n := sharedRollHandler()
+	if err := h.RequestLoginCode("R100", "asha@example.org"); err != nil {
+		t.Fatal(err)
+	}
+	code := n.lastCode(t)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	if _, err := h.VerifyLoginCode("R100", "9876543210", code); !errors.Is(err, ErrLoginCodeInvalid) {
+		t.Errorf("code checked against another contact: got %v, want ErrLoginCodeInvalid", err)
+	}
+	for i := 0; i < maxLoginCodeAttempts; i++ {
+		if _, err := h.VerifyLoginCode("R100", "asha@example.org", wrong); !errors.Is(err, ErrLoginCodeInvalid) {
+			t.Fatalf("attempt %d: got %v, want ErrLoginCodeInvalid", i+1, err)
+		}
+	}
+	if _, err := h.VerifyLoginCode("R100", "asha@example.org", code); !errors.Is(err, ErrLoginCodeInvalid) {
+		t.Errorf("right code after %d wrong attempts: got %v, want ErrLoginCodeInvalid", maxLoginCodeAttempts, err)
+	}
+}
+
+func TestRequestLoginCodeAnswersAlikeForUnknownPairs(t *testing.T) {
+	tests := []struct {
+		name        string
+		roll        string
+		contact     string
+		sendFails   bool
+		wantMessage bool
+	}{
+		{"registered", "R100", "asha@example.org", false, true},
+		{"unknown contact", "R100", "someone@example.org", false, false},
+		{"unknown roll", "R999", "asha@example.org", false, false},
+		{"send fails", "R100", "asha@example.org", true, false},
+	}
+	for _, tt := range tests {
+		h, n := sharedRollHandler()
+		if tt.sendFails {
+			n.err = errors.New("smtp down")
+		}
+		first := h.RequestLoginCode(tt.roll, tt.contact)
+		second := h.RequestLoginCode(tt.roll, tt.contact)
+		if first != nil {
+			t.Errorf("%s: first request: %v, want nil", tt.name, first)
+		}
+		if second == nil {
+			t.Errorf("%s: second request within a minute was accepted", tt.name)
+		}
+		if got := len(n.sent) > 0; got != tt.wantMessage {
+			t.Errorf("%s: message sent = %v, want %v", tt.name, got, tt.wantMessage)
+		}
+	}
+}
+
+func TestSignUpCandidateBindsVerifiedContact(t *testing.T) {
+	h, n := sharedRollHandler()
+	if err := h.RequestSignupCode("R100", "9876543210"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.SignUpCandidate("R100", "R100", "9876543210", "not-it", "longenough"); !errors.Is(err, ErrLoginCodeInvalid) {
+		t.Fatalf("wrong code: got %v, want ErrLoginCodeInvalid", err)
+	}
+	u, err := h.SignUpCandidate("ravi-jee", "R100", "9876543210", n.lastCode(t), "longenough")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Username != "ravi-jee" || u.Contact != "9876543210" || u.RollNumber != "R100" {
+		t.Errorf("account = %+v, want ravi-jee for roll R100 bound to 9876543210", u)
+	}
+	if u.CanSeeRegistration(h.registrations[0]) || !u.CanSeeRegistration(h.registrations[1]) {
+		t.Error("account should only see the registration listing its verified contact")
+	}
+	if err := h.AddUser("admin", User{Username: "R101", Role: RoleCandidate}, "longenough"); err == nil {
+		t.Error("candidate account without a contact was created")
+	}
+}
+func TestSignUpNeedsARegistrationOrWaitlistEntry(t *testing.T) {
+	tests := []struct {
+		name, roll, contact string
+		wantMessage         bool
+	}{
+		{"registration", "R100", "asha@example.org", true},
+		{"waitlist entry", "R200", "meera@example.org", true},
+		{"contact of another roll", "R100", "meera@example.org", false},
+		{"unknown roll", "R999", "asha@example.org", false},
+	}
+	for _, tt := range tests {
+		h, n := sharedRollHandler()
+		h.waitlist = []WaitlistEntry{{ID: "WL-1", Student: StudentInfo{RollNumber: "R200", Email: "meera@example.org"}}}
+		if err := h.RequestSignupCode(tt.roll, tt.contact); err != nil {
+			t.Fatalf("%s: request: %v, want the same answer as a known pair", tt.name, err)
+		}
+		if got := len(n.sent) > 0; got != tt.wantMessage {
+			t.Fatalf("%s: message sent = %v, want %v", tt.name, got, tt.wantMessage)
+		}
+		if !tt.wantMessage {
+			if _, err := h.SignUpCandidate("someone", tt.roll, tt.contact, "000000", "longenough"); err == nil {
+				t.Errorf("%s: account created without a code", tt.name)
+			}
+			continue
+		}
+		if _, err := h.SignUpCandidate("someone", tt.roll, tt.contact, n.lastCode(t), "longenough"); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+	}
+}
+
+func TestSignUpSharedRollGetsSeparateAccounts(t *testing.T) {
+	h, n := sharedRollHandler()
+	if err := h.RequestSignupCode("R100", "asha@example.org"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.SignUpCandidate("asha", "R100", "asha@example.org", n.lastCode(t), "longenough"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.RequestSignupCode("R100", "9876543210"); err != nil {
+		t.Fatal(err)
+	}
+	code := n.lastCode(t)
+	if _, err := h.SignUpCandidate("asha", "R100", "9876543210", code, "longenough"); err == nil {
+		t.Fatal("a second candidate took the same user name")
+	}
+	// A taken user name does not use up the code
+	if _, err := h.SignUpCandidate("ravi", "R100", "9876543210", code, "longenough"); err != nil {
+		t.Fatal(err)
+	}
+	for username, sees := range map[string]string{"asha": "NEET-R100-1", "ravi": "JEE-R100-1"} {
+		u, err := h.Authenticate(username, "longenough")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, reg := range h.registrations {
+			if got := u.CanSeeRegistration(reg); got != (reg.ID == sees) {
+				t.Errorf("%s sees %s = %v", username, reg.ID, got)
+			}
+		}
+	}
+} 
\ No newline at end of file
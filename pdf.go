@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size in PDF points
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+)
+
+// pdfPage collects drawing operations for a single-page PDF using the standard Helvetica fonts
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+// text draws s with its baseline starting at (x, y)
+func (p *pdfPage) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfEscape(s))
+}
+
+// rect fills a rectangle with the current fill colour
+func (p *pdfPage) rect(x, y, w, h float64) {
+	fmt.Fprintf(&p.content, "%.2f %.2f %.2f %.2f re f\n", x, y, w, h)
+}
+
+// strokeRect outlines a rectangle
+func (p *pdfPage) strokeRect(x, y, w, h float64) {
+	fmt.Fprintf(&p.content, "%.2f %.2f %.2f %.2f re S\n", x, y, w, h)
+}
+
+// line draws a straight line
+func (p *pdfPage) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "%.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// gray sets both fill and stroke colour to a grey level between 0 (black) and 1 (white)
+func (p *pdfPage) gray(level float64) {
+	fmt.Fprintf(&p.content, "%.2f g %.2f G\n", level, level)
+}
+
+// qr draws a QR code with its bottom-left corner at (x, y) and the given side length, including a quiet zone
+func (p *pdfPage) qr(q *qrCode, x, y, side float64) {
+	const quiet = 4
+	module := side / float64(q.size+2*quiet)
+	for row := 0; row < q.size; row++ {
+		top := y + side - float64(row+quiet+1)*module
+		for col := 0; col < q.size; {
+			if !q.Dark(col, row) {
+				col++
+				continue
+			}
+			start := col
+			for col < q.size && q.Dark(col, row) {
+				col++
+			}
+			p.rect(x+float64(start+quiet)*module, top, float64(col-start)*module, module)
+		}
+	}
+}
+
+// bytes renders the page as a complete PDF document
+func (p *pdfPage) bytes() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", pdfPageWidth, pdfPageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()),
+	}
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// pdfEscape escapes a string literal; characters outside ASCII are replaced as the base fonts cannot show them
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '–' || r == '—':
+			b.WriteByte('-')
+		case r < 32 || r > 126:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+} 
\ No newline at end of file
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestPDFEscape(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Pune University Center", "Pune University Center"},
+		{`Hall (A) \ B`, `Hall \(A\) \\ B`},
+		{"09:30–12:30 — morning", "09:30-12:30 - morning"},
+		{"Pūne\tनगर", "P?ne????"},
+	}
+	for _, tt := range tests {
+		if got := pdfEscape(tt.in); got != tt.want {
+			t.Errorf("pdfEscape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateAdmitCard(t *testing.T) {
+	h, _ := admitCardHandler(t)
+	ex, err := h.ExamEdition("UPSC", 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.registrations = append(h.registrations, ExamRegistration{ID: "UPSC-2025-U1-1", StudentName: "Asha (Ash) Verma", RollNumber: "U1",
+		StudentCity: "Pune", ExamType: ex, ExamDate: "2025-05-25", TimeSlot: "09:30-12:30",
+		AssignedCenter: "Pune University Center", AssignedCity: "Pune", Room: "Hall A", SeatNumber: "A1"})
+
+	doc, err := h.GenerateAdmitCard("UPSC-2025-U1-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Fatalf("not a PDF document: %q ... %q", doc[:min(16, len(doc))], doc[max(0, len(doc)-16):])
+	}
+	for _, text := range []string{"(UPSC-2025-U1-1)", `(Asha \(Ash\) Verma)`, "(Pune University Center)", "(Hall A / A1)", "(2025-05-25)"} {
+		if !bytes.Contains(doc, []byte(text)) {
+			t.Errorf("card is missing %s", text)
+		}
+	}
+	if _, err := h.GenerateAdmitCard("UPSC-2025-U9-1"); err == nil {
+		t.Error("card for an unknown registration, want an error")
+	}
+
+	// every cross-reference entry points at the start of its object, and startxref at the table
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(doc)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(doc[xref:], []byte("xref\n0 7\n")) {
+		t.Fatalf("startxref %d does not point at the table", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(doc[xref:], -1)
+	if len(entries) != 6 {
+		t.Fatalf("%d cross-reference entries, want 6", len(entries))
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(doc[off:], []byte(want)) {
+			t.Errorf("object %d: offset %d points at %q", i+1, off, doc[off:min(off+10, len(doc))])
+		}
+	}
+} 
\ No newline at end of file
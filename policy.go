@@ -0,0 +1,563 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// An allocation policy is the rule file of an exam type, read by the allocation engine below:
+//   - filters exclude centers and are checked in the order listed; the first that fails is the reason given
+//   - prefer keys order the remaining centers ahead of their score, e.g. home state first
+//   - weights score each center from distance, estimated travel time and fill rate; lower is better
+//   - tie-breakers order centers with equal scores; centers still equal keep their listed order
+//   - quotas cap how many candidates of one exam edition a center or a city takes
+//
+// Cities are offered in the order of their best center, at most the exam's MaxCenters of them, and a
+// candidate is registered at the first center offered. Exams without a policy of their own use
+// DefaultPolicy, which allocates as before policies existed.
+
+// Filter rules
+const (
+	FilterHomeCity      = "home_city"      // the exam's home city policy
+	FilterAllocation    = "allocation"     // the exam's allocation rule (state or zone)
+	FilterMaxDistance   = "max_distance"   // km, or the candidate's own limit when km is 0
+	FilterMaxTravel     = "max_travel"     // estimated travel time in hours, see EstimateTravel
+	FilterExcludeCities = "exclude_cities" // never these cities
+	FilterAccess        = "access"         // accessible centers and scribes for candidates who need them
+	FilterHasSeats      = "has_seats"      // seats open to the candidate, see categories.go
+)
+
+// Exclusions that are not filters of the policy
+const (
+	RuleDisabled   = "disabled"    // the city or center is disabled
+	RuleClosed     = "closed"      // the center is closed for the sitting, see closures.go
+	RuleQuota      = "quota"       // a quota of the policy is used up
+	RuleMaxCenters = "max_centers" // the city came after the exam's MaxCenters cities
+)
+
+// Prefer keys
+const (
+	PreferWomenRadius    = "women_radius"    // for women, cities within the exam's WomenRadius first
+	PreferAllocationRank = "allocation_rank" // home state first under same_state_first
+)
+
+// Tie-breakers
+const (
+	TieFillPercent = "fill_percent" // emptier center first
+	TieFreeSeats   = "free_seats"   // more free seats first
+	TieDistance    = "distance"     // nearer first
+	TieName        = "name"         // alphabetical
+)
+
+// Quota scopes
+const (
+	QuotaCenter = "center"
+	QuotaCity   = "city"
+)
+
+// AllocationPolicy is the declarative allocation rule file of an exam type
+type AllocationPolicy struct {
+	Exam        string         `json:"exam"`
+	Filters     []PolicyFilter `json:"filters"`
+	Prefer      []string       `json:"prefer"`
+	Weights     PolicyWeights  `json:"weights"`
+	TieBreakers []string       `json:"tie_breakers"`
+	Quotas      []PolicyQuota  `json:"quotas,omitempty"`
+}
+
+// PolicyFilter is one filter rule and its parameters
+type PolicyFilter struct {
+	Rule   string   `json:"rule"`
+	Km     float64  `json:"km,omitempty"`     // max_distance
+	Hours  float64  `json:"hours,omitempty"`  // max_travel
+	Cities []string `json:"cities,omitempty"` // exclude_cities
+}
+
+// PolicyWeights turn a center's distance, travel time and fill rate into its score
+type PolicyWeights struct {
+	DistanceKm  float64 `json:"distance_km"`
+	TravelHours float64 `json:"travel_hours"`
+	FillPercent float64 `json:"fill_percent"`
+}
+
+// PolicyQuota caps the candidates of one exam edition at each center or city, or at the one named
+type PolicyQuota struct {
+	Per        string  `json:"per"`
+	Name       string  `json:"name,omitempty"`
+	Max        int     `json:"max,omitempty"`
+	MaxPercent float64 `json:"max_percent,omitempty"` // of the total seats
+}
+
+// DefaultPolicy is the policy of exams that have none of their own
+func DefaultPolicy(code string) AllocationPolicy {
+	return AllocationPolicy{
+		Exam:        code,
+		Filters:     []PolicyFilter{{Rule: FilterHomeCity}, {Rule: FilterAllocation}, {Rule: FilterMaxDistance}, {Rule: FilterAccess}, {Rule: FilterHasSeats}},
+		Prefer:      []string{PreferWomenRadius, PreferAllocationRank},
+		Weights:     PolicyWeights{DistanceKm: 1},
+		TieBreakers: []string{},
+	}
+}
+
+// ParseAllocationPolicy reads a rule file. Unknown fields are errors so that a misspelt rule is not ignored.
+func ParseAllocationPolicy(r io.Reader) (AllocationPolicy, error) {
+	var p AllocationPolicy
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return p, fmt.Errorf("error reading policy: %v", err)
+	}
+	return p, nil
+}
+
+// CheckAllocationPolicy validates a policy and puts its exam, city and center names in canonical form
+func (h *ExamCenterHandler) CheckAllocationPolicy(p *AllocationPolicy) error {
+	p.Exam = strings.ToUpper(strings.TrimSpace(p.Exam))
+	if len(h.ExamEditions(p.Exam)) == 0 {
+		return fmt.Errorf("exam type '%s' not found", p.Exam)
+	}
+	seen := make(map[string]bool)
+	for i, f := range p.Filters {
+		switch f.Rule {
+		case FilterHomeCity, FilterAllocation, FilterAccess, FilterHasSeats:
+		case FilterMaxDistance:
+			if f.Km < 0 {
+				return fmt.Errorf("max_distance km cannot be negative")
+			}
+		case FilterMaxTravel:
+			if f.Hours <= 0 {
+				return fmt.Errorf("max_travel needs a number of hours")
+			}
+		case FilterExcludeCities:
+			if len(f.Cities) == 0 {
+				return fmt.Errorf("exclude_cities needs a list of cities")
+			}
+			for j, name := range f.Cities {
+				city, ok := h.findCity(name)
+				if !ok {
+					return fmt.Errorf("exclude_cities: city '%s' not found", name)
+				}
+				p.Filters[i].Cities[j] = city.Name
+			}
+		default:
+			return fmt.Errorf("unknown filter rule '%s'", f.Rule)
+		}
+		if seen[f.Rule] {
+			return fmt.Errorf("filter rule '%s' is listed twice", f.Rule)
+		}
+		seen[f.Rule] = true
+	}
+	// Without these a candidate could be seated at a full center or one they cannot get into
+	for _, rule := range []string{FilterAccess, FilterHasSeats} {
+		if !seen[rule] {
+			return fmt.Errorf("the %s filter cannot be left out", rule)
+		}
+	}
+	if err := checkKeys("prefer key", p.Prefer, PreferWomenRadius, PreferAllocationRank); err != nil {
+		return err
+	}
+	if err := checkKeys("tie-breaker", p.TieBreakers, TieFillPercent, TieFreeSeats, TieDistance, TieName); err != nil {
+		return err
+	}
+	if w := p.Weights; w.DistanceKm < 0 || w.TravelHours < 0 || w.FillPercent < 0 {
+		return fmt.Errorf("weights cannot be negative")
+	}
+	for i, q := range p.Quotas {
+		switch q.Per {
+		case QuotaCenter:
+			if q.Name != "" {
+				c, ok := h.findCenter(q.Name)
+				if !ok {
+					return fmt.Errorf("quota: exam center '%s' not found", q.Name)
+				}
+				p.Quotas[i].Name = c.Name
+			}
+		case QuotaCity:
+			if q.Name != "" {
+				city, ok := h.findCity(q.Name)
+				if !ok {
+					return fmt.Errorf("quota: city '%s' not found", q.Name)
+				}
+				p.Quotas[i].Name = city.Name
+			}
+		default:
+			return fmt.Errorf("quota per '%s' must be center or city", q.Per)
+		}
+		if (q.Max > 0) == (q.MaxPercent > 0) || q.Max < 0 || q.MaxPercent < 0 || q.MaxPercent > 100 {
+			return fmt.Errorf("a quota needs either max or max_percent (up to 100)")
+		}
+	}
+	return nil
+}
+
+// checkKeys reports the first key that is unknown or repeated
+func checkKeys(kind string, keys []string, known ...string) error {
+	seen := make(map[string]bool)
+	for _, k := range keys {
+		if !hasString(known, k) {
+			return fmt.Errorf("unknown %s '%s' (use %s)", kind, k, strings.Join(known, ", "))
+		}
+		if seen[k] {
+			return fmt.Errorf("%s '%s' is listed twice", kind, k)
+		}
+		seen[k] = true
+	}
+	return nil
+}
+
+func hasString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// AllocationPolicyFor returns the policy of an exam type and whether it is the exam's own rather than the default
+func (h *ExamCenterHandler) AllocationPolicyFor(code string) (AllocationPolicy, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if p, ok := h.policies[code]; ok {
+		return p, true
+	}
+	return DefaultPolicy(code), false
+}
+
+// SetAllocationPolicy checks and stores the policy of an exam type. Waiting candidates are placed if
+// the new rules give them a seat.
+func (h *ExamCenterHandler) SetAllocationPolicy(actor string, p AllocationPolicy) error {
+	if err := h.CheckAllocationPolicy(&p); err != nil {
+		return err
+	}
+	_, custom := h.policies[p.Exam]
+	h.policies[p.Exam] = p
+	h.PromoteWaitlist()
+	action := "policy.add"
+	if custom {
+		action = "policy.update"
+	}
+	details := fmt.Sprintf("%d filters, %d quotas", len(p.Filters), len(p.Quotas))
+	return h.commit(actor, action, p.Exam, details)
+}
+
+// renameQuotaTargets points the quotas that cap the center or city (per) called name at its new name,
+// in every exam's policy. Changed quota lists are copied, as planning copies share them.
+func (h *ExamCenterHandler) renameQuotaTargets(per, name, newName string) {
+	for code, p := range h.policies {
+		var quotas []PolicyQuota
+		for i, q := range p.Quotas {
+			if q.Per != per || q.Name != name {
+				continue
+			}
+			if quotas == nil {
+				quotas = append([]PolicyQuota(nil), p.Quotas...)
+			}
+			quotas[i].Name = newName
+		}
+		if quotas != nil {
+			p.Quotas = quotas
+			h.policies[code] = p
+		}
+	}
+}
+
+// ResetAllocationPolicy returns an exam type to the default policy
+func (h *ExamCenterHandler) ResetAllocationPolicy(actor, code string) error {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if _, ok := h.policies[code]; !ok {
+		return fmt.Errorf("%s already uses the default policy", code)
+	}
+	delete(h.policies, code)
+	h.PromoteWaitlist()
+	return h.commit(actor, "policy.reset", code, "")
+}
+
+// CenterVerdict is what the engine decided about one center for one candidate
+type CenterVerdict struct {
+	Center   ExamCenter
+	City     string
+	Distance float64
+	Travel   time.Duration
+	FillPct  float64
+	Score    float64
+	Rank     int    // 1-based place among the centers offered; 0 when excluded
+	Behind   string // why the center ranks below the first choice
+	Rule     string // the filter rule or other exclusion (RuleDisabled, RuleQuota, RuleMaxCenters); empty when offered
+	Reason   string
+}
+
+// Allocation is the engine's result for one candidate
+type Allocation struct {
+	Policy   AllocationPolicy
+	Nearest  []CityDistance  // cities offered, best first, each with its centers best first
+	Verdicts []CenterVerdict // the centers offered in rank order, then the excluded ones by distance
+	offers   []offer         // the centers offered in rank order, for comparing any two of them
+}
+
+// ExplainAllocation runs a policy for a candidate without registering anyone, so a rule file can be
+// tried before it is saved
+func (h *ExamCenterHandler) ExplainAllocation(p AllocationPolicy, homeCity string, ex ExamType, prefs StudentPreference) (Allocation, error) {
+	return h.allocate(p, homeCity, ex, prefs)
+}
+
+// firstSitting is the date and slot a new registration is given unless it clashes with the candidate's other exams
+func firstSitting(ex ExamType) (string, string) {
+	slot := ""
+	if len(ex.Schedule.TimeSlots) > 0 {
+		slot = ex.Schedule.TimeSlots[0]
+	}
+	return ex.Schedule.StartDate, slot
+}
+
+// offer is a center that passed every filter, with its sort keys
+type offer struct {
+	verdict CenterVerdict
+	city    City
+	near    bool // within the women's radius
+	rank    int  // allocation rank
+	free    int
+}
+
+// allocate is the allocation engine, run for the exam's first sitting
+func (h *ExamCenterHandler) allocate(p AllocationPolicy, homeCity string, ex ExamType, prefs StudentPreference) (Allocation, error) {
+	date, slot := firstSitting(ex)
+	return h.allocateAt(p, homeCity, ex, prefs, date, slot)
+}
+
+// allocateAt runs the allocation engine for a candidate sitting the exam on date in slot
+func (h *ExamCenterHandler) allocateAt(p AllocationPolicy, homeCity string, ex ExamType, prefs StudentPreference, date, slot string) (Allocation, error) {
+	return h.allocateUsing(p, homeCity, ex, prefs, date, slot, h.quotaUse(p, ex))
+}
+
+// allocateUsing is allocateAt with the quota use already counted, see quotaUse, so that a caller placing
+// many candidates can keep the counts up to date itself instead of recounting the registrations
+func (h *ExamCenterHandler) allocateUsing(p AllocationPolicy, homeCity string, ex ExamType, prefs StudentPreference, date, slot string, used map[string]int) (Allocation, error) {
+	home, ok := h.cities[homeCity]
+	if !ok {
+		return Allocation{}, fmt.Errorf("home city '%s' not found", homeCity)
+	}
+	res := Allocation{Policy: p}
+	var offers []offer
+	var excluded []CenterVerdict
+	for _, city := range h.ListCities() {
+		km := h.calculateDistance(home, city)
+		rank, _ := ex.allocationRank(home, city)
+		for _, c := range h.examCenters[city.Name] {
+			v := CenterVerdict{Center: c, City: city.Name, Distance: km, Travel: EstimateTravel(km), FillPct: h.fillPercent(c.Name)}
+			if v.Rule, v.Reason = h.exclusion(p, ex, prefs, home, city, c, km, used, date, slot); v.Rule != "" {
+				excluded = append(excluded, v)
+				continue
+			}
+			o := offer{verdict: v, city: city, near: withinWomenRadius(ex, prefs, km), rank: rank}
+			h.score(p, &o, prefs)
+			offers = append(offers, o)
+		}
+	}
+	sort.SliceStable(offers, func(i, j int) bool {
+		order, _ := p.compare(offers[i], offers[j])
+		return order < 0
+	})
+
+	index := make(map[string]int) // city name -> position in res.Nearest
+	for n, o := range offers {
+		v := o.verdict
+		if n > 0 {
+			if _, v.Behind = p.compare(offers[0], o); v.Behind == "" {
+				v.Behind = "listed later in its city"
+			}
+		}
+		i, seen := index[v.City]
+		if !seen {
+			if ex.MaxCenters > 0 && len(res.Nearest) >= ex.MaxCenters {
+				v.Rule, v.Reason = RuleMaxCenters, fmt.Sprintf("%s offers %d cities and this one ranks lower: %s", ex.Label(), ex.MaxCenters, v.Behind)
+				excluded = append(excluded, v)
+				continue
+			}
+			i = len(res.Nearest)
+			index[v.City] = i
+			res.Nearest = append(res.Nearest, CityDistance{City: o.city, Distance: v.Distance})
+		}
+		res.Nearest[i].Centers = append(res.Nearest[i].Centers, v.Center)
+		v.Rank = len(res.Verdicts) + 1
+		res.Verdicts = append(res.Verdicts, v)
+		o.verdict = v
+		res.offers = append(res.offers, o)
+	}
+	sort.SliceStable(excluded, func(i, j int) bool { return excluded[i].Distance < excluded[j].Distance })
+	res.Verdicts = append(res.Verdicts, excluded...)
+	return res, nil
+}
+
+// compare orders two offered centers by the policy's prefer keys, score and tie-breakers. It returns a
+// negative number when a comes first and a positive one when b does, with why the other one is behind;
+// 0 means the policy cannot tell them apart.
+func (p AllocationPolicy) compare(a, b offer) (int, string) {
+	first := func(aFirst bool) int {
+		if aFirst {
+			return -1
+		}
+		return 1
+	}
+	for _, key := range p.Prefer {
+		switch {
+		case key == PreferWomenRadius && a.near != b.near:
+			return first(a.near), "outside the women's radius"
+		case key == PreferAllocationRank && a.rank != b.rank:
+			return first(a.rank < b.rank), "outside the home state, which is offered first"
+		}
+	}
+	if sa, sb := a.verdict.Score, b.verdict.Score; sa != sb {
+		return first(sa < sb), fmt.Sprintf("score %.1f against %.1f", max(sa, sb), min(sa, sb))
+	}
+	for _, key := range p.TieBreakers {
+		switch {
+		case key == TieFillPercent && a.verdict.FillPct != b.verdict.FillPct:
+			return first(a.verdict.FillPct < b.verdict.FillPct), "same score but fuller"
+		case key == TieFreeSeats && a.free != b.free:
+			return first(a.free > b.free), "same score but fewer free seats"
+		case key == TieDistance && a.verdict.Distance != b.verdict.Distance:
+			return first(a.verdict.Distance < b.verdict.Distance), "same score but farther"
+		case key == TieName && a.verdict.Center.Name != b.verdict.Center.Name:
+			return first(a.verdict.Center.Name < b.verdict.Center.Name), "same score, later by name"
+		}
+	}
+	return 0, ""
+}
+
+// fillPercent is how much of a center is booked
+func (h *ExamCenterHandler) fillPercent(center string) float64 {
+	capInfo := h.centerCapacity[center]
+	if capInfo.TotalSeats == 0 {
+		return 0
+	}
+	return float64(capInfo.BookedSeats) / float64(capInfo.TotalSeats) * 100
+}
+
+// score works out an offer's score and free seats from how full its center is now
+func (h *ExamCenterHandler) score(p AllocationPolicy, o *offer, prefs StudentPreference) {
+	v := &o.verdict
+	v.FillPct = h.fillPercent(v.Center.Name)
+	v.Score = p.Weights.DistanceKm*v.Distance + p.Weights.TravelHours*v.Travel.Hours() + p.Weights.FillPercent*v.FillPct
+	o.free = h.freeSeatsFor(v.Center, prefs)
+}
+
+// exclusion returns the rule that keeps a center from the candidate and why, or "" when it can be offered
+func (h *ExamCenterHandler) exclusion(p AllocationPolicy, ex ExamType, prefs StudentPreference, home, city City, c ExamCenter, km float64, used map[string]int, date, slot string) (string, string) {
+	if city.Disabled {
+		return RuleDisabled, "the city is disabled"
+	}
+	if c.Disabled {
+		return RuleDisabled, "the center is disabled"
+	}
+	if cl, ok := h.closureFor(c.Name, date, slot); ok {
+		return RuleClosed, cl.Describe()
+	}
+	for _, f := range p.Filters {
+		if reason := h.filterReason(f, ex, prefs, home, city, c, km); reason != "" {
+			return f.Rule, reason
+		}
+	}
+	for _, q := range p.Quotas {
+		if reason := h.quotaReason(q, ex, c, used); reason != "" {
+			return RuleQuota, reason
+		}
+	}
+	return "", ""
+}
+
+// filterReason says why a filter excludes a center, or returns "" when it passes
+func (h *ExamCenterHandler) filterReason(f PolicyFilter, ex ExamType, prefs StudentPreference, home, city City, c ExamCenter, km float64) string {
+	switch f.Rule {
+	case FilterHomeCity:
+		if h.excludesHome(ex, home.Name, city.Name) {
+			if city.Name == home.Name {
+				return "the candidate's home city"
+			}
+			return fmt.Sprintf("in the candidate's metro cluster (%s)", h.clusterOf(city.Name))
+		}
+	case FilterAllocation:
+		if _, ok := ex.allocationRank(home, city); !ok {
+			if ex.Allocation == AllocateWithinZone {
+				return fmt.Sprintf("outside the candidate's %s zone", ex.Zone(home.State))
+			}
+			return fmt.Sprintf("outside %s", home.State)
+		}
+	case FilterMaxDistance:
+		limit := f.Km
+		if limit == 0 {
+			limit = prefs.MaxDistance
+		}
+		if limit > 0 && km > limit {
+			return fmt.Sprintf("%.0f km, over the %.0f km limit", km, limit)
+		}
+	case FilterMaxTravel:
+		if t := EstimateTravel(km); t.Hours() > f.Hours {
+			return fmt.Sprintf("about %s of travel, over %g hours", roughDuration(t), f.Hours)
+		}
+	case FilterExcludeCities:
+		if hasString(f.Cities, city.Name) {
+			return "the city is excluded by the policy"
+		}
+	case FilterAccess:
+		if prefs.NeedsAccessible() && !c.Accessible {
+			return "not accessible"
+		}
+		if prefs.NeedsScribe && !c.Scribes {
+			return "cannot seat scribes"
+		}
+	case FilterHasSeats:
+		if capInfo, ok := h.centerCapacity[c.Name]; ok && h.freeSeatsFor(c, prefs) <= 0 {
+			if capInfo.AvailableSeats > 0 {
+				return "full; the remaining seats are held for priority candidates"
+			}
+			return "full"
+		}
+	}
+	return ""
+}
+
+// quotaUse counts the registrations of the exam edition per center and per city when the policy has quotas
+func (h *ExamCenterHandler) quotaUse(p AllocationPolicy, ex ExamType) map[string]int {
+	if len(p.Quotas) == 0 {
+		return nil
+	}
+	used := make(map[string]int)
+	for _, reg := range h.registrations {
+		if reg.ExamType.SameEdition(ex) {
+			countQuotaUse(used, reg.AssignedCenter, reg.AssignedCity)
+		}
+	}
+	return used
+}
+
+// countQuotaUse adds one candidate seated at center in city to the quota use
+func countQuotaUse(used map[string]int, center, city string) {
+	used[QuotaCenter+":"+center]++
+	used[QuotaCity+":"+city]++
+}
+
+// quotaReason says why a quota excludes a center, or returns "" when there is room under it
+func (h *ExamCenterHandler) quotaReason(q PolicyQuota, ex ExamType, c ExamCenter, used map[string]int) string {
+	name, total := c.Name, h.centerCapacity[c.Name].TotalSeats
+	if q.Per == QuotaCity {
+		name, total = c.City, 0
+		for _, cc := range h.examCenters[c.City] {
+			total += h.centerCapacity[cc.Name].TotalSeats
+		}
+	}
+	if q.Name != "" && q.Name != name {
+		return ""
+	}
+	limit := q.Max
+	if q.MaxPercent > 0 {
+		limit = int(q.MaxPercent / 100 * float64(total))
+	}
+	if used[q.Per+":"+name] >= limit {
+		return fmt.Sprintf("the %s quota of %d for %s is used up", q.Per, limit, ex.Label())
+	}
+	return ""
+} 
\ No newline at end of file
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// allocationHandler allocates UPSC from Pune, where three centers sit at the same distance
+func allocationHandler(t *testing.T) (*ExamCenterHandler, ExamType) {
+	t.Helper()
+	h := NewExamCenterHandler()
+	return h, includeHomeCity(t, h, "UPSC")
+}
+
+// includeHomeCity opts an exam into sitting in the candidate's home city, so a Pune candidate is
+// offered the Pune centers first
+func includeHomeCity(t *testing.T, h *ExamCenterHandler, code string) ExamType {
+	t.Helper()
+	for i := range h.exams {
+		if h.exams[i].Code == code {
+			h.exams[i].HomeCity = HomeCityInclude
+		}
+	}
+	exam, err := h.GetExamTypeDetails(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return exam
+}
+
+func TestAllocationPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy func(p *AllocationPolicy)
+		setup  func(h *ExamCenterHandler, ex ExamType)
+		first  string
+		rules  map[string]string // center -> the rule that excludes it; "" when offered
+	}{
+		{
+			name:  "default",
+			first: "Pune University Center",
+			rules: map[string]string{"Shivaji Nagar Exam Hall": "", "Navi Mumbai Central Exam Center": "", "Kalyan Central Exam Center": RuleMaxCenters, "Aurangabad Central Exam Center": FilterMaxDistance},
+		},
+		{
+			name: "excluded city",
+			policy: func(p *AllocationPolicy) {
+				p.Filters = append(p.Filters, PolicyFilter{Rule: FilterExcludeCities, Cities: []string{"Pune"}})
+			},
+			first: "Navi Mumbai Central Exam Center",
+			rules: map[string]string{"Pune University Center": FilterExcludeCities, "Kalyan Central Exam Center": ""},
+		},
+		{
+			name:   "policy distance limit",
+			policy: func(p *AllocationPolicy) { p.Filters[2].Km = 50 },
+			first:  "Pune University Center",
+			rules:  map[string]string{"Kothrud Sports Complex": "", "Navi Mumbai Central Exam Center": FilterMaxDistance},
+		},
+		{
+			name:   "fill percent tie-breaker",
+			policy: func(p *AllocationPolicy) { p.TieBreakers = []string{TieFillPercent} },
+			setup: func(h *ExamCenterHandler, ex ExamType) {
+				c := h.centerCapacity["Kothrud Sports Complex"]
+				c.AvailableSeats, c.BookedSeats = c.TotalSeats, 0
+				h.centerCapacity["Kothrud Sports Complex"] = c
+			},
+			first: "Kothrud Sports Complex",
+		},
+		{
+			name:   "name tie-breaker",
+			policy: func(p *AllocationPolicy) { p.TieBreakers = []string{TieName} },
+			first:  "Kothrud Sports Complex",
+		},
+		{
+			name: "full center",
+			setup: func(h *ExamCenterHandler, ex ExamType) {
+				h.centerCapacity["Pune University Center"] = CenterCapacity{TotalSeats: 100, BookedSeats: 100}
+			},
+			first: "Shivaji Nagar Exam Hall",
+			rules: map[string]string{"Pune University Center": FilterHasSeats},
+		},
+		{
+			name: "center quota used up",
+			policy: func(p *AllocationPolicy) {
+				p.Quotas = []PolicyQuota{{Per: QuotaCenter, Name: "Pune University Center", Max: 1}}
+			},
+			setup: func(h *ExamCenterHandler, ex ExamType) {
+				h.registrations = append(h.registrations, ExamRegistration{ID: "UPSC-1", ExamType: ex, AssignedCenter: "Pune University Center", AssignedCity: "Pune"})
+			},
+			first: "Shivaji Nagar Exam Hall",
+			rules: map[string]string{"Pune University Center": RuleQuota, "Kothrud Sports Complex": ""},
+		},
+		{
+			name:   "quota of another edition",
+			policy: func(p *AllocationPolicy) { p.Quotas = []PolicyQuota{{Per: QuotaCity, Name: "Pune", Max: 1}} },
+			setup: func(h *ExamCenterHandler, ex ExamType) {
+				ex.Edition--
+				h.registrations = append(h.registrations, ExamRegistration{ID: "UPSC-1", ExamType: ex, AssignedCenter: "Pune University Center", AssignedCity: "Pune"})
+			},
+			first: "Pune University Center",
+		},
+		{
+			name: "closed for the sitting",
+			setup: func(h *ExamCenterHandler, ex ExamType) {
+				h.closures = append(h.closures, CenterClosure{Center: "Pune University Center", City: "Pune", Date: "2024-06-02"})
+			},
+			first: "Shivaji Nagar Exam Hall",
+			rules: map[string]string{"Pune University Center": RuleClosed},
+		},
+		{
+			name:  "disabled center",
+			setup: func(h *ExamCenterHandler, ex ExamType) { h.examCenters["Pune"][0].Disabled = true },
+			first: "Shivaji Nagar Exam Hall",
+			rules: map[string]string{"Pune University Center": RuleDisabled},
+		},
+		{
+			name: "travel time limit",
+			policy: func(p *AllocationPolicy) {
+				p.Filters = append(p.Filters, PolicyFilter{Rule: FilterMaxTravel, Hours: 0.5})
+			},
+			first: "Pune University Center",
+			rules: map[string]string{"Kothrud Sports Complex": "", "Navi Mumbai Central Exam Center": FilterMaxTravel},
+		},
+		{
+			name:   "free seats tie-breaker",
+			policy: func(p *AllocationPolicy) { p.TieBreakers = []string{TieFreeSeats} },
+			setup: func(h *ExamCenterHandler, ex ExamType) {
+				h.centerCapacity["Shivaji Nagar Exam Hall"] = CenterCapacity{TotalSeats: 1000, AvailableSeats: 999, BookedSeats: 1}
+			},
+			first: "Shivaji Nagar Exam Hall",
+		},
+		{
+			name: "distance tie-breaker without weights",
+			policy: func(p *AllocationPolicy) {
+				p.Weights, p.TieBreakers = PolicyWeights{}, []string{TieDistance}
+			},
+			first: "Pune University Center",
+			rules: map[string]string{"Navi Mumbai Central Exam Center": "", "Kalyan Central Exam Center": RuleMaxCenters},
+		},
+		{
+			name: "city quota as a share of its seats",
+			policy: func(p *AllocationPolicy) {
+				p.Quotas = []PolicyQuota{{Per: QuotaCity, Name: "Pune", MaxPercent: 1}} // 1% of 667 seats
+			},
+			setup: func(h *ExamCenterHandler, ex ExamType) {
+				for i := 0; i < 6; i++ {
+					h.registrations = append(h.registrations, ExamRegistration{ExamType: ex, AssignedCenter: "Kothrud Sports Complex", AssignedCity: "Pune"})
+				}
+			},
+			first: "Navi Mumbai Central Exam Center",
+			rules: map[string]string{"Pune University Center": RuleQuota, "Kothrud Sports Complex": RuleQuota},
+		},
+	}
+	for _, tt := range tests {
+		h, ex := allocationHandler(t)
+		p := DefaultPolicy(ex.Code)
+		if tt.policy != nil {
+			tt.policy(&p)
+		}
+		if tt.setup != nil {
+			tt.setup(h, ex)
+		}
+		res, err := h.allocate(p, "Pune", ex, StudentPreference{MaxDistance: 200})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(res.Nearest) == 0 || res.Nearest[0].Centers[0].Name != tt.first {
+			t.Errorf("%s: first center is not %s: %+v", tt.name, tt.first, res.Nearest)
+		}
+		got := make(map[string]CenterVerdict)
+		for _, v := range res.Verdicts {
+			got[v.Center.Name] = v
+		}
+		for center, rule := range tt.rules {
+			v, ok := got[center]
+			if !ok {
+				t.Errorf("%s: no verdict for %s", tt.name, center)
+			} else if v.Rule != rule || (rule == "") != (v.Rank > 0) {
+				t.Errorf("%s: %s has rule %q and rank %d, want rule %q", tt.name, center, v.Rule, v.Rank, rule)
+			}
+		}
+	}
+}
+
+func TestAllocationExplainsWhatIsBehind(t *testing.T) {
+	h, ex := allocationHandler(t)
+	p := DefaultPolicy(ex.Code)
+	p.TieBreakers = []string{TieName}
+	res, err := h.allocate(p, "Pune", ex, StudentPreference{MaxDistance: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		center string
+		behind string
+	}{
+		{"Kothrud Sports Complex", ""},
+		{"Pune University Center", "same score, later by name"},
+		{"Shivaji Nagar Exam Hall", "same score, later by name"},
+		{"Navi Mumbai Central Exam Center", "score 104.1 against 0.0"},
+	}
+	for i, w := range want {
+		v := res.Verdicts[i]
+		if v.Center.Name != w.center || v.Rank != i+1 || v.Behind != w.behind {
+			t.Errorf("verdict %d is %s ranked %d behind %q, want %s behind %q", i, v.Center.Name, v.Rank, v.Behind, w.center, w.behind)
+		}
+	}
+}
+func TestRenameCenterKeepsItsQuotas(t *testing.T) {
+	h, ex := allocationHandler(t)
+	p := DefaultPolicy(ex.Code)
+	p.Quotas = []PolicyQuota{{Per: QuotaCenter, Name: "Pune University Center", Max: 1}, {Per: QuotaCity, Name: "Pune", Max: 5}}
+	if err := h.SetAllocationPolicy("admin", p); err != nil {
+		t.Fatal(err)
+	}
+	h.registrations = append(h.registrations, ExamRegistration{ID: "UPSC-1", ExamType: ex, AssignedCenter: "Pune University Center", AssignedCity: "Pune"})
+	if err := h.RenameCenter("admin", "Pune University Center", "Savitribai Phule Center"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := h.AllocationPolicyFor(ex.Code)
+	want := []PolicyQuota{{Per: QuotaCenter, Name: "Savitribai Phule Center", Max: 1}, {Per: QuotaCity, Name: "Pune", Max: 5}}
+	if !reflect.DeepEqual(got.Quotas, want) {
+		t.Errorf("quotas %+v, want %+v", got.Quotas, want)
+	}
+	if p.Quotas[0].Name != "Pune University Center" {
+		t.Error("the rename changed a copy of the policy taken before it")
+	}
+	res, err := h.allocate(got, "Pune", ex, StudentPreference{MaxDistance: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range res.Verdicts {
+		if v.Center.Name == "Savitribai Phule Center" && v.Rule != RuleQuota {
+			t.Errorf("renamed center excluded by %q, want its quota to still apply", v.Rule)
+		}
+	}
+}
+func TestCheckAllocationPolicy(t *testing.T) {
+	const musts = `{"rule":"access"},{"rule":"has_seats"}`
+	tests := []struct {
+		name string
+		file string
+		err  string
+	}{
+		{"misspelt field", `{"exam":"UPSC","filter":[]}`, `error reading policy: json: unknown field "filter"`},
+		{"unknown exam", `{"exam":"XAT","filters":[` + musts + `]}`, "exam type 'XAT' not found"},
+		{"unknown rule", `{"exam":"UPSC","filters":[{"rule":"nearest"},` + musts + `]}`, "unknown filter rule 'nearest'"},
+		{"rule twice", `{"exam":"UPSC","filters":[{"rule":"max_distance","km":100},{"rule":"max_distance"},` + musts + `]}`, "filter rule 'max_distance' is listed twice"},
+		{"seat filter left out", `{"exam":"UPSC","filters":[{"rule":"access"}]}`, "the has_seats filter cannot be left out"},
+		{"travel without hours", `{"exam":"UPSC","filters":[{"rule":"max_travel"},` + musts + `]}`, "max_travel needs a number of hours"},
+		{"unknown excluded city", `{"exam":"UPSC","filters":[{"rule":"exclude_cities","cities":["Lonavala"]},` + musts + `]}`, "exclude_cities: city 'Lonavala' not found"},
+		{"unknown tie-breaker", `{"exam":"UPSC","filters":[` + musts + `],"tie_breakers":["random"]}`, "unknown tie-breaker 'random' (use fill_percent, free_seats, distance, name)"},
+		{"negative weight", `{"exam":"UPSC","filters":[` + musts + `],"weights":{"distance_km":-1}}`, "weights cannot be negative"},
+		{"quota per district", `{"exam":"UPSC","filters":[` + musts + `],"quotas":[{"per":"district","max":5}]}`, "quota per 'district' must be center or city"},
+		{"quota with both limits", `{"exam":"UPSC","filters":[` + musts + `],"quotas":[{"per":"city","max":5,"max_percent":10}]}`, "a quota needs either max or max_percent (up to 100)"},
+		{"quota over 100%", `{"exam":"UPSC","filters":[` + musts + `],"quotas":[{"per":"city","max_percent":120}]}`, "a quota needs either max or max_percent (up to 100)"},
+	}
+	h := NewExamCenterHandler()
+	for _, tt := range tests {
+		p, err := ParseAllocationPolicy(strings.NewReader(tt.file))
+		if err == nil {
+			err = h.CheckAllocationPolicy(&p)
+		}
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: error %v, want %q", tt.name, err, tt.err)
+		}
+	}
+
+	p, err := ParseAllocationPolicy(strings.NewReader(`{"exam":" upsc ","filters":[{"rule":"exclude_cities","cities":["navi mumbai"]},` + musts + `],
+		"quotas":[{"per":"center","name":"pune university center","max":5},{"per":"city","name":"PUNE","max_percent":10}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.CheckAllocationPolicy(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Exam != "UPSC" || p.Filters[0].Cities[0] != "Navi Mumbai" || p.Quotas[0].Name != "Pune University Center" || p.Quotas[1].Name != "Pune" {
+		t.Errorf("names not made canonical: %+v", p)
+	}
+} 
\ No newline at end of file
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"math"
+	"sort"
+)
+
+// DefaultPopulation is the population, in thousands, assumed for cities not in cityPopulations
+const DefaultPopulation = 500
+
+// cityPopulations holds the 2011 census population of the built-in cities, in thousands. Cities that
+// share an urban area with a bigger one (Navi Mumbai, Howrah, Gurgaon, ...) count on their own.
+var cityPopulations = map[string]int{
+	"Mumbai": 12442, "Delhi": 11034, "Bangalore": 8443, "Hyderabad": 6731, "Ahmedabad": 5577, "Chennai": 4646,
+	"Kolkata": 4497, "Pune": 3124, "Jaipur": 3046, "Lucknow": 2817, "Kanpur": 2768, "Nagpur": 2405,
+	"Indore": 1964, "Bhopal": 1798, "Patna": 1684, "Vadodara": 1670, "Ghaziabad": 1648, "Agra": 1585,
+	"Nashik": 1486, "Faridabad": 1414, "Meerut": 1305, "Rajkot": 1286, "Kalyan": 1247, "Vasai": 1222,
+	"Varanasi": 1198, "Srinagar": 1180, "Aurangabad": 1175, "Dhanbad": 1162, "Amritsar": 1132,
+	"Navi Mumbai": 1120, "Allahabad": 1112, "Ranchi": 1073, "Howrah": 1072, "Coimbatore": 1061,
+	"Jabalpur": 1055, "Gwalior": 1054, "Vijayawada": 1048, "Jodhpur": 1033, "Madurai": 1017, "Raipur": 1010,
+	"Kota": 1001, "Chandigarh": 961, "Guwahati": 957, "Solapur": 951, "Hubli": 943, "Bareilly": 898,
+	"Moradabad": 887, "Mysore": 887, "Gurgaon": 876, "Aligarh": 874, "Jalandhar": 862,
+}
+
+// CityPopulation returns a city's population in thousands, DefaultPopulation when it is not on record
+func CityPopulation(city string) int {
+	if p, ok := cityPopulations[city]; ok {
+		return p
+	}
+	return DefaultPopulation
+}
+
+// SyntheticDemand spreads total candidates over every home city in proportion to its population.
+// Rounding remainders go to the cities that lost most to rounding, so the counts add up to total.
+func (h *ExamCenterHandler) SyntheticDemand(total int) []DemandForecast {
+	cities := h.ListCities()
+	if total <= 0 || len(cities) == 0 {
+		return nil
+	}
+	people := 0
+	for _, c := range cities {
+		people += CityPopulation(c.Name)
+	}
+	demand := make([]DemandForecast, len(cities))
+	remainders := make([]float64, len(cities))
+	left := total
+	for i, c := range cities {
+		share := float64(total) * float64(CityPopulation(c.Name)) / float64(people)
+		demand[i] = DemandForecast{City: c.Name, Candidates: int(math.Floor(share))}
+		remainders[i] = share - math.Floor(share)
+		left -= demand[i].Candidates
+	}
+	order := make([]int, len(cities))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for _, i := range order[:left] {
+		demand[i].Candidates++
+	}
+	return demand
+} 
\ No newline at end of file
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQRReedSolomon(t *testing.T) {
+	// "HELLO WORLD" at version 1-M, the worked example from the standard
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := qrRSRemainder(data, qrRSDivisor(10)); !reflect.DeepEqual(got, want) {
+		t.Errorf("ECC %v, want %v", got, want)
+	}
+}
+
+func TestQRFormatBits(t *testing.T) {
+	// format strings for each level and mask, most significant bit first
+	want := map[qrLevel][8]string{
+		qrLevelL: {"111011111000100", "111001011110011", "111110110101010", "111100010011101", "110011000101111", "110001100011000", "110110001000001", "110100101110110"},
+		qrLevelM: {"101010000010010", "101000100100101", "101111001111100", "101101101001011", "100010111111001", "100000011001110", "100111110010111", "100101010100000"},
+		qrLevelQ: {"011010101011111", "011000001101000", "011111100110001", "011101000000110", "010010010110100", "010000110000011", "010111011011010", "010101111101101"},
+		qrLevelH: {"001011010001001", "001001110111110", "001110011100111", "001100111010000", "000011101100010", "000001001010101", "000110100001100", "000100000111011"},
+	}
+	for level, masks := range want {
+		for mask, bits := range masks {
+			q := newTestQR(21)
+			q.drawFormatBits(level, mask)
+			if got := qrFormatString(q); got != bits {
+				t.Errorf("level %d mask %d: format %s, want %s", level, mask, got, bits)
+			}
+		}
+	}
+}
+
+func TestEncodeQR(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		level   qrLevel
+		version int
+	}{
+		{"one byte", 1, qrLevelM, 1},
+		{"fills version 1", 14, qrLevelM, 1},
+		{"spills into version 2", 15, qrLevelM, 2},
+		{"version information", 150, qrLevelM, 8},
+		{"sixteen bit count", 320, qrLevelM, 13},
+		{"high recovery", 320, qrLevelH, 19},
+	}
+	for _, tt := range tests {
+		data := bytes.Repeat([]byte("ECH1:{}/"), tt.size/8+1)[:tt.size]
+		q, err := encodeQR(data, tt.level)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := (q.size - 17) / 4; got != tt.version {
+			t.Errorf("%s: version %d, want %d", tt.name, got, tt.version)
+		}
+		level, got := decodeTestQR(t, q)
+		if level != tt.level || !bytes.Equal(got, data) {
+			t.Errorf("%s: decoded %q at level %d, want %q at level %d", tt.name, got, level, data, tt.level)
+		}
+	}
+
+	if _, err := encodeQR(make([]byte, 2332), qrLevelM); err == nil {
+		t.Error("2332 bytes at level M encoded, want an error")
+	}
+}
+
+func newTestQR(size int) *qrCode {
+	q := &qrCode{size: size, modules: make([][]bool, size), isFunction: make([][]bool, size)}
+	for i := range q.modules {
+		q.modules[i] = make([]bool, size)
+		q.isFunction[i] = make([]bool, size)
+	}
+	return q
+}
+
+// qrFormatString reads the format bits around the top-left finder and checks the second copy and
+// the dark module; the bits come back as written, most significant bit first
+func qrFormatString(q *qrCode) string {
+	first := make([]bool, 15)
+	for i := 0; i <= 5; i++ {
+		first[i] = q.Dark(8, i)
+	}
+	first[6], first[7], first[8] = q.Dark(8, 7), q.Dark(8, 8), q.Dark(7, 8)
+	for i := 9; i < 15; i++ {
+		first[i] = q.Dark(14-i, 8)
+	}
+	second := make([]bool, 15)
+	for i := 0; i < 8; i++ {
+		second[i] = q.Dark(q.size-1-i, 8)
+	}
+	for i := 8; i < 15; i++ {
+		second[i] = q.Dark(8, q.size-15+i)
+	}
+	if !reflect.DeepEqual(first, second) || !q.Dark(8, q.size-8) {
+		return "copies differ"
+	}
+	var b strings.Builder
+	for i := 14; i >= 0; i-- {
+		if first[i] {
+			b.WriteByte('1')
+		} else {
+			b.WriteByte('0')
+		}
+	}
+	return b.String()
+}
+
+// decodeTestQR reads a byte-mode symbol back the way a scanner would: format bits, unmasking,
+// zigzag placement, block de-interleaving with an ECC check, and the byte-mode segment
+func decodeTestQR(t *testing.T, q *qrCode) (qrLevel, []byte) {
+	t.Helper()
+	version := (q.size - 17) / 4
+	format := qrFormatString(q)
+	var level qrLevel
+	mask := -1
+	for l := qrLevelL; l <= qrLevelH && mask < 0; l++ {
+		for m := 0; m < 8; m++ {
+			probe := newTestQR(q.size)
+			probe.drawFormatBits(l, m)
+			if qrFormatString(probe) == format {
+				level, mask = l, m
+				break
+			}
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("unreadable format bits %s", format)
+	}
+
+	plain := newTestQR(q.size)
+	plain.drawFunctionPatterns(version, level)
+	for y := range plain.modules {
+		for x := range plain.modules[y] {
+			if plain.isFunction[y][x] {
+				continue
+			}
+			plain.modules[y][x] = q.Dark(x, y)
+		}
+	}
+	plain.applyMask(mask)
+
+	raw := make([]byte, qrNumRawDataModules(version)/8)
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < q.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = q.size - 1 - vert
+				}
+				if !plain.isFunction[y][x] && i < len(raw)*8 {
+					if plain.modules[y][x] {
+						raw[i>>3] |= 1 << (7 - uint(i&7))
+					}
+					i++
+				}
+			}
+		}
+	}
+
+	numBlocks := qrNumBlocks[level][version]
+	eccLen := qrECCPerBlock[level][version]
+	numShort := numBlocks - len(raw)%numBlocks
+	shortLen := len(raw) / numBlocks
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := 0; i <= shortLen; i++ {
+		for j := range blocks {
+			if i == shortLen-eccLen && j < numShort {
+				continue // short blocks have no byte at this position
+			}
+			blocks[j] = append(blocks[j], raw[k])
+			k++
+		}
+	}
+	var data []byte
+	divisor := qrRSDivisor(eccLen)
+	for j, block := range blocks {
+		n := len(block) - eccLen
+		if got := qrRSRemainder(block[:n], divisor); !bytes.Equal(got, block[n:]) {
+			t.Fatalf("block %d: ECC %v, want %v", j, block[n:], got)
+		}
+		data = append(data, block[:n]...)
+	}
+
+	bit := 0
+	read := func(n int) int {
+		v := 0
+		for ; n > 0; n-- {
+			v = v<<1 | int(data[bit>>3]>>(7-uint(bit&7))&1)
+			bit++
+		}
+		return v
+	}
+	if mode := read(4); mode != 0x4 {
+		t.Fatalf("mode %04b, want byte mode", mode)
+	}
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	out := make([]byte, read(countBits))
+	for i := range out {
+		out[i] = byte(read(8))
+	}
+	return level, out
+} 
\ No newline at end of file
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Registration sort orders
+const (
+	SortRegistered = "registered" // registration time
+	SortDistance   = "distance"   // distance from the home city
+	SortExamDate   = "exam_date"  // exam date, then time slot
+)
+
+// SortKeys lists the accepted sort orders
+var SortKeys = []string{SortRegistered, SortDistance, SortExamDate}
+
+// DefaultPageSize is used when a query does not ask for a page size
+const DefaultPageSize = 50
+
+// MaxPageSize caps the page size a query may ask for
+const MaxPageSize = 1000
+
+// RegistrationQuery is a filtered, sorted, paginated view of the registrations
+type RegistrationQuery struct {
+	Filter   RegistrationFilter
+	Sort     string // one of SortKeys; empty sorts by registration time
+	Desc     bool
+	Page     int // 1-based; values below 1 mean the first page
+	PageSize int // 0 means DefaultPageSize
+}
+
+// RegistrationPage is one page of query results
+type RegistrationPage struct {
+	Items    []ExamRegistration
+	Total    int // matching registrations on all pages
+	Page     int
+	PageSize int
+	Pages    int
+}
+
+// HasPrev reports whether there is an earlier page
+func (p RegistrationPage) HasPrev() bool { return p.Page > 1 }
+
+// HasNext reports whether there is a later page
+func (p RegistrationPage) HasNext() bool { return p.Page < p.Pages }
+
+// First is the 1-based position of the page's first item, or 0 on an empty page
+func (p RegistrationPage) First() int {
+	if len(p.Items) == 0 {
+		return 0
+	}
+	return (p.Page-1)*p.PageSize + 1
+}
+
+// Last is the 1-based position of the page's last item
+func (p RegistrationPage) Last() int { return p.First() + len(p.Items) - 1 }
+
+// Validate checks the filter and sort order
+func (q RegistrationQuery) Validate() error {
+	if err := q.Filter.Validate(); err != nil {
+		return err
+	}
+	if q.Sort != "" {
+		for _, k := range SortKeys {
+			if k == q.Sort {
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown sort order '%s' (use %s)", q.Sort, strings.Join(SortKeys, ", "))
+	}
+	return nil
+}
+
+// QueryRegistrations filters, sorts and pages the registrations. allowed limits the result to what
+// the caller may see; nil allows all. A page past the end is clamped to the last page.
+func (h *ExamCenterHandler) QueryRegistrations(q RegistrationQuery, allowed func(ExamRegistration) bool) (RegistrationPage, error) {
+	if err := q.Validate(); err != nil {
+		return RegistrationPage{}, err
+	}
+	list := h.FilterRegistrations(q.Filter, allowed)
+	// Stable sorts keep registration order among equal keys
+	switch q.Sort {
+	case SortDistance:
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Distance < list[j].Distance })
+	case SortExamDate:
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].ExamDate != list[j].ExamDate {
+				return list[i].ExamDate < list[j].ExamDate
+			}
+			return list[i].TimeSlot < list[j].TimeSlot
+		})
+	default:
+		sort.SliceStable(list, func(i, j int) bool { return list[i].RegistrationTime.Before(list[j].RegistrationTime) })
+	}
+	if q.Desc {
+		for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+			list[i], list[j] = list[j], list[i]
+		}
+	}
+
+	p := RegistrationPage{Total: len(list), PageSize: q.PageSize, Page: q.Page}
+	if p.PageSize <= 0 {
+		p.PageSize = DefaultPageSize
+	}
+	p.PageSize = min(p.PageSize, MaxPageSize)
+	p.Pages = max(1, (p.Total+p.PageSize-1)/p.PageSize)
+	p.Page = min(max(p.Page, 1), p.Pages)
+	start := (p.Page - 1) * p.PageSize
+	p.Items = list[start:min(start+p.PageSize, p.Total)]
+	return p, nil
+} 
\ No newline at end of file
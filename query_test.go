@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func queryHandler() *ExamCenterHandler {
+	h := NewExamCenterHandler()
+	start := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	reg := func(id, exam, date, slot string, km float64, minute int) ExamRegistration {
+		return ExamRegistration{ID: id, StudentName: "Candidate " + id, ExamType: ExamType{Code: exam}, ExamDate: date, TimeSlot: slot, Distance: km,
+			RegistrationTime: start.Add(time.Duration(minute) * time.Minute)}
+	}
+	// stored out of registration order, the way a rename or promotion can leave them
+	h.registrations = []ExamRegistration{
+		reg("R3", "UPSC", "2024-06-02", "14:30-17:30", 20, 3),
+		reg("R1", "NEET", "2024-05-05", "09:30-12:30", 40, 1),
+		reg("R5", "UPSC", "2024-06-02", "09:30-12:30", 20, 5),
+		reg("R2", "UPSC", "2024-06-01", "09:30-12:30", 0, 2),
+		reg("R4", "NEET", "2024-05-05", "09:30-12:30", 55, 4),
+	}
+	return h
+}
+
+func TestQueryRegistrations(t *testing.T) {
+	h := queryHandler()
+	tests := []struct {
+		name  string
+		query RegistrationQuery
+		ids   []string
+		page  RegistrationPage // Total, Page, PageSize and Pages
+		first int
+	}{
+		{
+			name:  "registration order",
+			query: RegistrationQuery{},
+			ids:   []string{"R1", "R2", "R3", "R4", "R5"},
+			page:  RegistrationPage{Total: 5, Page: 1, PageSize: DefaultPageSize, Pages: 1},
+			first: 1,
+		},
+		{
+			name:  "distance, ties in registration order",
+			query: RegistrationQuery{Sort: SortDistance},
+			ids:   []string{"R2", "R3", "R5", "R1", "R4"},
+			page:  RegistrationPage{Total: 5, Page: 1, PageSize: DefaultPageSize, Pages: 1},
+			first: 1,
+		},
+		{
+			name:  "exam date and slot, newest first",
+			query: RegistrationQuery{Sort: SortExamDate, Desc: true},
+			ids:   []string{"R3", "R5", "R2", "R4", "R1"},
+			page:  RegistrationPage{Total: 5, Page: 1, PageSize: DefaultPageSize, Pages: 1},
+			first: 1,
+		},
+		{
+			name:  "second page",
+			query: RegistrationQuery{Page: 2, PageSize: 2},
+			ids:   []string{"R3", "R4"},
+			page:  RegistrationPage{Total: 5, Page: 2, PageSize: 2, Pages: 3},
+			first: 3,
+		},
+		{
+			name:  "page past the end is the last page",
+			query: RegistrationQuery{Page: 9, PageSize: 2},
+			ids:   []string{"R5"},
+			page:  RegistrationPage{Total: 5, Page: 3, PageSize: 2, Pages: 3},
+			first: 5,
+		},
+		{
+			name:  "filtered",
+			query: RegistrationQuery{Filter: RegistrationFilter{Exam: "NEET"}, Sort: SortDistance, Desc: true},
+			ids:   []string{"R4", "R1"},
+			page:  RegistrationPage{Total: 2, Page: 1, PageSize: DefaultPageSize, Pages: 1},
+			first: 1,
+		},
+		{
+			name:  "nothing matches",
+			query: RegistrationQuery{Filter: RegistrationFilter{Exam: "JEE"}, Page: 3},
+			ids:   []string{},
+			page:  RegistrationPage{Total: 0, Page: 1, PageSize: DefaultPageSize, Pages: 1},
+		},
+		{
+			name:  "page size is capped",
+			query: RegistrationQuery{PageSize: MaxPageSize + 1},
+			ids:   []string{"R1", "R2", "R3", "R4", "R5"},
+			page:  RegistrationPage{Total: 5, Page: 1, PageSize: MaxPageSize, Pages: 1},
+			first: 1,
+		},
+	}
+	for _, tt := range tests {
+		p, err := h.QueryRegistrations(tt.query, nil)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		ids := []string{}
+		for _, reg := range p.Items {
+			ids = append(ids, reg.ID)
+		}
+		if !reflect.DeepEqual(ids, tt.ids) {
+			t.Errorf("%s: items %v, want %v", tt.name, ids, tt.ids)
+		}
+		got := RegistrationPage{Total: p.Total, Page: p.Page, PageSize: p.PageSize, Pages: p.Pages}
+		if !reflect.DeepEqual(got, tt.page) {
+			t.Errorf("%s: page %+v, want %+v", tt.name, got, tt.page)
+		}
+		if p.First() != tt.first || p.Last() != tt.first+len(tt.ids)-1 {
+			t.Errorf("%s: showing %d-%d, want %d-%d", tt.name, p.First(), p.Last(), tt.first, tt.first+len(tt.ids)-1)
+		}
+	}
+}
+
+func TestQueryRegistrationsPaging(t *testing.T) {
+	h := queryHandler()
+	p, _ := h.QueryRegistrations(RegistrationQuery{Page: 2, PageSize: 2}, nil)
+	if !p.HasPrev() || !p.HasNext() {
+		t.Errorf("middle page: prev %v next %v, want both", p.HasPrev(), p.HasNext())
+	}
+	p, _ = h.QueryRegistrations(RegistrationQuery{Page: 3, PageSize: 2}, nil)
+	if !p.HasPrev() || p.HasNext() {
+		t.Errorf("last page: prev %v next %v, want only prev", p.HasPrev(), p.HasNext())
+	}
+
+	onlyUPSC := func(reg ExamRegistration) bool { return reg.ExamType.Code == "UPSC" }
+	if p, _ := h.QueryRegistrations(RegistrationQuery{}, onlyUPSC); p.Total != 3 {
+		t.Errorf("allowed registrations: total %d, want 3", p.Total)
+	}
+
+	for _, q := range []RegistrationQuery{{Sort: "name"}, {Filter: RegistrationFilter{From: "2024-13-01"}}} {
+		if _, err := h.QueryRegistrations(q, nil); err == nil {
+			t.Errorf("%+v: no error, want one", q)
+		}
+	}
+} 
\ No newline at end of file
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Question paper dispatch rules
+const (
+	PaperPacketSize = 25   // papers per sealed packet
+	PaperSpareRate  = 0.05 // spare papers sent on top of the candidate count
+)
+
+// AttendanceSheet lists the candidates expected at one center for one date and slot
+type AttendanceSheet struct {
+	Center     string
+	City       string
+	Date       string
+	Slot       string
+	Candidates []ExamRegistration // by room and seat, unseated candidates last by roll number
+}
+
+// AttendanceSheets builds per-center, per-slot attendance sheets. Empty filters match everything.
+func (h *ExamCenterHandler) AttendanceSheets(center, date, slot string) []AttendanceSheet {
+	sheets := make(map[string]*AttendanceSheet)
+	for _, reg := range h.registrations {
+		if center != "" && !strings.EqualFold(reg.AssignedCenter, center) {
+			continue
+		}
+		if (date != "" && reg.ExamDate != date) || (slot != "" && reg.TimeSlot != slot) {
+			continue
+		}
+		key := reg.AssignedCenter + "|" + reg.ExamDate + "|" + reg.TimeSlot
+		sheet, ok := sheets[key]
+		if !ok {
+			sheet = &AttendanceSheet{Center: reg.AssignedCenter, City: reg.AssignedCity, Date: reg.ExamDate, Slot: reg.TimeSlot}
+			sheets[key] = sheet
+		}
+		sheet.Candidates = append(sheet.Candidates, reg)
+	}
+	result := make([]AttendanceSheet, 0, len(sheets))
+	for _, sheet := range sheets {
+		c := sheet.Candidates
+		sort.Slice(c, func(i, j int) bool {
+			if (c[i].Room == "") != (c[j].Room == "") {
+				return c[j].Room == ""
+			}
+			if c[i].Room != c[j].Room {
+				return c[i].Room < c[j].Room
+			}
+			if c[i].SeatNumber != c[j].SeatNumber {
+				return seatLess(c[i].SeatNumber, c[j].SeatNumber)
+			}
+			return c[i].RollNumber < c[j].RollNumber
+		})
+		result = append(result, *sheet)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.City != b.City {
+			return a.City < b.City
+		}
+		if a.Center != b.Center {
+			return a.Center < b.Center
+		}
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		return a.Slot < b.Slot
+	})
+	return result
+}
+
+// seatLess orders seat labels by row, then numerically by seat
+func seatLess(a, b string) bool {
+	ra, sa, okA := parseSeatLabel(a)
+	rb, sb, okB := parseSeatLabel(b)
+	if !okA || !okB {
+		return a < b
+	}
+	if ra != rb {
+		return ra < rb
+	}
+	return sa < sb
+}
+
+// WriteAttendanceSheetsCSV writes the sheets as one CSV with an empty signature column
+func WriteAttendanceSheetsCSV(w io.Writer, sheets []AttendanceSheet) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"center", "city", "date", "slot", "registration_id", "roll_number", "name", "exam", "room", "seat", "signature"})
+	for _, sheet := range sheets {
+		for _, reg := range sheet.Candidates {
+			_ = cw.Write([]string{sheet.Center, sheet.City, sheet.Date, sheet.Slot, reg.ID, reg.RollNumber, reg.StudentName, reg.ExamType.Code, reg.Room, reg.SeatNumber, ""})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ManifestLine is the question paper dispatch for one center, exam and session
+type ManifestLine struct {
+	Center     string
+	City       string
+	Exam       string
+	Date       string
+	Slot       string
+	Candidates int
+	Papers     int // candidates plus spares
+	Packets    int
+}
+
+// DispatchManifest counts question papers and sealed packets to send to every center
+func (h *ExamCenterHandler) DispatchManifest() []ManifestLine {
+	lines := make(map[string]*ManifestLine)
+	for _, reg := range h.registrations {
+		key := strings.Join([]string{reg.AssignedCenter, reg.ExamType.Code, reg.ExamDate, reg.TimeSlot}, "|")
+		line, ok := lines[key]
+		if !ok {
+			line = &ManifestLine{Center: reg.AssignedCenter, City: reg.AssignedCity, Exam: reg.ExamType.Code, Date: reg.ExamDate, Slot: reg.TimeSlot}
+			lines[key] = line
+		}
+		line.Candidates++
+	}
+	result := make([]ManifestLine, 0, len(lines))
+	for _, line := range lines {
+		line.Papers = line.Candidates + int(math.Ceil(float64(line.Candidates)*PaperSpareRate))
+		line.Packets = (line.Papers + PaperPacketSize - 1) / PaperPacketSize
+		result = append(result, *line)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.City != b.City {
+			return a.City < b.City
+		}
+		if a.Center != b.Center {
+			return a.Center < b.Center
+		}
+		if a.Date+a.Slot != b.Date+b.Slot {
+			return a.Date+a.Slot < b.Date+b.Slot
+		}
+		return a.Exam < b.Exam
+	})
+	return result
+}
+
+// WriteManifestCSV writes the dispatch manifest as CSV
+func WriteManifestCSV(w io.Writer, lines []ManifestLine) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"city", "center", "exam", "date", "slot", "candidates", "papers", "packets"})
+	for _, l := range lines {
+		_ = cw.Write([]string{l.City, l.Center, l.Exam, l.Date, l.Slot, strconv.Itoa(l.Candidates), strconv.Itoa(l.Papers), strconv.Itoa(l.Packets)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ProcessReports writes attendance sheets or the dispatch manifest to a CSV file
+func (h *ExamCenterHandler) ProcessReports() error {
+	fmt.Println("=== Center Reports ===")
+	fmt.Println("1. Attendance sheets (CSV)")
+	fmt.Println("2. Question paper dispatch manifest (CSV)")
+	choice, err := h.GetUserInput("Select (1-2): ")
+	if err != nil {
+		return err
+	}
+	var write func(io.Writer) error
+	var path string
+	switch choice {
+	case "1":
+		center, err := h.GetUserInput("Center name [default: all]: ")
+		if err != nil {
+			return err
+		}
+		sheets := h.AttendanceSheets(center, "", "")
+		if len(sheets) == 0 {
+			return fmt.Errorf("no registrations found")
+		}
+		write = func(w io.Writer) error { return WriteAttendanceSheetsCSV(w, sheets) }
+		path = "attendance-sheets.csv"
+	case "2":
+		lines := h.DispatchManifest()
+		if len(lines) == 0 {
+			return fmt.Errorf("no registrations found")
+		}
+		total := 0
+		for _, l := range lines {
+			fmt.Printf("%-12s %-34s %-6s %s %s  %4d papers  %3d packets\n", l.City, l.Center, l.Exam, l.Date, l.Slot, l.Papers, l.Packets)
+			total += l.Packets
+		}
+		fmt.Printf("Total packets: %d\n", total)
+		write = func(w io.Writer) error { return WriteManifestCSV(w, lines) }
+		path = "dispatch-manifest.csv"
+	default:
+		return fmt.Errorf("invalid option")
+	}
+	out, err := h.GetUserInput(fmt.Sprintf("Output file [default: %s]: ", path))
+	if err != nil {
+		return err
+	}
+	if out != "" {
+		path = out
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %v", path, err)
+	}
+	defer f.Close()
+	if err := write(f); err != nil {
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	fmt.Printf("✅ Written to %s\n", path)
+	return nil
+} 
\ No newline at end of file
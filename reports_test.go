@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func reportsHandler() *ExamCenterHandler {
+	h := NewExamCenterHandler()
+	reg := func(id, roll, center, city, exam, slot, room, seat string) ExamRegistration {
+		return ExamRegistration{ID: id, RollNumber: roll, StudentName: "Candidate " + roll, ExamType: ExamType{Code: exam}, ExamDate: "2024-06-02",
+			TimeSlot: slot, AssignedCenter: center, AssignedCity: city, Room: room, SeatNumber: seat}
+	}
+	h.registrations = []ExamRegistration{
+		reg("P1", "R5", "Pune University Center", "Pune", "UPSC", "09:30-12:30", "", ""),
+		reg("P2", "R4", "Pune University Center", "Pune", "UPSC", "09:30-12:30", "Hall A", "A10"),
+		reg("P3", "R3", "Pune University Center", "Pune", "UPSC", "09:30-12:30", "Hall A", "A2"),
+		reg("P4", "R2", "Pune University Center", "Pune", "UPSC", "09:30-12:30", "", ""),
+		reg("P5", "R1", "Pune University Center", "Pune", "UPSC", "14:30-17:30", "Hall B", "A1"),
+		reg("M1", "R6", "Navi Mumbai Central Exam Center", "Navi Mumbai", "UPSC", "09:30-12:30", "", ""),
+	}
+	return h
+}
+
+func TestAttendanceSheets(t *testing.T) {
+	h := reportsHandler()
+	tests := []struct {
+		name               string
+		center, date, slot string
+		want               []string // center/slot: registration IDs in sheet order
+	}{
+		{
+			name: "all",
+			want: []string{"Navi Mumbai Central Exam Center/09:30-12:30: M1", "Pune University Center/09:30-12:30: P3 P2 P4 P1", "Pune University Center/14:30-17:30: P5"},
+		},
+		{
+			name:   "one center, any case",
+			center: "pune university center", slot: "14:30-17:30",
+			want: []string{"Pune University Center/14:30-17:30: P5"},
+		},
+		{
+			name: "no match", date: "2024-06-03",
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		got := []string{}
+		for _, sheet := range h.AttendanceSheets(tt.center, tt.date, tt.slot) {
+			var ids []string
+			for _, reg := range sheet.Candidates {
+				ids = append(ids, reg.ID)
+			}
+			got = append(got, fmt.Sprintf("%s/%s: %s", sheet.Center, sheet.Slot, strings.Join(ids, " ")))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: sheets %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAttendanceSheetsCSV(&buf, h.AttendanceSheets("", "", "14:30-17:30")); err != nil {
+		t.Fatal(err)
+	}
+	want := "center,city,date,slot,registration_id,roll_number,name,exam,room,seat,signature\n" +
+		"Pune University Center,Pune,2024-06-02,14:30-17:30,P5,R1,Candidate R1,UPSC,Hall B,A1,\n"
+	if buf.String() != want {
+		t.Errorf("CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestDispatchManifest(t *testing.T) {
+	h := NewExamCenterHandler()
+	add := func(n int, center, exam, slot string) {
+		for i := 0; i < n; i++ {
+			h.registrations = append(h.registrations, ExamRegistration{ExamType: ExamType{Code: exam}, ExamDate: "2024-06-02", TimeSlot: slot,
+				AssignedCenter: center, AssignedCity: "Pune"})
+		}
+	}
+	add(1, "Pune University Center", "UPSC", "09:30-12:30")
+	add(24, "Pune University Center", "NEET", "09:30-12:30")
+	add(20, "Pune University Center", "UPSC", "14:30-17:30")
+	add(50, "Kothrud Sports Complex", "UPSC", "09:30-12:30")
+
+	var got []string
+	for _, l := range h.DispatchManifest() {
+		got = append(got, fmt.Sprintf("%s %s %s: %d candidates, %d papers, %d packets", l.Center, l.Exam, l.Slot, l.Candidates, l.Papers, l.Packets))
+	}
+	want := []string{
+		"Kothrud Sports Complex UPSC 09:30-12:30: 50 candidates, 53 papers, 3 packets",
+		"Pune University Center NEET 09:30-12:30: 24 candidates, 26 papers, 2 packets",
+		"Pune University Center UPSC 09:30-12:30: 1 candidates, 2 papers, 1 packets",
+		"Pune University Center UPSC 14:30-17:30: 20 candidates, 21 papers, 1 packets",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("manifest:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+} 
\ No newline at end of file
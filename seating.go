@@ -0,0 +1,412 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default hall layout used when a center has not declared its rooms
+const (
+	defaultRoomRows        = 6
+	defaultRoomSeatsPerRow = 5 // odd, so interleaved groups form a checkerboard
+)
+
+// Seating interleave modes
+const (
+	InterleaveNone  = "none"  // seat candidates in roll number order
+	InterleaveExam  = "exam"  // alternate candidates of different exams sitting in the same slot
+	InterleaveShift = "shift" // give each shift of the day alternate seats, leaving gaps between candidates
+)
+
+// SeatingOptions controls seat allocation inside a center
+type SeatingOptions struct {
+	Interleave string
+}
+
+// Capacity is the number of seats in the room
+func (r Room) Capacity() int { return r.Rows * r.SeatsPerRow }
+
+// SeatLabel names a seat by row letter and seat number, e.g. "C4"
+func SeatLabel(row, seat int) string {
+	if row < 26 {
+		return fmt.Sprintf("%c%d", 'A'+row, seat+1)
+	}
+	return fmt.Sprintf("R%d-%d", row+1, seat+1)
+}
+
+// ParseRoomSpec parses room declarations such as "Hall A:6x5, Hall B:8x6" (name:rows x seats per row)
+func ParseRoomSpec(spec string) ([]Room, error) {
+	var rooms []Room
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		i := strings.LastIndex(part, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("room '%s' must look like name:ROWSxSEATS", part)
+		}
+		name := strings.TrimSpace(part[:i])
+		rowsText, seatsText, ok := strings.Cut(strings.ToLower(strings.TrimSpace(part[i+1:])), "x")
+		rows, err1 := strconv.Atoi(strings.TrimSpace(rowsText))
+		seats, err2 := strconv.Atoi(strings.TrimSpace(seatsText))
+		if name == "" || !ok || err1 != nil || err2 != nil || rows < 1 || seats < 1 {
+			return nil, fmt.Errorf("room '%s' must look like name:ROWSxSEATS", part)
+		}
+		rooms = append(rooms, Room{Name: name, Rows: rows, SeatsPerRow: seats})
+	}
+	if len(rooms) == 0 {
+		return nil, fmt.Errorf("no rooms declared")
+	}
+	return rooms, nil
+}
+
+// FormatRoomSpec is the inverse of ParseRoomSpec
+func FormatRoomSpec(rooms []Room) string {
+	specs := make([]string, len(rooms))
+	for i, r := range rooms {
+		specs[i] = fmt.Sprintf("%s:%dx%d", r.Name, r.Rows, r.SeatsPerRow)
+	}
+	return strings.Join(specs, ", ")
+}
+
+// SetCenterRooms declares the exam halls of a center
+func (h *ExamCenterHandler) SetCenterRooms(actor, centerName string, rooms []Room) error {
+	center, ok := h.findCenter(centerName)
+	if !ok {
+		return fmt.Errorf("exam center '%s' not found", centerName)
+	}
+	centers := h.examCenters[center.City]
+	for i := range centers {
+		if centers[i].Name == center.Name {
+			centers[i].Rooms = append([]Room(nil), rooms...)
+		}
+	}
+	return h.commit(actor, "center.rooms", center.Name, FormatRoomSpec(rooms))
+}
+
+// CenterRooms returns the declared rooms of a center, or default halls covering its total seats
+func (h *ExamCenterHandler) CenterRooms(centerName string) ([]Room, error) {
+	center, ok := h.findCenter(centerName)
+	if !ok {
+		return nil, fmt.Errorf("exam center '%s' not found", centerName)
+	}
+	if len(center.Rooms) > 0 {
+		return center.Rooms, nil
+	}
+	perRoom := defaultRoomRows * defaultRoomSeatsPerRow
+	total := h.centerCapacity[center.Name].TotalSeats
+	var rooms []Room
+	for i := 0; i*perRoom < total; i++ {
+		rooms = append(rooms, Room{Name: fmt.Sprintf("Room %d", 101+i), Rows: defaultRoomRows, SeatsPerRow: defaultRoomSeatsPerRow})
+	}
+	return rooms, nil
+}
+
+// SeatAssignment places one candidate in a room
+type SeatAssignment struct {
+	Row          int
+	Seat         int
+	SeatNumber   string
+	Registration ExamRegistration
+}
+
+// RoomPlan is the seating of one room for a session
+type RoomPlan struct {
+	Room  Room
+	Seats []SeatAssignment // in row, then seat order
+}
+
+// At returns the candidate in a seat, if any
+func (p RoomPlan) At(row, seat int) (SeatAssignment, bool) {
+	for _, s := range p.Seats {
+		if s.Row == row && s.Seat == seat {
+			return s, true
+		}
+	}
+	return SeatAssignment{}, false
+}
+
+// DoorList returns the room's candidates ordered by roll number, as posted on the door
+func (p RoomPlan) DoorList() []SeatAssignment {
+	list := append([]SeatAssignment(nil), p.Seats...)
+	sort.Slice(list, func(i, j int) bool { return list[i].Registration.RollNumber < list[j].Registration.RollNumber })
+	return list
+}
+
+// SeatingPlan is the room-wise seating of a center for one date and time slot
+type SeatingPlan struct {
+	Center   string
+	City     string
+	Date     string
+	Slot     string
+	Rooms    []RoomPlan
+	Unseated []ExamRegistration
+}
+
+// AllocateSeats assigns a room and seat to every registration at a center, replacing earlier
+// assignments, and returns the resulting plans. Candidates who do not fit are left unseated.
+func (h *ExamCenterHandler) AllocateSeats(actor, centerName string, opts SeatingOptions) ([]SeatingPlan, error) {
+	center, ok := h.findCenter(centerName)
+	if !ok {
+		return nil, fmt.Errorf("exam center '%s' not found", centerName)
+	}
+	rooms, err := h.CenterRooms(center.Name)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Interleave == "" {
+		opts.Interleave = InterleaveNone
+	}
+	if opts.Interleave != InterleaveNone && opts.Interleave != InterleaveExam && opts.Interleave != InterleaveShift {
+		return nil, fmt.Errorf("unknown interleave mode '%s' (use none, exam or shift)", opts.Interleave)
+	}
+
+	// Candidates sharing a seat layout: one session per slot, or per day when shifts are interleaved
+	sessions := make(map[string][]int)
+	var keys []string
+	for i, reg := range h.registrations {
+		if reg.AssignedCenter != center.Name {
+			continue
+		}
+		h.registrations[i].Room, h.registrations[i].SeatNumber = "", ""
+		key := reg.ExamDate + "|" + reg.TimeSlot
+		if opts.Interleave == InterleaveShift {
+			key = reg.ExamDate
+		}
+		if _, ok := sessions[key]; !ok {
+			keys = append(keys, key)
+		}
+		sessions[key] = append(sessions[key], i)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		order := h.interleave(sessions[key], opts.Interleave)
+		n := 0
+		for _, room := range rooms {
+			for row := 0; row < room.Rows; row++ {
+				for seat := 0; seat < room.SeatsPerRow && n < len(order); seat++ {
+					if idx := order[n]; idx >= 0 {
+						h.registrations[idx].Room = room.Name
+						h.registrations[idx].SeatNumber = SeatLabel(row, seat)
+					}
+					n++
+				}
+			}
+		}
+	}
+	plans, err := h.SeatingPlans(center.Name)
+	if err != nil {
+		return nil, err
+	}
+	seated, unseated := 0, 0
+	for _, plan := range plans {
+		for _, rp := range plan.Rooms {
+			seated += len(rp.Seats)
+		}
+		unseated += len(plan.Unseated)
+	}
+	details := fmt.Sprintf("interleave=%s, %d seated, %d unseated", opts.Interleave, seated, unseated)
+	return plans, h.commit(actor, "seating.allocate", center.Name, details)
+}
+
+// interleave orders registration indexes so neighbouring seats go to different groups.
+// With the shift mode each slot keeps its own seat positions, so -1 marks seats left empty.
+func (h *ExamCenterHandler) interleave(indexes []int, mode string) []int {
+	groupOf := func(reg ExamRegistration) string {
+		switch mode {
+		case InterleaveExam:
+			return reg.ExamType.Code
+		case InterleaveShift:
+			return reg.TimeSlot
+		}
+		return ""
+	}
+	groups := make(map[string][]int)
+	var names []string
+	for _, idx := range indexes {
+		g := groupOf(h.registrations[idx])
+		if _, ok := groups[g]; !ok {
+			names = append(names, g)
+		}
+		groups[g] = append(groups[g], idx)
+	}
+	sort.Strings(names)
+	for _, g := range names {
+		list := groups[g]
+		sort.SliceStable(list, func(i, j int) bool {
+			return h.registrations[list[i]].RollNumber < h.registrations[list[j]].RollNumber
+		})
+	}
+
+	var order []int
+	for round := 0; ; round++ {
+		placed := false
+		for _, g := range names {
+			switch {
+			case round < len(groups[g]):
+				order = append(order, groups[g][round])
+				placed = true
+			case mode == InterleaveShift && len(names) > 1:
+				order = append(order, -1) // keep the other shifts' positions fixed
+			}
+		}
+		if !placed {
+			break
+		}
+	}
+	// Trailing gaps hold no one
+	for len(order) > 0 && order[len(order)-1] < 0 {
+		order = order[:len(order)-1]
+	}
+	return order
+}
+
+// SeatingPlans rebuilds the seating plans of a center from the stored seat assignments
+func (h *ExamCenterHandler) SeatingPlans(centerName string) ([]SeatingPlan, error) {
+	center, ok := h.findCenter(centerName)
+	if !ok {
+		return nil, fmt.Errorf("exam center '%s' not found", centerName)
+	}
+	rooms, err := h.CenterRooms(center.Name)
+	if err != nil {
+		return nil, err
+	}
+	plans := make(map[string]*SeatingPlan)
+	var keys []string
+	for _, reg := range h.registrations {
+		if reg.AssignedCenter != center.Name {
+			continue
+		}
+		key := reg.ExamDate + "|" + reg.TimeSlot
+		plan, ok := plans[key]
+		if !ok {
+			plan = &SeatingPlan{Center: center.Name, City: center.City, Date: reg.ExamDate, Slot: reg.TimeSlot}
+			for _, room := range rooms {
+				plan.Rooms = append(plan.Rooms, RoomPlan{Room: room})
+			}
+			plans[key] = plan
+			keys = append(keys, key)
+		}
+		placed := false
+		for i := range plan.Rooms {
+			if plan.Rooms[i].Room.Name != reg.Room {
+				continue
+			}
+			if row, seat, ok := parseSeatLabel(reg.SeatNumber); ok {
+				plan.Rooms[i].Seats = append(plan.Rooms[i].Seats, SeatAssignment{Row: row, Seat: seat, SeatNumber: reg.SeatNumber, Registration: reg})
+				placed = true
+			}
+		}
+		if !placed {
+			plan.Unseated = append(plan.Unseated, reg)
+		}
+	}
+	sort.Strings(keys)
+	result := make([]SeatingPlan, 0, len(keys))
+	for _, key := range keys {
+		plan := plans[key]
+		for r := range plan.Rooms {
+			seats := plan.Rooms[r].Seats
+			sort.Slice(seats, func(i, j int) bool {
+				if seats[i].Row != seats[j].Row {
+					return seats[i].Row < seats[j].Row
+				}
+				return seats[i].Seat < seats[j].Seat
+			})
+		}
+		result = append(result, *plan)
+	}
+	return result, nil
+}
+
+// parseSeatLabel is the inverse of SeatLabel
+func parseSeatLabel(label string) (row, seat int, ok bool) {
+	if strings.HasPrefix(label, "R") && strings.Contains(label, "-") {
+		r, s, _ := strings.Cut(label[1:], "-")
+		ri, err1 := strconv.Atoi(r)
+		si, err2 := strconv.Atoi(s)
+		return ri - 1, si - 1, err1 == nil && err2 == nil
+	}
+	if len(label) < 2 || label[0] < 'A' || label[0] > 'Z' {
+		return 0, 0, false
+	}
+	si, err := strconv.Atoi(label[1:])
+	return int(label[0] - 'A'), si - 1, err == nil
+}
+
+// DisplaySeatingPlans prints room-wise seating charts and door lists
+func (h *ExamCenterHandler) DisplaySeatingPlans(plans []SeatingPlan) {
+	if len(plans) == 0 {
+		fmt.Println("No registrations at this center.")
+		return
+	}
+	for _, plan := range plans {
+		fmt.Println("\n" + strings.Repeat("=", 70))
+		fmt.Printf("SEATING PLAN: %s, %s\n", plan.Center, plan.City)
+		fmt.Printf("Date: %s | Slot: %s\n", plan.Date, plan.Slot)
+		fmt.Println(strings.Repeat("=", 70))
+		for _, rp := range plan.Rooms {
+			if len(rp.Seats) == 0 {
+				continue
+			}
+			fmt.Printf("\n🚪 %s (%d candidates, %d seats)\n", rp.Room.Name, len(rp.Seats), rp.Room.Capacity())
+			for row := 0; row < rp.Room.Rows; row++ {
+				fmt.Print("   ")
+				for seat := 0; seat < rp.Room.SeatsPerRow; seat++ {
+					cell := "-"
+					if s, ok := rp.At(row, seat); ok {
+						cell = s.Registration.RollNumber
+					}
+					fmt.Printf("%-4s %-14.14s", SeatLabel(row, seat), cell)
+				}
+				fmt.Println()
+			}
+			fmt.Println("   Door list:")
+			for _, s := range rp.DoorList() {
+				fmt.Printf("   %-6s %-16s %s (%s)\n", s.SeatNumber, s.Registration.RollNumber, s.Registration.StudentName, s.Registration.ExamType.Code)
+			}
+		}
+		if len(plan.Unseated) > 0 {
+			fmt.Printf("\n⚠️  %d candidates could not be seated - declare more rooms\n", len(plan.Unseated))
+		}
+	}
+}
+
+// ProcessSeatAllocation asks for a center and interleave mode, allocates seats and prints the plans
+func (h *ExamCenterHandler) ProcessSeatAllocation() error {
+	centerName, err := h.GetUserInput("Exam center name: ")
+	if err != nil {
+		return fmt.Errorf("error reading center: %v", err)
+	}
+	center, ok := h.findCenter(centerName)
+	if !ok {
+		return fmt.Errorf("exam center '%s' not found", centerName)
+	}
+	spec, err := h.GetUserInput("Rooms, e.g. \"Hall A:6x5, Hall B:8x6\" [default: keep current]: ")
+	if err != nil {
+		return err
+	}
+	if spec != "" {
+		rooms, err := ParseRoomSpec(spec)
+		if err != nil {
+			return err
+		}
+		if err := h.SetCenterRooms("cli", center.Name, rooms); err != nil {
+			return err
+		}
+	}
+	mode, err := h.GetUserInput("Interleave (none/exam/shift) [default: none]: ")
+	if err != nil {
+		return err
+	}
+	plans, err := h.AllocateSeats("cli", center.Name, SeatingOptions{Interleave: strings.ToLower(mode)})
+	if err != nil {
+		return err
+	}
+	h.DisplaySeatingPlans(plans)
+	return nil
+} 
\ No newline at end of file
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+// seatingHandler keeps its state in a temporary directory, so commits reach the audit log
+func seatingHandler(t *testing.T, regs ...ExamRegistration) *ExamCenterHandler {
+	t.Helper()
+	h, err := OpenExamCenterHandler(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, reg := range regs {
+		reg.AssignedCenter, reg.AssignedCity = "Pune University Center", "Pune"
+		h.registrations = append(h.registrations, reg)
+	}
+	return h
+}
+
+func TestAllocateSeats(t *testing.T) {
+	morning, afternoon := "09:30-12:30", "14:30-17:30"
+	reg := func(roll, exam, slot string) ExamRegistration {
+		return ExamRegistration{ID: exam + "-" + roll, RollNumber: roll, ExamType: ExamType{Code: exam}, ExamDate: "2024-06-02", TimeSlot: slot}
+	}
+	tests := []struct {
+		name       string
+		rooms      string
+		interleave string
+		regs       []ExamRegistration
+		seats      map[string]string // registration ID -> "room seat"; missing IDs are unseated
+		unseated   int
+	}{
+		{
+			name:  "in roll order",
+			rooms: "Hall A:1x2, Hall B:1x2",
+			regs:  []ExamRegistration{reg("R3", "UPSC", morning), reg("R1", "UPSC", morning), reg("R2", "UPSC", morning)},
+			seats: map[string]string{"UPSC-R1": "Hall A A1", "UPSC-R2": "Hall A A2", "UPSC-R3": "Hall B A1"},
+		},
+		{
+			name:     "overflow left unseated",
+			rooms:    "Hall A:1x2",
+			regs:     []ExamRegistration{reg("R1", "UPSC", morning), reg("R2", "UPSC", morning), reg("R3", "UPSC", morning)},
+			seats:    map[string]string{"UPSC-R1": "Hall A A1", "UPSC-R2": "Hall A A2"},
+			unseated: 1,
+		},
+		{
+			name:       "exams alternate",
+			rooms:      "Hall A:2x2",
+			interleave: InterleaveExam,
+			regs:       []ExamRegistration{reg("R1", "UPSC", morning), reg("R2", "UPSC", morning), reg("G1", "GATE", morning)},
+			seats:      map[string]string{"GATE-G1": "Hall A A1", "UPSC-R1": "Hall A A2", "UPSC-R2": "Hall A B1"},
+		},
+		{
+			name:       "shifts keep their own seats",
+			rooms:      "Hall A:2x2",
+			interleave: InterleaveShift,
+			// Both shifts share the layout; the afternoon's missing second candidate leaves B2 empty
+			// and pushes the morning's third candidate out of the room
+			regs:     []ExamRegistration{reg("R1", "UPSC", morning), reg("R2", "UPSC", morning), reg("R3", "UPSC", morning), reg("A1", "UPSC", afternoon)},
+			seats:    map[string]string{"UPSC-R1": "Hall A A1", "UPSC-A1": "Hall A A2", "UPSC-R2": "Hall A B1"},
+			unseated: 1,
+		},
+	}
+	for _, tt := range tests {
+		h := seatingHandler(t, tt.regs...)
+		rooms, err := ParseRoomSpec(tt.rooms)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := h.SetCenterRooms("admin", "Pune University Center", rooms); err != nil {
+			t.Fatal(err)
+		}
+		plans, err := h.AllocateSeats("admin", "Pune University Center", SeatingOptions{Interleave: tt.interleave})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		seats := make(map[string]string)
+		for _, reg := range h.registrations {
+			if reg.Room != "" {
+				seats[reg.ID] = reg.Room + " " + reg.SeatNumber
+			}
+		}
+		if !reflect.DeepEqual(seats, tt.seats) {
+			t.Errorf("%s: seats %v, want %v", tt.name, seats, tt.seats)
+		}
+		unseated := 0
+		for _, plan := range plans {
+			unseated += len(plan.Unseated)
+		}
+		if unseated != tt.unseated {
+			t.Errorf("%s: %d unseated, want %d", tt.name, unseated, tt.unseated)
+		}
+	}
+}
+
+func TestSeatingChangesAreAudited(t *testing.T) {
+	h := seatingHandler(t, ExamRegistration{ID: "UPSC-R1", RollNumber: "R1", ExamDate: "2024-06-02", TimeSlot: "09:30-12:30"})
+	if err := h.SetCenterRooms("pune-supt", "Pune University Center", []Room{{Name: "Hall A", Rows: 1, SeatsPerRow: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.AllocateSeats("pune-supt", "Pune University Center", SeatingOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := h.AuditLog(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got [][3]string
+	for _, e := range entries {
+		got = append(got, [3]string{e.Actor, e.Action, e.Details})
+	}
+	want := [][3]string{ // newest first
+		{"pune-supt", "seating.allocate", "interleave=none, 1 seated, 0 unseated"},
+		{"pune-supt", "center.rooms", "Hall A:1x2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("audit log %v, want %v", got, want)
+	}
+} 
\ No newline at end of file
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateContacts normalizes an optional mobile number and email address
+func ValidateContacts(phone, email string) (string, string, error) {
+	if strings.TrimSpace(phone) != "" {
+		channel, value, err := NormalizeContact(phone)
+		if err != nil {
+			return "", "", err
+		}
+		if channel != ChannelSMS {
+			return "", "", fmt.Errorf("'%s' is not a valid phone number", phone)
+		}
+		phone = value
+	}
+	if strings.TrimSpace(email) != "" {
+		if !strings.Contains(email, "@") {
+			return "", "", fmt.Errorf("'%s' is not a valid email address", strings.TrimSpace(email))
+		}
+		_, value, err := NormalizeContact(email)
+		if err != nil {
+			return "", "", err
+		}
+		email = value
+	}
+	return strings.TrimSpace(phone), strings.TrimSpace(email), nil
+}
+
+// registrationIndex returns the position of a registration in h.registrations
+func (h *ExamCenterHandler) registrationIndex(id string) (int, error) {
+	id = strings.TrimSpace(id)
+	for i, reg := range h.registrations {
+		if reg.ID == id {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("registration '%s' not found", id)
+}
+
+// releaseSeat returns a booked seat at a center to the available pool
+func (h *ExamCenterHandler) releaseSeat(center string) {
+	if capInfo, ok := h.centerCapacity[center]; ok && capInfo.BookedSeats > 0 {
+		capInfo.BookedSeats--
+		capInfo.AvailableSeats++
+		h.centerCapacity[center] = capInfo
+	}
+}
+
+// bookSeat takes one available seat at a center
+func (h *ExamCenterHandler) bookSeat(center string) {
+	if capInfo, ok := h.centerCapacity[center]; ok {
+		capInfo.AvailableSeats--
+		capInfo.BookedSeats++
+		h.centerCapacity[center] = capInfo
+	}
+}
+
+// UpdateRegistrationContact replaces the mobile number and email on a registration. At least one is required.
+func (h *ExamCenterHandler) UpdateRegistrationContact(actor, id, phone, email string) error {
+	i, err := h.registrationIndex(id)
+	if err != nil {
+		return err
+	}
+	phone, email, err = ValidateContacts(phone, email)
+	if err != nil {
+		return err
+	}
+	if phone == "" && email == "" {
+		return fmt.Errorf("give a mobile number or an email address")
+	}
+	h.registrations[i].Phone = phone
+	h.registrations[i].Email = email
+	return h.commit(actor, "registration.contact", h.registrations[i].ID, "")
+}
+
+// ChangeRegistrationCity moves a registration to a new home city and reassigns the nearest center with
+// free seats. The old seat is released and any room and seat allocation is cleared.
+func (h *ExamCenterHandler) ChangeRegistrationCity(actor, id, cityInput string) (ExamRegistration, error) {
+	i, err := h.registrationIndex(id)
+	if err != nil {
+		return ExamRegistration{}, err
+	}
+	reg := h.registrations[i]
+	homeCity, err := h.ValidateCity(cityInput)
+	if err != nil {
+		return reg, err
+	}
+	prefs := reg.Preferences
+	if prefs.MaxDistance <= 0 {
+		prefs.MaxDistance = 1000
+	}
+	policy, _ := h.AllocationPolicyFor(reg.ExamType.Code)
+	res, err := h.allocateAt(policy, homeCity, reg.ExamType, prefs, reg.ExamDate, reg.TimeSlot)
+	if err != nil {
+		return reg, err
+	}
+	nearest := res.Nearest
+	if len(nearest) == 0 {
+		return reg, fmt.Errorf("no exam center with free seats within %.0f km of %s", prefs.MaxDistance, homeCity)
+	}
+	best := nearest[0]
+	if best.Centers[0].Name != reg.AssignedCenter {
+		try := reg
+		try.AssignedCenter = best.Centers[0].Name
+		if _, _, ok := h.chooseSlot(try); !ok {
+			return reg, fmt.Errorf("%s is closed for every sitting of %s", try.AssignedCenter, reg.ExamType.Label())
+		}
+	}
+	previous := reg
+	details := fmt.Sprintf("home %s -> %s, center %s -> %s", reg.StudentCity, homeCity, reg.AssignedCenter, best.Centers[0].Name)
+	reg.Explanation = h.explainAssignment(homeCity, reg.ExamType, prefs, best.Centers[0].Name, reg.ExamDate, reg.TimeSlot)
+	if best.Centers[0].Name != reg.AssignedCenter {
+		h.releaseSeat(reg.AssignedCenter)
+		h.bookSeat(best.Centers[0].Name)
+		reg.AssignedCenter = best.Centers[0].Name
+		reg.AssignedCity = best.City.Name
+		reg.Room, reg.SeatNumber = "", ""
+		// The new city may be too far from the candidate's other exams for the old slot
+		if date, slot, _ := h.chooseSlot(reg); date != reg.ExamDate || slot != reg.TimeSlot {
+			details += fmt.Sprintf(", slot %s %s -> %s %s", reg.ExamDate, reg.TimeSlot, date, slot)
+			reg.ExamDate, reg.TimeSlot = date, slot
+		}
+	}
+	reg.StudentCity = homeCity
+	reg.Distance = best.Distance
+	h.registrations[i] = reg
+	if reg.AssignedCenter != previous.AssignedCenter {
+		h.emit(Event{Kind: EventRegistrationReassigned, Registration: reg, PreviousCenter: previous.AssignedCenter, PreviousCity: previous.AssignedCity})
+		h.PromoteWaitlist()
+	}
+	return reg, h.commit(actor, "registration.reassign", reg.ID, details)
+}
+
+// CancelRegistration withdraws a registration and frees its seat
+func (h *ExamCenterHandler) CancelRegistration(actor, id string) error {
+	i, err := h.registrationIndex(id)
+	if err != nil {
+		return err
+	}
+	reg := h.registrations[i]
+	h.releaseSeat(reg.AssignedCenter)
+	h.registrations = append(h.registrations[:i], h.registrations[i+1:]...)
+	delete(h.attendance, reg.ID)
+	h.emit(Event{Kind: EventRegistrationCancelled, Registration: reg})
+	h.PromoteWaitlist()
+	return h.commit(actor, "registration.cancel", reg.ID, reg.AssignedCenter)
+} 
\ No newline at end of file
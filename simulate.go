@@ -0,0 +1,468 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The capacity planner runs the allocation engine (policy.go) for forecast demand without registering
+// anyone. Candidates arrive in a random but repeatable order across their home cities and each takes
+// the first center the exam's policy offers, as a real registration would. The run works on its own
+// copy of the centers, seats and policies (see Simulator), so the live data is never touched and a
+// server can run it without holding up other requests. Simulated candidates are general candidates:
+// they cannot take seats held back for priority categories.
+
+// MaxSimulatedCandidates caps the demand of one simulation so that a run with both the current and the
+// planned seats finishes within seconds
+const MaxSimulatedCandidates = 200000
+
+// DemandColumns are the CSV header names of a demand forecast
+var DemandColumns = []string{"home_city", "candidates"}
+
+// DemandForecast is the number of candidates expected from one home city
+type DemandForecast struct {
+	City       string
+	Candidates int
+}
+
+// SeatChange is a planned change to the seats of a city, e.g. 500 more seats in Patna
+type SeatChange struct {
+	City  string
+	Seats int
+}
+
+// SimulationScenario is what a planner wants to try
+type SimulationScenario struct {
+	Exam         string // exam code; its current edition and allocation policy are used
+	Demand       []DemandForecast
+	AddSeats     []SeatChange // seats added in a planned center of each city
+	KeepBookings bool         // start from the seats still free instead of empty centers
+	MaxDistance  float64      // the candidates' own limit in km; DefaultImportDistance when 0
+	Seed         int64        // arrival order; the same seed gives the same result
+}
+
+// SimulatedHome is how the candidates of one home city fared
+type SimulatedHome struct {
+	City        string
+	Demand      int
+	Allocated   int
+	Unallocated int
+	Preferred   string // the exam city the policy offers first when seats are unlimited
+	InPreferred int    // candidates seated there
+	Distance    DistanceStats
+}
+
+// SimulatedCity is how one exam city coped with the candidates
+type SimulatedCity struct {
+	City     string
+	Seats    int // seats open when the run started, planned ones included
+	Booked   int
+	FillPct  float64
+	Wanted   int // candidates whose preferred city this is
+	Overflow int // of them, those seated elsewhere or not at all: the seats short
+}
+
+// Overloaded reports whether more candidates wanted the city than it could seat
+func (c SimulatedCity) Overloaded() bool { return c.Overflow > 0 }
+
+// SimulationResult is the outcome of one run
+type SimulationResult struct {
+	Exam            string // label of the edition simulated
+	Candidates      int
+	Allocated       int
+	Unallocated     int
+	Seats           int
+	Distance        DistanceStats // of the candidates allocated
+	DistanceBuckets []Bucket
+	Homes           []SimulatedHome // most unallocated first
+	Cities          []SimulatedCity // most overflow first
+}
+
+// Overloaded returns the cities that could not seat everyone who wanted them
+func (r SimulationResult) Overloaded() []SimulatedCity {
+	var list []SimulatedCity
+	for _, c := range r.Cities {
+		if c.Overloaded() {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// Simulation compares the current capacity with the planned changes
+type Simulation struct {
+	Scenario SimulationScenario
+	Baseline SimulationResult
+	Changed  *SimulationResult // with AddSeats; nil when there are none
+}
+
+// ParseDemandCSV reads a demand forecast with the columns in DemandColumns, in any order. Rows for the
+// same city are added up.
+func (h *ExamCenterHandler) ParseDemandCSV(r io.Reader) ([]DemandForecast, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("the demand file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading header: %v", err)
+	}
+	col := make(map[string]int)
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range DemandColumns {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("missing column '%s' (the header needs %s)", name, strings.Join(DemandColumns, ", "))
+		}
+	}
+	var demand []DemandForecast
+	index := make(map[string]int) // city -> position in demand
+	for row := 2; ; row++ {
+		rec, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", row, err)
+		}
+		if strings.Join(rec, "") == "" {
+			continue
+		}
+		field := func(name string) string {
+			if i := col[name]; i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		city, err := h.ValidateCity(field("home_city"))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", row, err)
+		}
+		n, err := strconv.Atoi(field("candidates"))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("row %d: candidates '%s' is not a whole number", row, field("candidates"))
+		}
+		if i, ok := index[city]; ok {
+			demand[i].Candidates += n
+			continue
+		}
+		index[city] = len(demand)
+		demand = append(demand, DemandForecast{City: city, Candidates: n})
+	}
+	return demand, nil
+}
+
+// ParseSeatChanges reads planned seats written as "Patna:500, Gaya:200". Seats for the same city are added up.
+func (h *ExamCenterHandler) ParseSeatChanges(s string) ([]SeatChange, error) {
+	var changes []SeatChange
+	index := make(map[string]int) // city -> position in changes
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, seats, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("'%s' is not CITY:SEATS, e.g. Patna:500", strings.TrimSpace(part))
+		}
+		city, found := h.findCity(name)
+		if !found {
+			return nil, fmt.Errorf("city '%s' not found", strings.TrimSpace(name))
+		}
+		if city.Disabled {
+			return nil, fmt.Errorf("%s is disabled, so seats there are never offered", city.Name)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(seats))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("seats to add in %s must be a positive number, not '%s'", city.Name, strings.TrimSpace(seats))
+		}
+		if i, ok := index[city.Name]; ok {
+			changes[i].Seats += n
+			continue
+		}
+		index[city.Name] = len(changes)
+		changes = append(changes, SeatChange{City: city.Name, Seats: n})
+	}
+	return changes, nil
+}
+
+// Simulator is a scenario ready to run on its own copy of the data the allocation engine reads. Once
+// made it shares nothing with the handler, so Run needs no lock.
+type Simulator struct {
+	scenario SimulationScenario
+	exam     ExamType
+	policy   AllocationPolicy
+	data     *ExamCenterHandler // the copy, with the seats the runs start from
+	used     map[string]int     // quota use of the bookings kept; nil when the policy has no quotas
+}
+
+// NewSimulator checks a scenario and copies what it needs to run
+func (h *ExamCenterHandler) NewSimulator(sc SimulationScenario) (*Simulator, error) {
+	ex, ok := h.currentEdition(sc.Exam)
+	if !ok {
+		return nil, fmt.Errorf("exam type '%s' not found", sc.Exam)
+	}
+	total := 0
+	for _, d := range sc.Demand {
+		if _, ok := h.cities[d.City]; !ok {
+			return nil, fmt.Errorf("home city '%s' not found", d.City)
+		}
+		total += d.Candidates
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("the forecast has no candidates")
+	}
+	if total > MaxSimulatedCandidates {
+		return nil, fmt.Errorf("the forecast has %d candidates; at most %d can be simulated", total, MaxSimulatedCandidates)
+	}
+	if sc.MaxDistance <= 0 {
+		sc.MaxDistance = DefaultImportDistance
+	}
+	policy, _ := h.AllocationPolicyFor(ex.Code)
+	s := &Simulator{scenario: sc, exam: ex, policy: policy, data: h.planningCopy(sc.KeepBookings)}
+	if sc.KeepBookings {
+		s.used = h.quotaUse(policy, ex)
+	} else if len(policy.Quotas) > 0 {
+		s.used = make(map[string]int)
+	}
+	return s, nil
+}
+
+// Run runs the scenario against the current capacity and, when it adds seats, again with them
+func (s *Simulator) Run() Simulation {
+	sim := Simulation{Scenario: s.scenario, Baseline: s.run(nil)}
+	if len(s.scenario.AddSeats) > 0 {
+		changed := s.run(s.scenario.AddSeats)
+		sim.Changed = &changed
+	}
+	return sim
+}
+
+// Simulate runs the scenario against the current capacity and, when it adds seats, again with them
+func (h *ExamCenterHandler) Simulate(sc SimulationScenario) (Simulation, error) {
+	s, err := h.NewSimulator(sc)
+	if err != nil {
+		return Simulation{}, err
+	}
+	return s.Run(), nil
+}
+
+// planningCopy copies the cities, centers, seats, clusters, exams, policies and closures. The seats
+// are emptied unless bookings are kept. Registrations are left out: a simulation keeps its own quota
+// counts instead.
+func (h *ExamCenterHandler) planningCopy(keepBookings bool) *ExamCenterHandler {
+	c := &ExamCenterHandler{
+		cities:         make(map[string]City, len(h.cities)),
+		examCenters:    make(map[string][]ExamCenter, len(h.examCenters)),
+		centerCapacity: make(map[string]CenterCapacity, len(h.centerCapacity)),
+		clusters:       make([]MetroCluster, len(h.clusters)),
+		exams:          append([]ExamType(nil), h.exams...),
+		policies:       make(map[string]AllocationPolicy, len(h.policies)),
+		closures:       append([]CenterClosure(nil), h.closures...),
+	}
+	for name, city := range h.cities {
+		c.cities[name] = city
+	}
+	for city, list := range h.examCenters {
+		c.examCenters[city] = append([]ExamCenter(nil), list...)
+	}
+	for name, capInfo := range h.centerCapacity {
+		if !keepBookings {
+			capInfo.BookedSeats, capInfo.AvailableSeats = 0, capInfo.TotalSeats
+		}
+		c.centerCapacity[name] = capInfo
+	}
+	for i, cl := range h.clusters {
+		c.clusters[i] = MetroCluster{Name: cl.Name, Cities: append([]string(nil), cl.Cities...)}
+	}
+	for code, p := range h.policies {
+		c.policies[code] = p
+	}
+	return c
+}
+
+// run places every forecast candidate on a fresh copy of the simulator's seats, with the planned seats in add
+func (s *Simulator) run(add []SeatChange) SimulationResult {
+	h, ex, sc, policy := s.data.planningCopy(true), s.exam, s.scenario, s.policy
+	for _, c := range add {
+		planned := ExamCenter{Name: c.City + " planned seats", City: c.City}
+		h.examCenters[c.City] = append(h.examCenters[c.City], planned)
+		capInfo := h.centerCapacity[planned.Name]
+		capInfo.TotalSeats += c.Seats
+		capInfo.AvailableSeats += c.Seats
+		h.centerCapacity[planned.Name] = capInfo
+	}
+	used := maps.Clone(s.used)
+
+	prefs := StudentPreference{MaxDistance: sc.MaxDistance, PreferredTransport: "any"}
+	date, slot := firstSitting(ex)
+	res := SimulationResult{Exam: ex.Label()}
+	cities := make(map[string]*SimulatedCity)
+	cityOf := func(name string) *SimulatedCity {
+		if c, ok := cities[name]; ok {
+			return c
+		}
+		c := &SimulatedCity{City: name}
+		cities[name] = c
+		return c
+	}
+	for city := range h.examCenters {
+		if h.cities[city].Disabled {
+			continue
+		}
+		for _, c := range h.activeCenters(city) {
+			seats := h.freeSeatsFor(c, prefs)
+			if seats > 0 {
+				cityOf(city).Seats += seats
+				res.Seats += seats
+			}
+		}
+	}
+
+	// The city each home city's candidates would get if seats were unlimited
+	unlimited := policy
+	unlimited.Filters, unlimited.Quotas = nil, nil
+	for _, f := range policy.Filters {
+		if f.Rule != FilterHasSeats {
+			unlimited.Filters = append(unlimited.Filters, f)
+		}
+	}
+	homes := make(map[string]*SimulatedHome)
+	distances := make(map[string][]float64)
+	var arrivals []string
+	for _, d := range sc.Demand {
+		if d.Candidates == 0 {
+			continue
+		}
+		home, ok := homes[d.City]
+		if !ok {
+			home = &SimulatedHome{City: d.City}
+			if a, err := h.allocate(unlimited, d.City, ex, prefs); err == nil && len(a.Nearest) > 0 {
+				home.Preferred = a.Nearest[0].City.Name
+			}
+			homes[d.City] = home
+		}
+		home.Demand += d.Candidates
+		for i := 0; i < d.Candidates; i++ {
+			arrivals = append(arrivals, d.City)
+		}
+	}
+	rng := rand.New(rand.NewSource(sc.Seed))
+	rng.Shuffle(len(arrivals), func(i, j int) { arrivals[i], arrivals[j] = arrivals[j], arrivals[i] })
+
+	// The engine ranks a home city's centers when its first candidate arrives, without the exam's limit
+	// on cities since further cities are offered once the first ones fill. The ranking is kept for the
+	// later candidates: bookings and quota use only ever shut centers or make them fuller, so they take
+	// the best of the ranked centers still open. Only a policy that looks at how full a center is needs
+	// them rescored; otherwise the first one open is the best.
+	byFill := policy.Weights.FillPercent > 0 || hasString(policy.TieBreakers, TieFillPercent) || hasString(policy.TieBreakers, TieFreeSeats)
+	uncapped := ex
+	uncapped.MaxCenters = 0
+	open := func(c ExamCenter) bool {
+		if h.freeSeatsFor(c, prefs) <= 0 {
+			return false
+		}
+		for _, q := range policy.Quotas {
+			if h.quotaReason(q, ex, c, used) != "" {
+				return false
+			}
+		}
+		return true
+	}
+	ranked := make(map[string][]offer) // home city -> its centers still open, in rank order
+	for _, homeCity := range arrivals {
+		home := homes[homeCity]
+		list, seen := ranked[homeCity]
+		if !seen {
+			if a, err := h.allocateUsing(policy, homeCity, uncapped, prefs, date, slot, used); err == nil {
+				list = a.offers
+			}
+		}
+		best := -1
+		if byFill {
+			kept := list[:0]
+			for _, o := range list {
+				if !open(o.verdict.Center) {
+					continue
+				}
+				h.score(policy, &o, prefs)
+				kept = append(kept, o)
+				if order, _ := policy.compare(o, kept[max(best, 0)]); best < 0 || order < 0 {
+					best = len(kept) - 1
+				}
+			}
+			list = kept
+		} else {
+			for len(list) > 0 && !open(list[0].verdict.Center) {
+				list = list[1:]
+			}
+			if len(list) > 0 {
+				best = 0
+			}
+		}
+		ranked[homeCity] = list
+		if best < 0 {
+			home.Unallocated++
+			if home.Preferred != "" {
+				cityOf(home.Preferred).Overflow++
+			}
+			continue
+		}
+		center := list[best].verdict.Center
+		h.bookSeat(center.Name)
+		if used != nil {
+			countQuotaUse(used, center.Name, center.City)
+		}
+		home.Allocated++
+		distances[homeCity] = append(distances[homeCity], list[best].verdict.Distance)
+		cityOf(center.City).Booked++
+		if center.City == home.Preferred {
+			home.InPreferred++
+		} else if home.Preferred != "" {
+			cityOf(home.Preferred).Overflow++
+		}
+	}
+
+	var all []float64
+	for name, home := range homes {
+		home.Distance = distanceStats(distances[name])
+		all = append(all, distances[name]...)
+		res.Candidates += home.Demand
+		res.Allocated += home.Allocated
+		res.Unallocated += home.Unallocated
+		if home.Preferred != "" {
+			cityOf(home.Preferred).Wanted += home.Demand
+		}
+		res.Homes = append(res.Homes, *home)
+	}
+	res.Distance = distanceStats(all)
+	res.DistanceBuckets = bucketize(all, distanceBucketEdges, " km")
+	sort.Slice(res.Homes, func(i, j int) bool {
+		if res.Homes[i].Unallocated != res.Homes[j].Unallocated {
+			return res.Homes[i].Unallocated > res.Homes[j].Unallocated
+		}
+		return res.Homes[i].City < res.Homes[j].City
+	})
+	for _, c := range cities {
+		if c.Seats > 0 {
+			c.FillPct = float64(c.Booked) / float64(c.Seats) * 100
+		}
+		res.Cities = append(res.Cities, *c)
+	}
+	sort.Slice(res.Cities, func(i, j int) bool {
+		if res.Cities[i].Overflow != res.Cities[j].Overflow {
+			return res.Cities[i].Overflow > res.Cities[j].Overflow
+		}
+		return res.Cities[i].City < res.Cities[j].City
+	})
+	return res
+} 
\ No newline at end of file
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSimulate(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    func(p *AllocationPolicy)
+		demand    int
+		add       []SeatChange
+		allocated int // -1 when it depends on the seats
+		perCenter int // most candidates any one center may get; 0 for no limit
+		wantErr   bool
+	}{
+		{name: "default policy", demand: 5000, allocated: 5000},
+		{name: "center quota", policy: func(p *AllocationPolicy) { p.Quotas = []PolicyQuota{{Per: QuotaCenter, Max: 40}} }, demand: 5000, allocated: -1, perCenter: 40},
+		{name: "fill weighted", policy: func(p *AllocationPolicy) { p.Weights.FillPercent = 2 }, demand: 5000, allocated: 5000},
+		{name: "planned seats", demand: 40000, add: []SeatChange{{City: "Patna", Seats: 500}}, allocated: -1},
+		{name: "no candidates", demand: 0, wantErr: true},
+		{name: "over the cap", demand: MaxSimulatedCandidates + 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		h := NewExamCenterHandler()
+		if tt.policy != nil {
+			p := DefaultPolicy("NEET")
+			tt.policy(&p)
+			if err := h.SetAllocationPolicy("admin", p); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+		}
+		capacity := make(map[string]CenterCapacity)
+		for name, c := range h.centerCapacity {
+			capacity[name] = c
+		}
+		sim, err := h.Simulate(SimulationScenario{Exam: "NEET", Demand: h.SyntheticDemand(tt.demand), AddSeats: tt.add, Seed: 1})
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: error %v, want one: %v", tt.name, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(h.centerCapacity, capacity) || len(h.registrations) != 0 {
+			t.Errorf("%s: the simulation changed the live data", tt.name)
+		}
+		runs := []SimulationResult{sim.Baseline}
+		if sim.Changed != nil {
+			runs = append(runs, *sim.Changed)
+		}
+		for _, res := range runs {
+			if res.Allocated+res.Unallocated != tt.demand || (tt.allocated >= 0 && res.Allocated != tt.allocated) {
+				t.Errorf("%s: %d allocated and %d not of %d", tt.name, res.Allocated, res.Unallocated, tt.demand)
+			}
+			for _, c := range res.Cities {
+				if c.Booked > c.Seats {
+					t.Errorf("%s: %s booked %d of %d seats", tt.name, c.City, c.Booked, c.Seats)
+				}
+				if tt.perCenter > 0 && c.Booked > tt.perCenter*len(h.examCenters[c.City]) {
+					t.Errorf("%s: %s booked %d, over the quota", tt.name, c.City, c.Booked)
+				}
+			}
+		}
+		if sim.Changed != nil && sim.Changed.Allocated <= sim.Baseline.Allocated {
+			t.Errorf("%s: planned seats allocated %d, no more than %d", tt.name, sim.Changed.Allocated, sim.Baseline.Allocated)
+		}
+	}
+}
+
+func TestSimulatorSharesNothingWithTheHandler(t *testing.T) {
+	h := NewExamCenterHandler()
+	s, err := h.NewSimulator(SimulationScenario{Exam: "NEET", Demand: h.SyntheticDemand(1000), Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := s.Run()
+	// Changes to the live data after the simulator is made do not reach it
+	for name, c := range h.centerCapacity {
+		c.AvailableSeats, c.BookedSeats = 0, c.TotalSeats
+		h.centerCapacity[name] = c
+	}
+	h.examCenters["Patna"] = nil
+	if got := s.Run(); !reflect.DeepEqual(got, want) {
+		t.Errorf("the run changed with the live data: %d allocated, want %d", got.Baseline.Allocated, want.Baseline.Allocated)
+	}
+} 
\ No newline at end of file
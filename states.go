@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UnknownState groups cities whose state is not on record
+const UnknownState = "Unknown"
+
+// IndianStates lists the states and union territories a city can belong to
+var IndianStates = []string{
+	"Andhra Pradesh", "Arunachal Pradesh", "Assam", "Bihar", "Chhattisgarh", "Goa", "Gujarat", "Haryana",
+	"Himachal Pradesh", "Jharkhand", "Karnataka", "Kerala", "Madhya Pradesh", "Maharashtra", "Manipur",
+	"Meghalaya", "Mizoram", "Nagaland", "Odisha", "Punjab", "Rajasthan", "Sikkim", "Tamil Nadu", "Telangana",
+	"Tripura", "Uttar Pradesh", "Uttarakhand", "West Bengal",
+	"Andaman and Nicobar Islands", "Chandigarh", "Dadra and Nagar Haveli and Daman and Diu", "Delhi",
+	"Jammu and Kashmir", "Ladakh", "Lakshadweep", "Puducherry",
+}
+
+// cityStates maps the built-in cities to their state or union territory
+var cityStates = map[string]string{
+	"Agra": "Uttar Pradesh", "Aligarh": "Uttar Pradesh", "Allahabad": "Uttar Pradesh", "Bareilly": "Uttar Pradesh",
+	"Ghaziabad": "Uttar Pradesh", "Kanpur": "Uttar Pradesh", "Lucknow": "Uttar Pradesh", "Meerut": "Uttar Pradesh",
+	"Moradabad": "Uttar Pradesh", "Varanasi": "Uttar Pradesh",
+	"Aurangabad": "Maharashtra", "Kalyan": "Maharashtra", "Mumbai": "Maharashtra", "Nagpur": "Maharashtra",
+	"Nashik": "Maharashtra", "Navi Mumbai": "Maharashtra", "Pune": "Maharashtra", "Solapur": "Maharashtra", "Vasai": "Maharashtra",
+	"Ahmedabad": "Gujarat", "Rajkot": "Gujarat", "Vadodara": "Gujarat",
+	"Jaipur": "Rajasthan", "Jodhpur": "Rajasthan", "Kota": "Rajasthan",
+	"Bhopal": "Madhya Pradesh", "Gwalior": "Madhya Pradesh", "Indore": "Madhya Pradesh", "Jabalpur": "Madhya Pradesh",
+	"Bangalore": "Karnataka", "Hubli": "Karnataka", "Mysore": "Karnataka",
+	"Chennai": "Tamil Nadu", "Coimbatore": "Tamil Nadu", "Madurai": "Tamil Nadu",
+	"Amritsar": "Punjab", "Jalandhar": "Punjab",
+	"Faridabad": "Haryana", "Gurgaon": "Haryana",
+	"Howrah": "West Bengal", "Kolkata": "West Bengal",
+	"Dhanbad": "Jharkhand", "Ranchi": "Jharkhand",
+	"Chandigarh": "Chandigarh", "Delhi": "Delhi", "Guwahati": "Assam", "Hyderabad": "Telangana", "Patna": "Bihar",
+	"Raipur": "Chhattisgarh", "Srinagar": "Jammu and Kashmir", "Vijayawada": "Andhra Pradesh",
+}
+
+// cityDistricts maps the built-in cities to their district; most share its name
+var cityDistricts = map[string]string{
+	"Mumbai": "Mumbai City", "Delhi": "New Delhi", "Bangalore": "Bengaluru Urban", "Kanpur": "Kanpur Nagar",
+	"Kalyan": "Thane", "Navi Mumbai": "Thane", "Vasai": "Palghar", "Allahabad": "Prayagraj", "Vijayawada": "NTR",
+	"Guwahati": "Kamrup Metropolitan", "Hubli": "Dharwad", "Mysore": "Mysuru", "Gurgaon": "Gurugram",
+}
+
+// seedRegion fills in the state and district of a built-in city that has no state yet
+func seedRegion(c City) City {
+	if c.State != "" {
+		return c
+	}
+	if c.State = cityStates[c.Name]; c.State != "" {
+		c.District = c.Name
+		if d, ok := cityDistricts[c.Name]; ok {
+			c.District = d
+		}
+	}
+	return c
+}
+
+// NormalizeState matches a state or union territory name case-insensitively. Empty stays empty.
+func NormalizeState(s string) (string, error) {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return "", nil
+	}
+	for _, st := range IndianStates {
+		if strings.EqualFold(st, s) {
+			return st, nil
+		}
+	}
+	return "", fmt.Errorf("'%s' is not a state or union territory", s)
+}
+
+// CityState returns the state or union territory of a city, or UnknownState
+func (h *ExamCenterHandler) CityState(city string) string {
+	if c, ok := h.cities[city]; ok && c.State != "" {
+		return c.State
+	}
+	return UnknownState
+} 
\ No newline at end of file
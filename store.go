@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StateFile holds the cities, centers, capacity and registrations inside the data directory
+const StateFile = "state.json"
+
+// StateLockFile exists inside the data directory while a process replaces the state file
+const StateLockFile = "state.lock"
+
+// How long Save waits for another process's lock, and how old a lock must be to count as abandoned
+const (
+	stateLockWait  = 5 * time.Second
+	staleStateLock = 30 * time.Second
+)
+
+// ErrStateChanged is returned by Save when another process saved the state after this one read it.
+// Nothing is written, so neither process's changes are lost; reload and make the change again.
+var ErrStateChanged = errors.New("the state file was changed by another process since it was read; nothing was saved, run the command again")
+
+// OutboxDir receives candidate messages inside the data directory unless another Notifier is set
+const OutboxDir = "outbox"
+
+// handlerState is the persisted form of an ExamCenterHandler
+type handlerState struct {
+	Cities         map[string]City
+	ExamCenters    map[string][]ExamCenter
+	CenterCapacity map[string]CenterCapacity
+	Registrations  []ExamRegistration
+	Attendance     map[string]AttendanceRecord
+	Users          map[string]User
+	Waitlist       []WaitlistEntry
+	ImportQueue    []QueuedCandidate
+	Deliveries     []Delivery
+	Webhooks       []WebhookSubscription
+	WebhookLog     []WebhookDelivery
+	MetroClusters  []MetroCluster
+	ExamTypes      []ExamType
+	Policies       map[string]AllocationPolicy
+	Closures       []CenterClosure
+	IDStamp        string
+	IDsIssued      map[string]int
+}
+
+// OpenExamCenterHandler loads the handler state from dir. On first use the
+// built-in cities and centers are seeded and written out.
+func OpenExamCenterHandler(dir string) (*ExamCenterHandler, error) {
+	h := NewExamCenterHandler()
+	h.dataDir = dir
+	h.notifier = OutboxNotifier{Dir: filepath.Join(dir, OutboxDir)}
+	h.Subscribe(h.notifyCandidate)
+	h.Subscribe(h.dispatchWebhooks)
+	st, info, err := readState(filepath.Join(dir, StateFile))
+	if os.IsNotExist(err) {
+		return h, h.Save()
+	}
+	if err != nil {
+		return nil, err
+	}
+	h.applyState(st, info)
+	return h, nil
+}
+
+// Reload reads the state file again if another process, such as a CLI command next to the web
+// server, has saved it since this handler last read or wrote it. Changes not saved yet are dropped.
+func (h *ExamCenterHandler) Reload() error {
+	if h.dataDir == "" {
+		return nil
+	}
+	path := filepath.Join(h.dataDir, StateFile)
+	if info, err := os.Stat(path); err != nil || h.sameState(info) {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	st, info, err := readState(path)
+	if err != nil {
+		return err
+	}
+	h.applyState(st, info)
+	return nil
+}
+
+// readState reads and decodes a state file, with the file's details as they were when it was read
+func readState(path string) (handlerState, os.FileInfo, error) {
+	var st handlerState
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return st, nil, err
+		}
+		return st, nil, fmt.Errorf("error reading state: %v", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return st, nil, fmt.Errorf("error reading state: %v", err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return st, nil, fmt.Errorf("error reading state: %v", err)
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return st, nil, fmt.Errorf("error decoding %s: %v", StateFile, err)
+	}
+	return st, info, nil
+}
+
+// applyState replaces the handler's data with a state read from info
+func (h *ExamCenterHandler) applyState(st handlerState, info os.FileInfo) {
+	h.stateInfo = info
+	h.cities = st.Cities
+	h.examCenters = st.ExamCenters
+	h.centerCapacity = st.CenterCapacity
+	h.registrations = st.Registrations
+	h.attendance = st.Attendance
+	h.users = st.Users
+	h.waitlist = st.Waitlist
+	h.importQueue = st.ImportQueue
+	h.deliveries = st.Deliveries
+	h.webhooks = st.Webhooks
+	h.webhookLog = st.WebhookLog
+	h.closures = st.Closures
+	h.idStamp, h.idsIssued = st.IDStamp, st.IDsIssued
+	h.clusters, h.exams, h.policies = seedClusters(), seedExamTypes(), make(map[string]AllocationPolicy)
+	if st.MetroClusters != nil {
+		h.clusters = st.MetroClusters
+	}
+	if st.ExamTypes != nil {
+		h.exams = st.ExamTypes
+	}
+	if st.Policies != nil {
+		h.policies = st.Policies
+	}
+	// State files from before exam types had editions or registrations had candidate IDs
+	h.backfillEditions()
+	h.backfillCandidates()
+	if h.cities == nil {
+		h.cities = make(map[string]City)
+	}
+	// State files from before cities had a state and district
+	for name, c := range h.cities {
+		h.cities[name] = seedRegion(c)
+	}
+	if h.examCenters == nil {
+		h.examCenters = make(map[string][]ExamCenter)
+	}
+	if h.centerCapacity == nil {
+		h.centerCapacity = make(map[string]CenterCapacity)
+	}
+	if h.attendance == nil {
+		h.attendance = make(map[string]AttendanceRecord)
+	}
+	if h.users == nil {
+		h.users = make(map[string]User)
+	}
+}
+
+// DataDir returns the directory the handler persists to, or "" for an in-memory handler
+func (h *ExamCenterHandler) DataDir() string { return h.dataDir }
+
+// Save writes the handler state to the data directory. It does nothing for an in-memory handler.
+func (h *ExamCenterHandler) Save() error {
+	if h.dataDir == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(handlerState{
+		Cities:         h.cities,
+		ExamCenters:    h.examCenters,
+		CenterCapacity: h.centerCapacity,
+		Registrations:  h.registrations,
+		Attendance:     h.attendance,
+		Users:          h.users,
+		Waitlist:       h.waitlist,
+		ImportQueue:    h.importQueue,
+		Deliveries:     h.deliveries,
+		Webhooks:       h.webhooks,
+		WebhookLog:     h.webhookLog,
+		MetroClusters:  h.clusters,
+		ExamTypes:      h.exams,
+		Policies:       h.policies,
+		Closures:       h.closures,
+		IDStamp:        h.idStamp,
+		IDsIssued:      h.idsIssued,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding state: %v", err)
+	}
+	if err := os.MkdirAll(h.dataDir, 0o755); err != nil {
+		return fmt.Errorf("error creating data directory: %v", err)
+	}
+	unlock, err := lockState(h.dataDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	path := filepath.Join(h.dataDir, StateFile)
+	if info, err := os.Stat(path); err == nil && !h.sameState(info) {
+		return ErrStateChanged
+	}
+	// Write to a temporary file first so a crash never leaves a truncated state file
+	tmp, err := os.CreateTemp(h.dataDir, StateFile+".*")
+	if err != nil {
+		return fmt.Errorf("error saving state: %v", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("error saving state: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("error saving state: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("error saving state: %v", err)
+	}
+	if h.stateInfo, err = os.Stat(path); err != nil {
+		return fmt.Errorf("error saving state: %v", err)
+	}
+	return nil
+}
+
+// sameState reports whether info describes the state file as this handler last read or wrote it.
+// Every save renames a new file into place, so a save by another process shows as another file.
+func (h *ExamCenterHandler) sameState(info os.FileInfo) bool {
+	seen := h.stateInfo
+	return seen != nil && os.SameFile(seen, info) && seen.Size() == info.Size() && seen.ModTime().Equal(info.ModTime())
+}
+
+// lockState takes the lock that keeps two processes from replacing the state file at once. The
+// returned func releases it. A lock older than staleStateLock is left over from a crash and is broken.
+func lockState(dir string) (func(), error) {
+	path := filepath.Join(dir, StateLockFile)
+	deadline := time.Now().Add(stateLockWait)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("error locking state: %v", err)
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleStateLock {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("error saving state: %s is held by another process", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+} 
\ No newline at end of file
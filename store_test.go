@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveRefusesToOverwriteAnotherProcess(t *testing.T) {
+	dir := t.TempDir()
+	server, err := OpenExamCenterHandler(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli, err := OpenExamCenterHandler(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.registrations = append(cli.registrations, ExamRegistration{ID: "NEET-R1-1"})
+	if err := cli.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	server.registrations = append(server.registrations, ExamRegistration{ID: "UPSC-U1-1"})
+	if err := server.Save(); !errors.Is(err, ErrStateChanged) {
+		t.Fatalf("saving over another process's save: %v, want ErrStateChanged", err)
+	}
+	if err := server.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.registrations) != 1 || server.registrations[0].ID != "NEET-R1-1" {
+		t.Fatalf("after reload the server has %+v, want the CLI's registration", server.registrations)
+	}
+	server.registrations = append(server.registrations, ExamRegistration{ID: "UPSC-U1-1"})
+	if err := server.Save(); err != nil {
+		t.Fatalf("saving after reload: %v", err)
+	}
+	// A reload with nothing new keeps what is in memory
+	if err := server.Reload(); err != nil || len(server.registrations) != 2 {
+		t.Fatalf("reload without changes: %v, %d registrations", err, len(server.registrations))
+	}
+	reopened, err := OpenExamCenterHandler(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reopened.registrations) != 2 {
+		t.Fatalf("state file has %d registrations, want both", len(reopened.registrations))
+	}
+}
+
+func TestSaveWaitsForTheStateLock(t *testing.T) {
+	dir := t.TempDir()
+	h, err := OpenExamCenterHandler(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock := filepath.Join(dir, StateLockFile)
+	if err := os.WriteFile(lock, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		os.Remove(lock)
+	}()
+	if err := h.Save(); err != nil {
+		t.Fatalf("save after the lock is released: %v", err)
+	}
+	// A lock left behind by a crash is broken
+	if err := os.WriteFile(lock, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleStateLock)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Save(); err != nil {
+		t.Fatalf("save over a stale lock: %v", err)
+	}
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		t.Fatalf("lock file left behind: %v", err)
+	}
+} 
\ No newline at end of file
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Bar is one bar of a chart
+type Bar struct {
+	Label string
+	Value float64
+	Note  string // shown after the value, e.g. "p95 410 km"
+}
+
+// BarChart is a horizontal bar chart rendered to SVG on the server
+type BarChart struct {
+	Title string
+	Unit  string  // appended to values, e.g. " km" or "%"
+	Max   float64 // scale maximum; 0 scales to the largest value
+	Bars  []Bar
+}
+
+// Chart layout in SVG user units
+const (
+	chartWidth     = 640
+	chartLabelW    = 170
+	chartValueW    = 150
+	chartBarH      = 18
+	chartGap       = 6
+	chartTitleH    = 26
+	chartBarColour = "#2563eb"
+)
+
+// SVG renders the chart as a standalone <svg> element that scales to its container
+func (c BarChart) SVG() string {
+	height := chartTitleH + len(c.Bars)*(chartBarH+chartGap) + chartGap
+	scale := c.Max
+	for _, b := range c.Bars {
+		scale = max(scale, b.Value)
+	}
+	var s strings.Builder
+	fmt.Fprintf(&s, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="100%%" role="img" aria-label="%s" font-family="sans-serif" font-size="12" fill="currentColor">`,
+		chartWidth, height, xmlEscape(c.Title))
+	fmt.Fprintf(&s, `<text x="0" y="16" font-size="14" font-weight="bold">%s</text>`, xmlEscape(c.Title))
+	barSpace := float64(chartWidth - chartLabelW - chartValueW)
+	for i, b := range c.Bars {
+		y := chartTitleH + i*(chartBarH+chartGap)
+		w := 0.0
+		if scale > 0 {
+			w = b.Value / scale * barSpace
+		}
+		value := strconv.FormatFloat(b.Value, 'f', -1, 64)
+		if b.Value != float64(int64(b.Value)) {
+			value = strconv.FormatFloat(b.Value, 'f', 1, 64)
+		}
+		label := value + c.Unit
+		if b.Note != "" {
+			label += " · " + b.Note
+		}
+		fmt.Fprintf(&s, `<text x="%d" y="%d" text-anchor="end">%s</text>`, chartLabelW-8, y+chartBarH-5, xmlEscape(b.Label))
+		fmt.Fprintf(&s, `<rect x="%d" y="%d" width="%.1f" height="%d" fill="%s" rx="2"><title>%s: %s</title></rect>`,
+			chartLabelW, y, w, chartBarH, chartBarColour, xmlEscape(b.Label), xmlEscape(label))
+		fmt.Fprintf(&s, `<text x="%.1f" y="%d" opacity="0.8">%s</text>`, float64(chartLabelW)+w+6, y+chartBarH-5, xmlEscape(label))
+	}
+	if len(c.Bars) == 0 {
+		fmt.Fprintf(&s, `<text x="0" y="%d" opacity="0.6">No data</text>`, chartTitleH+12)
+	}
+	s.WriteString("</svg>")
+	return s.String()
+} 
\ No newline at end of file
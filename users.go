@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Role decides what a user may see and change
+type Role string
+
+const (
+	RoleNationalAdmin  Role = "national_admin" // everything, including cities, centers and users
+	RoleExamAdmin      Role = "exam_admin"     // registrations and reports of the exam bodies in ExamCodes
+	RoleSuperintendent Role = "superintendent" // exam-day operations at one center
+	RoleCandidate      Role = "candidate"      // own registrations only
+)
+
+// Roles lists every role in order of decreasing reach
+var Roles = []Role{RoleNationalAdmin, RoleExamAdmin, RoleSuperintendent, RoleCandidate}
+
+// ParseRole converts a role name such as "superintendent" to a Role
+func ParseRole(s string) (Role, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, r := range Roles {
+		if string(r) == s {
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("unknown role '%s'", s)
+}
+
+// User is an account that can sign in to the web UI
+type User struct {
+	Username     string
+	Role         Role
+	PasswordHash string
+	ExamCodes    []string // exam admin scope
+	Center       string   // superintendent scope
+	RollNumber   string   // candidate scope
+	Contact      string   // candidate scope: the phone or email proven with a one-time code
+	Disabled     bool
+}
+
+// InScope reports whether the user may work with data of an exam at a center
+func (u User) InScope(examCode, center string) bool {
+	switch u.Role {
+	case RoleNationalAdmin:
+		return true
+	case RoleExamAdmin:
+		for _, code := range u.ExamCodes {
+			if strings.EqualFold(code, examCode) {
+				return true
+			}
+		}
+	case RoleSuperintendent:
+		return strings.EqualFold(u.Center, center)
+	}
+	return false
+}
+
+// CanSeeRegistration reports whether the user may view a registration and its admit card. Roll
+// numbers are only unique per exam, so a candidate sees a registration only when it also lists
+// the contact they proved they own.
+func (u User) CanSeeRegistration(reg ExamRegistration) bool {
+	if u.Role == RoleCandidate {
+		return u.RollNumber != "" && u.RollNumber == reg.RollNumber &&
+			u.Contact != "" && (u.Contact == reg.Phone || u.Contact == reg.Email)
+	}
+	return u.InScope(reg.ExamType.Code, reg.AssignedCenter)
+}
+
+// CanSeeWaitlistEntry applies the rules of CanSeeRegistration to a candidate still waiting for a seat
+func (u User) CanSeeWaitlistEntry(e WaitlistEntry) bool {
+	if u.Role == RoleCandidate {
+		return u.RollNumber != "" && u.RollNumber == e.Student.RollNumber &&
+			u.Contact != "" && (u.Contact == e.Student.Phone || u.Contact == e.Student.Email)
+	}
+	return u.InScope(e.ExamType.Code, "")
+}
+
+// CandidateContacts puts the candidate's verified contact in place of the phone or email they
+// typed, so the registrations they create or edit stay visible to them
+func (u User) CandidateContacts(phone, email string) (string, string) {
+	if u.Role != RoleCandidate || u.Contact == "" {
+		return phone, email
+	}
+	if strings.Contains(u.Contact, "@") {
+		return phone, u.Contact
+	}
+	return u.Contact, email
+}
+
+// Scope describes the user's scope for display
+func (u User) Scope() string {
+	switch u.Role {
+	case RoleExamAdmin:
+		return strings.Join(u.ExamCodes, ", ")
+	case RoleSuperintendent:
+		return u.Center
+	case RoleCandidate:
+		if u.Contact != "" {
+			return u.RollNumber + " / " + u.Contact
+		}
+		return u.RollNumber
+	}
+	return "all"
+}
+
+// HasUsers reports whether any account exists
+func (h *ExamCenterHandler) HasUsers() bool { return len(h.users) > 0 }
+
+// GetUser looks up an account by user name (case-insensitive)
+func (h *ExamCenterHandler) GetUser(username string) (User, bool) {
+	u, ok := h.users[strings.ToLower(strings.TrimSpace(username))]
+	return u, ok
+}
+
+// ListUsers returns all accounts sorted by role, then user name
+func (h *ExamCenterHandler) ListUsers() []User {
+	rank := make(map[Role]int)
+	for i, r := range Roles {
+		rank[r] = i
+	}
+	users := make([]User, 0, len(h.users))
+	for _, u := range h.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].Role != users[j].Role {
+			return rank[users[i].Role] < rank[users[j].Role]
+		}
+		return users[i].Username < users[j].Username
+	})
+	return users
+}
+
+// AddUser creates an account. The scope fields that do not apply to the role are ignored;
+// a candidate without a roll number signs in with the roll number as user name, and needs
+// the phone or email on their registrations to see them.
+func (h *ExamCenterHandler) AddUser(actor string, u User, password string) error {
+	u.Username = strings.TrimSpace(u.Username)
+	if u.Username == "" {
+		return fmt.Errorf("user name cannot be empty")
+	}
+	if strings.ContainsAny(u.Username, " \t:") {
+		return fmt.Errorf("user name cannot contain spaces or colons")
+	}
+	if _, exists := h.GetUser(u.Username); exists {
+		return fmt.Errorf("user '%s' already exists", u.Username)
+	}
+	scoped := User{Username: u.Username, Role: u.Role}
+	switch u.Role {
+	case RoleNationalAdmin:
+	case RoleExamAdmin:
+		for _, code := range u.ExamCodes {
+			code = strings.ToUpper(strings.TrimSpace(code))
+			if code == "" {
+				continue
+			}
+			if _, err := h.examCode(code); err != nil {
+				return err
+			}
+			scoped.ExamCodes = append(scoped.ExamCodes, code)
+		}
+		if len(scoped.ExamCodes) == 0 {
+			return fmt.Errorf("an exam admin needs at least one exam code")
+		}
+	case RoleSuperintendent:
+		center, ok := h.findCenter(u.Center)
+		if !ok {
+			return fmt.Errorf("exam center '%s' not found", u.Center)
+		}
+		scoped.Center = center.Name
+	case RoleCandidate:
+		scoped.RollNumber = strings.TrimSpace(u.RollNumber)
+		if scoped.RollNumber == "" {
+			scoped.RollNumber = u.Username
+		}
+		_, contact, err := NormalizeContact(u.Contact)
+		if err != nil {
+			return fmt.Errorf("a candidate account needs the mobile number or email on their registrations: %v", err)
+		}
+		scoped.Contact = contact
+	default:
+		return fmt.Errorf("unknown role '%s'", u.Role)
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	scoped.PasswordHash = hash
+	h.users[strings.ToLower(scoped.Username)] = scoped
+	return h.commit(actor, "user.add", scoped.Username, fmt.Sprintf("role=%s scope=%s", scoped.Role, scoped.Scope()))
+}
+
+// SetUserPassword replaces a user's password
+func (h *ExamCenterHandler) SetUserPassword(actor, username, password string) error {
+	u, ok := h.GetUser(username)
+	if !ok {
+		return fmt.Errorf("user '%s' not found", username)
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash = hash
+	h.users[strings.ToLower(u.Username)] = u
+	return h.commit(actor, "user.password", u.Username, "")
+}
+
+// SetUserDisabled blocks (or restores) sign-in for a user
+func (h *ExamCenterHandler) SetUserDisabled(actor, username string, disabled bool) error {
+	u, ok := h.GetUser(username)
+	if !ok {
+		return fmt.Errorf("user '%s' not found", username)
+	}
+	u.Disabled = disabled
+	h.users[strings.ToLower(u.Username)] = u
+	return h.commit(actor, disableAction("user", disabled), u.Username, "")
+}
+
+// ErrBadCredentials is returned for an unknown user, a wrong password or a disabled account
+var ErrBadCredentials = errors.New("invalid user name or password")
+
+// Authenticate checks a user name and password
+func (h *ExamCenterHandler) Authenticate(username, password string) (User, error) {
+	u, ok := h.GetUser(username)
+	if !ok {
+		// Hash anyway so unknown user names take as long to reject as wrong passwords
+		CheckPassword(dummyPasswordHash, password)
+		return User{}, ErrBadCredentials
+	}
+	if !CheckPassword(u.PasswordHash, password) || u.Disabled {
+		return User{}, ErrBadCredentials
+	}
+	return u, nil
+}
+
+// dummyPasswordHash has the same work factor as real hashes and matches no password
+var dummyPasswordHash = fmt.Sprintf("pbkdf2-sha256$%d$c2FsdHNhbHRzYWx0c2FsdA$AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA", passwordIterations)
+
+// RegistrationsFor returns the registrations a user may see, in registration order
+func (h *ExamCenterHandler) RegistrationsFor(u User) []ExamRegistration {
+	var regs []ExamRegistration
+	for _, reg := range h.registrations {
+		if u.CanSeeRegistration(reg) {
+			regs = append(regs, reg)
+		}
+	}
+	return regs
+} 
\ No newline at end of file
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// WaitlistEntry is a candidate waiting for a seat because no center had room
+type WaitlistEntry struct {
+	ID          string
+	Student     StudentInfo
+	ExamType    ExamType
+	HomeCity    string
+	Preferences StudentPreference
+	JoinedAt    time.Time
+}
+
+// JoinWaitlist queues a candidate for the next free seat within their preferences
+func (h *ExamCenterHandler) JoinWaitlist(student StudentInfo, examType ExamType, homeCity string, prefs StudentPreference) WaitlistEntry {
+	entry := WaitlistEntry{
+		ID:          "WL-" + h.generateRegistrationID(examType, student.RollNumber),
+		Student:     student,
+		ExamType:    examType,
+		HomeCity:    homeCity,
+		Preferences: prefs,
+		JoinedAt:    time.Now(),
+	}
+	h.waitlist = append(h.waitlist, entry)
+	return entry
+}
+
+// Waitlist returns the queued candidates, first come first
+func (h *ExamCenterHandler) Waitlist() []WaitlistEntry {
+	return append([]WaitlistEntry(nil), h.waitlist...)
+}
+
+// WaitlistFor returns the waiting entries the user may see
+func (h *ExamCenterHandler) WaitlistFor(u User) []WaitlistEntry {
+	var out []WaitlistEntry
+	for _, e := range h.waitlist {
+		if u.CanSeeWaitlistEntry(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// WaitlistPosition returns the 1-based queue position of an entry, or 0 when it is no longer waiting
+func (h *ExamCenterHandler) WaitlistPosition(id string) int {
+	for i, e := range h.waitlist {
+		if e.ID == id {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// PromoteWaitlist registers waiting candidates wherever seats have come free: priority candidates
+// first, then everyone else, each in queue order. It is called after anything that frees or adds seats.
+func (h *ExamCenterHandler) PromoteWaitlist() []ExamRegistration {
+	var promoted []ExamRegistration
+	order := append([]WaitlistEntry(nil), h.waitlist...)
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].Preferences.Priority() && !order[j].Preferences.Priority()
+	})
+	placed := make(map[string]bool)
+	for _, e := range order {
+		nearest, err := h.FindNearestCitiesAdvanced(e.HomeCity, e.ExamType, e.Preferences)
+		if err != nil || len(nearest) == 0 {
+			continue
+		}
+		reg := h.createRegistration(e.Student, e.ExamType, nearest[0], e.HomeCity, e.Preferences)
+		placed[e.ID] = true
+		promoted = append(promoted, reg)
+		h.emit(Event{Kind: EventWaitlistPromoted, Registration: reg})
+	}
+	remaining := h.waitlist[:0]
+	for _, e := range h.waitlist {
+		if !placed[e.ID] {
+			remaining = append(remaining, e)
+		}
+	}
+	h.waitlist = remaining
+	return promoted
+}
+
+// DisplayWaitlistEntry prints a waitlist confirmation
+func (h *ExamCenterHandler) DisplayWaitlistEntry(e WaitlistEntry) {
+	fmt.Printf("\n⏳ No center has free seats within %.0f km. You are number %d on the %s waitlist.\n", e.Preferences.MaxDistance, h.WaitlistPosition(e.ID), e.ExamType.Code)
+	fmt.Printf("Waitlist ID: %s\n", e.ID)
+	fmt.Println("You will be notified when a seat is assigned.")
+} 
\ No newline at end of file
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWaitlistForMatchesRollAndContact(t *testing.T) {
+	h := NewExamCenterHandler()
+	h.waitlist = []WaitlistEntry{
+		{ID: "WL-NEET-R100", ExamType: ExamType{Code: "NEET"}, Student: StudentInfo{RollNumber: "R100", Email: "asha@example.org"}},
+		{ID: "WL-JEE-R100", ExamType: ExamType{Code: "JEE"}, Student: StudentInfo{RollNumber: "R100", Phone: "9876543210"}},
+	}
+	tests := []struct {
+		name string
+		user User
+		want []string
+	}{
+		{"candidate with the email", User{Role: RoleCandidate, RollNumber: "R100", Contact: "asha@example.org"}, []string{"WL-NEET-R100"}},
+		{"candidate with the phone", User{Role: RoleCandidate, RollNumber: "R100", Contact: "9876543210"}, []string{"WL-JEE-R100"}},
+		{"candidate with another contact", User{Role: RoleCandidate, RollNumber: "R100", Contact: "other@example.org"}, nil},
+		{"candidate without a contact", User{Role: RoleCandidate, RollNumber: "R100"}, nil},
+		{"exam admin", User{Role: RoleExamAdmin, ExamCodes: []string{"JEE"}}, []string{"WL-JEE-R100"}},
+		{"national admin", User{Role: RoleNationalAdmin}, []string{"WL-NEET-R100", "WL-JEE-R100"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, e := range h.WaitlistFor(tt.user) {
+			got = append(got, e.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: sees %v, want %v", tt.name, got, tt.want)
+		}
+	}
+} 
\ No newline at end of file
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+type MyPageData struct {
+	Title         string
+	User          handlerpkg.User
+	Registrations []handlerpkg.ExamRegistration
+	Waitlist      []handlerpkg.WaitlistEntry
+	Clashes       []handlerpkg.Clash
+}
+
+// handleMyRegistrations lists the registrations the signed-in user may see
+func (s *Server) handleMyRegistrations(w http.ResponseWriter, r *http.Request) {
+	u, _ := currentUser(r)
+	data := MyPageData{Title: "My registrations — ExamCenterHub", User: u, Registrations: s.h.RegistrationsFor(u), Waitlist: s.h.WaitlistFor(u),
+		Clashes: s.h.Clashes(u.CanSeeRegistration)}
+	_ = s.t.ExecuteTemplate(w, "my.html", data)
+}
+
+type LoginCodePageData struct {
+	Title      string
+	RollNumber string
+	Contact    string
+	CodeSent   bool
+	Error      string
+}
+
+// handleLoginCode signs candidates in with a one-time code sent to the phone or email on their registration
+func (s *Server) handleLoginCode(w http.ResponseWriter, r *http.Request) {
+	data := LoginCodePageData{Title: "Sign in with a code — ExamCenterHub"}
+	if r.Method == http.MethodPost {
+		data.RollNumber = r.FormValue("roll_number")
+		data.Contact = r.FormValue("contact")
+		switch r.FormValue("action") {
+		case "send":
+			if err := s.h.RequestLoginCode(data.RollNumber, data.Contact); err != nil {
+				data.Error = err.Error()
+			} else {
+				data.CodeSent = true
+			}
+		case "verify":
+			data.CodeSent = true
+			u, err := s.h.VerifyLoginCode(data.RollNumber, data.Contact, r.FormValue("code"))
+			if err == nil {
+				err = s.startSession(w, r, session{roll: u.RollNumber, contact: u.Contact})
+			}
+			if err == nil {
+				http.Redirect(w, r, "/my", http.StatusSeeOther)
+				return
+			}
+			data.Error = err.Error()
+		}
+	}
+	_ = s.t.ExecuteTemplate(w, "login_code.html", data)
+}
+
+type ManagePageData struct {
+	Title         string
+	Registration  handlerpkg.ExamRegistration
+	ReportingTime string
+	Cities        []string
+	Message       string
+	Error         string
+	Map           template.HTML
+}
+
+// handleManageRegistration lets a candidate view one registration, update contact details,
+// move to another home city (which reassigns the center) or cancel
+func (s *Server) handleManageRegistration(w http.ResponseWriter, r *http.Request) {
+	u, _ := currentUser(r)
+	id := r.FormValue("id")
+	reg, err := s.h.GetRegistration(id)
+	if err != nil || !u.CanSeeRegistration(reg) {
+		http.Error(w, fmt.Sprintf("registration '%s' not found", id), http.StatusNotFound)
+		return
+	}
+	data := ManagePageData{Title: "Your registration — ExamCenterHub", Cities: s.h.GetAvailableCities()}
+	if r.Method == http.MethodPost {
+		switch r.FormValue("action") {
+		case "contact":
+			phone, email := u.CandidateContacts(r.FormValue("phone"), r.FormValue("email"))
+			if err = s.h.UpdateRegistrationContact(u.Username, reg.ID, phone, email); err == nil {
+				data.Message = "Contact details updated."
+			}
+		case "city":
+			var moved handlerpkg.ExamRegistration
+			if moved, err = s.h.ChangeRegistrationCity(u.Username, reg.ID, r.FormValue("home_city")); err == nil {
+				data.Message = fmt.Sprintf("Your exam center is now %s, %s. Download the new admit card.", moved.AssignedCenter, moved.AssignedCity)
+			}
+		case "cancel":
+			if err = s.h.CancelRegistration(u.Username, reg.ID); err == nil {
+				http.Redirect(w, r, "/my", http.StatusSeeOther)
+				return
+			}
+		default:
+			http.Error(w, "unknown action", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			data.Error = err.Error()
+		}
+		reg, _ = s.h.GetRegistration(reg.ID)
+	}
+	data.Registration = reg
+	data.ReportingTime = handlerpkg.ReportingTime(reg.TimeSlot)
+	data.Map = template.HTML(s.h.RegistrationMap(reg).SVG())
+	_ = s.t.ExecuteTemplate(w, "manage.html", data)
+} 
\ No newline at end of file
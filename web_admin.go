@@ -0,0 +1,268 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+type AdminPageData struct {
+	Title    string
+	User     string
+	Message  string
+	Error    string
+	City     string
+	Cities   []handlerpkg.City
+	States   []string
+	Clusters []handlerpkg.MetroCluster
+	Centers  []handlerpkg.CenterStatus
+	Totals   handlerpkg.CenterCapacity
+	Audit    []handlerpkg.AuditEntry
+}
+
+// randomPassword returns a password for when none is configured
+func randomPassword() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// handleAdmin shows cities, centers with live seat counts and the recent audit trail
+func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	data := AdminPageData{
+		Title:    "Admin — ExamCenterHub",
+		User:     userName(r),
+		Message:  q.Get("msg"),
+		Error:    q.Get("error"),
+		City:     q.Get("city"),
+		Cities:   s.h.ListCities(),
+		States:   handlerpkg.IndianStates,
+		Clusters: s.h.MetroClusters(),
+		Centers:  s.h.ListCenters(q.Get("city")),
+	}
+	for _, c := range data.Centers {
+		data.Totals.TotalSeats += c.Capacity.TotalSeats
+		data.Totals.BookedSeats += c.Capacity.BookedSeats
+		data.Totals.AvailableSeats += c.Capacity.AvailableSeats
+	}
+	audit, err := s.h.AuditLog(50)
+	if err != nil && data.Error == "" {
+		data.Error = err.Error()
+	}
+	data.Audit = audit
+	_ = s.t.ExecuteTemplate(w, "admin.html", data)
+}
+
+// handleAdminCity adds, moves, disables or re-enables a city, or sets its state and district
+func (s *Server) handleAdminCity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/admin", http.StatusSeeOther)
+		return
+	}
+	name := r.FormValue("name")
+	actor := userName(r)
+	var err error
+	switch r.FormValue("action") {
+	case "add", "update":
+		var lat, lng float64
+		if lat, lng, err = parseLatLng(r.FormValue("lat"), r.FormValue("lng")); err != nil {
+			break
+		}
+		if r.FormValue("action") == "add" {
+			err = s.h.AddCity(actor, name, r.FormValue("state"), r.FormValue("district"), lat, lng)
+		} else {
+			err = s.h.UpdateCityLocation(actor, name, lat, lng)
+		}
+	case "region":
+		err = s.h.SetCityRegion(actor, name, r.FormValue("state"), r.FormValue("district"))
+	case "disable":
+		err = s.h.SetCityDisabled(actor, name, true)
+	case "enable":
+		err = s.h.SetCityDisabled(actor, name, false)
+	default:
+		http.Error(w, "unknown action", http.StatusBadRequest)
+		return
+	}
+	adminRedirect(w, r, "", "City "+strings.TrimSpace(name)+" saved", err)
+}
+
+// handleAdminCluster creates, edits or removes a metro cluster
+func (s *Server) handleAdminCluster(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/admin", http.StatusSeeOther)
+		return
+	}
+	name := strings.TrimSpace(r.FormValue("name"))
+	var err error
+	switch r.FormValue("action") {
+	case "set":
+		err = s.h.SetMetroCluster(userName(r), name, strings.Split(r.FormValue("cities"), ","))
+	case "remove":
+		err = s.h.RemoveMetroCluster(userName(r), name)
+	default:
+		http.Error(w, "unknown action", http.StatusBadRequest)
+		return
+	}
+	msg := "Cluster " + name + " saved"
+	if r.FormValue("action") == "remove" {
+		msg = "Cluster " + name + " removed"
+	}
+	adminRedirect(w, r, "", msg, err)
+}
+
+// handleAdminCenter adds, renames, resizes, disables or re-enables an exam center
+func (s *Server) handleAdminCenter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/admin", http.StatusSeeOther)
+		return
+	}
+	name := strings.TrimSpace(r.FormValue("name"))
+	actor := userName(r)
+	var err error
+	switch r.FormValue("action") {
+	case "add":
+		var seats int
+		if seats, err = parseSeats(r.FormValue("total_seats")); err == nil {
+			err = s.h.AddCenter(actor, r.FormValue("city"), name, seats)
+		}
+	case "rename":
+		err = s.h.RenameCenter(actor, name, r.FormValue("new_name"))
+		name = strings.TrimSpace(r.FormValue("new_name"))
+	case "seats":
+		var seats int
+		if seats, err = parseSeats(r.FormValue("total_seats")); err == nil {
+			err = s.h.SetCenterTotalSeats(actor, name, seats)
+		}
+	case "disable":
+		err = s.h.SetCenterDisabled(actor, name, true)
+	case "enable":
+		err = s.h.SetCenterDisabled(actor, name, false)
+	case "access":
+		text := r.FormValue("priority_seats")
+		var seats int
+		if seats, err = strconv.Atoi(strings.TrimSpace(text)); err != nil {
+			err = errBadNumber("priority seats", text)
+		} else {
+			err = s.h.SetCenterAccess(actor, name, r.FormValue("accessible") != "", r.FormValue("scribes") != "", seats)
+		}
+	default:
+		http.Error(w, "unknown action", http.StatusBadRequest)
+		return
+	}
+	adminRedirect(w, r, r.FormValue("filter_city"), "Center "+name+" saved", err)
+}
+
+// adminRedirect returns to the admin page with a success or error message
+func adminRedirect(w http.ResponseWriter, r *http.Request, city, msg string, err error) {
+	v := url.Values{}
+	if city != "" {
+		v.Set("city", city)
+	}
+	if err != nil {
+		v.Set("error", err.Error())
+	} else {
+		v.Set("msg", msg)
+	}
+	http.Redirect(w, r, "/admin?"+v.Encode(), http.StatusSeeOther)
+}
+
+func parseLatLng(latText, lngText string) (float64, float64, error) {
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latText), 64)
+	if err != nil {
+		return 0, 0, errBadNumber("latitude", latText)
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(lngText), 64)
+	if err != nil {
+		return 0, 0, errBadNumber("longitude", lngText)
+	}
+	return lat, lng, nil
+}
+
+func parseSeats(text string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil {
+		return 0, errBadNumber("total seats", text)
+	}
+	return n, nil
+}
+
+func errBadNumber(field, text string) error {
+	return fmt.Errorf("%s '%s' is not a number", field, strings.TrimSpace(text))
+}
+
+type UsersPageData struct {
+	Title   string
+	User    string
+	Message string
+	Error   string
+	Users   []handlerpkg.User
+	Roles   []handlerpkg.Role
+	Exams   []handlerpkg.ExamType
+	Centers []handlerpkg.CenterStatus
+}
+
+// handleAdminUsers lists accounts; POST adds, disables, re-enables or resets the password of one
+func (s *Server) handleAdminUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		name := strings.TrimSpace(r.FormValue("username"))
+		actor := userName(r)
+		var err error
+		switch r.FormValue("action") {
+		case "add":
+			var role handlerpkg.Role
+			if role, err = handlerpkg.ParseRole(r.FormValue("role")); err == nil {
+				u := handlerpkg.User{
+					Username:   name,
+					Role:       role,
+					ExamCodes:  r.Form["exam_codes"],
+					Center:     r.FormValue("center"),
+					RollNumber: r.FormValue("roll_number"),
+					Contact:    r.FormValue("contact"),
+				}
+				err = s.h.AddUser(actor, u, r.FormValue("password"))
+			}
+		case "password":
+			err = s.h.SetUserPassword(actor, name, r.FormValue("password"))
+		case "disable":
+			if strings.EqualFold(name, actor) {
+				err = fmt.Errorf("you cannot disable your own account")
+			} else {
+				err = s.h.SetUserDisabled(actor, name, true)
+			}
+		case "enable":
+			err = s.h.SetUserDisabled(actor, name, false)
+		default:
+			http.Error(w, "unknown action", http.StatusBadRequest)
+			return
+		}
+		v := url.Values{}
+		if err != nil {
+			v.Set("error", err.Error())
+		} else {
+			v.Set("msg", "User "+name+" saved")
+		}
+		http.Redirect(w, r, "/admin/users?"+v.Encode(), http.StatusSeeOther)
+		return
+	}
+	q := r.URL.Query()
+	data := UsersPageData{
+		Title:   "Users — ExamCenterHub",
+		User:    userName(r),
+		Message: q.Get("msg"),
+		Error:   q.Get("error"),
+		Users:   s.h.ListUsers(),
+		Roles:   handlerpkg.Roles,
+		Exams:   s.h.GetExamTypes(),
+		Centers: s.h.ListCenters(""),
+	}
+	_ = s.t.ExecuteTemplate(w, "admin_users.html", data)
+} 
\ No newline at end of file
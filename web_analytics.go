@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+type AnalyticsPageData struct {
+	Title     string
+	User      string
+	Error     string
+	Filter    handlerpkg.RegistrationFilter
+	Exams     []handlerpkg.ExamType
+	Analytics handlerpkg.Analytics
+	Charts    []template.HTML
+}
+
+// topCenters is how many of the fullest centers are charted
+const topCenters = 10
+
+// handleAnalytics shows travel distance, first-preference, fill rate and fairness statistics with SVG charts
+func (s *Server) handleAnalytics(w http.ResponseWriter, r *http.Request) {
+	u, _ := currentUser(r)
+	q := r.URL.Query()
+	data := AnalyticsPageData{
+		Title:  "Analytics — ExamCenterHub",
+		User:   u.Username,
+		Filter: handlerpkg.RegistrationFilter{Exam: q.Get("exam"), From: q.Get("from"), To: q.Get("to")},
+	}
+	for _, ex := range s.h.GetExamTypes() {
+		if u.InScope(ex.Code, "") {
+			data.Exams = append(data.Exams, ex)
+		}
+	}
+	if err := data.Filter.Validate(); err != nil {
+		data.Error = err.Error()
+		data.Filter = handlerpkg.RegistrationFilter{}
+	}
+	a := s.h.Analytics(data.Filter, u.CanSeeRegistration)
+	data.Analytics = a
+
+	byExam := handlerpkg.BarChart{Title: "Mean travel distance by exam", Unit: " km"}
+	firstPref := handlerpkg.BarChart{Title: "Seated in their nearest center city", Unit: "%", Max: 100}
+	for _, g := range a.Exams {
+		byExam.Bars = append(byExam.Bars, handlerpkg.Bar{Label: g.Name, Value: g.Distance.Mean,
+			Note: fmt.Sprintf("p50 %.0f · p95 %.0f · max %.0f", g.Distance.P50, g.Distance.P95, g.Distance.Max)})
+		firstPref.Bars = append(firstPref.Bars, handlerpkg.Bar{Label: g.Name, Value: g.FirstPreferencePct,
+			Note: fmt.Sprintf("%d of %d", g.FirstPreference, g.Distance.Count)})
+	}
+	distances := handlerpkg.BarChart{Title: "Candidates by travel distance"}
+	for _, b := range a.DistanceBuckets {
+		distances.Bars = append(distances.Bars, handlerpkg.Bar{Label: b.Label, Value: float64(b.Count)})
+	}
+	states := handlerpkg.BarChart{Title: "Mean travel distance by home state", Unit: " km"}
+	for _, g := range a.States {
+		states.Bars = append(states.Bars, handlerpkg.Bar{Label: g.Name, Value: g.Distance.Mean,
+			Note: fmt.Sprintf("%d candidates · %.0f%% nearest", g.Distance.Count, g.FirstPreferencePct)})
+	}
+	fill := handlerpkg.BarChart{Title: "Centers by fill rate"}
+	for _, b := range a.FillBuckets {
+		fill.Bars = append(fill.Bars, handlerpkg.Bar{Label: b.Label, Value: float64(b.Count)})
+	}
+	fullest := handlerpkg.BarChart{Title: fmt.Sprintf("%d fullest centers", topCenters), Unit: "%", Max: 100}
+	for _, c := range a.Centers[:min(topCenters, len(a.Centers))] {
+		fullest.Bars = append(fullest.Bars, handlerpkg.Bar{Label: c.City, Value: c.FillPct, Note: fmt.Sprintf("%d/%d %s", c.Booked, c.Total, c.Center)})
+	}
+	for _, c := range []handlerpkg.BarChart{byExam, distances, firstPref, states, fill, fullest} {
+		// The SVG is generated from escaped text by handlerpkg.BarChart
+		data.Charts = append(data.Charts, template.HTML(c.SVG()))
+	}
+	_ = s.t.ExecuteTemplate(w, "analytics.html", data)
+} 
\ No newline at end of file
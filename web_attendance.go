@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+type AttendancePageData struct {
+	Title   string
+	Center  string
+	Report  []handlerpkg.CenterAttendance
+	Message string
+	Errors  []handlerpkg.RowError
+	Scoped  bool // the center filter is fixed to the user's center
+}
+
+// markedBy names the invigilator on an attendance mark, falling back to the signed-in user
+func markedBy(u handlerpkg.User, invigilator string) string {
+	if invigilator = strings.TrimSpace(invigilator); invigilator != "" {
+		return invigilator
+	}
+	return u.Username
+}
+
+// handleAttendance shows the per-center report and accepts CSV uploads from invigilators
+func (s *Server) handleAttendance(w http.ResponseWriter, r *http.Request) {
+	u, _ := currentUser(r)
+	data := AttendancePageData{Title: "Attendance — ExamCenterHub", Center: scopedCenter(r, r.URL.Query().Get("center")), Scoped: u.Role == handlerpkg.RoleSuperintendent}
+	if r.Method == http.MethodPost {
+		file, _, err := r.FormFile("csv")
+		if err != nil {
+			data.Errors = []handlerpkg.RowError{{Message: "choose a CSV file to upload"}}
+		} else {
+			defer file.Close()
+			marked, errs := s.h.ImportAttendanceCSV(file, markedBy(u, r.FormValue("marked_by")), u.CanSeeRegistration)
+			data.Message = fmt.Sprintf("%d candidates marked", marked)
+			data.Errors = errs
+		}
+	}
+	data.Report = s.h.AttendanceReport(data.Center)
+	_ = s.t.ExecuteTemplate(w, "attendance.html", data)
+}
+
+type attendanceMark struct {
+	RegistrationID string `json:"registration_id"`
+	Status         string `json:"status"`
+	MarkedBy       string `json:"marked_by"`
+}
+
+type attendanceError struct {
+	Index   int    `json:"index"`
+	Message string `json:"message"`
+}
+
+// handleAttendanceAPI accepts a JSON array of marks (POST) or returns the report (GET)
+func (s *Server) handleAttendanceAPI(w http.ResponseWriter, r *http.Request) {
+	u, _ := currentUser(r)
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.h.AttendanceReport(scopedCenter(r, r.URL.Query().Get("center"))))
+	case http.MethodPost:
+		var marks []attendanceMark
+		if err := json.NewDecoder(r.Body).Decode(&marks); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "expected a JSON array of marks: " + err.Error()})
+			return
+		}
+		errs := []attendanceError{}
+		for i, m := range marks {
+			status, err := handlerpkg.ParseAttendanceStatus(m.Status)
+			if err == nil {
+				if reg, lookupErr := s.h.GetRegistration(m.RegistrationID); lookupErr == nil && !u.CanSeeRegistration(reg) {
+					err = fmt.Errorf("registration '%s' is outside your scope", reg.ID)
+				}
+			}
+			if err == nil {
+				err = s.h.MarkAttendance(m.RegistrationID, status, markedBy(u, m.MarkedBy))
+			}
+			if err != nil {
+				errs = append(errs, attendanceError{Index: i, Message: err.Error()})
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"marked": len(marks) - len(errs), "errors": errs})
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+} 
\ No newline at end of file
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+const (
+	sessionCookie   = "examhub_session"
+	sessionLifetime = 12 * time.Hour
+)
+
+// session is a signed-in browser; sessions live in memory, so a restart signs everyone out
+type session struct {
+	username string // account holder, or
+	roll     string // candidate signed in with a one-time code
+	contact  string // and the phone or email the code went to
+	expires  time.Time
+}
+
+type contextKey int
+
+const userContextKey contextKey = 0
+
+type LoginPageData struct {
+	Title    string
+	Username string
+	Next     string
+	Error    string
+}
+
+type SignupPageData struct {
+	Title      string
+	RollNumber string
+	Contact    string
+	Username   string
+	CodeSent   bool
+	Next       string
+	Error      string
+}
+
+// currentUser returns the signed-in user attached by public or require
+func currentUser(r *http.Request) (handlerpkg.User, bool) {
+	u, ok := r.Context().Value(userContextKey).(handlerpkg.User)
+	return u, ok
+}
+
+// userName returns the signed-in user's name for audit entries
+func userName(r *http.Request) string {
+	u, _ := currentUser(r)
+	return u.Username
+}
+
+// sessionUser resolves the session cookie to an active user
+func (s *Server) sessionUser(r *http.Request) (handlerpkg.User, bool) {
+	c, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return handlerpkg.User{}, false
+	}
+	sess, ok := s.sessions[c.Value]
+	if !ok || time.Now().After(sess.expires) {
+		delete(s.sessions, c.Value)
+		return handlerpkg.User{}, false
+	}
+	if sess.roll != "" {
+		return handlerpkg.User{Username: sess.roll, Role: handlerpkg.RoleCandidate, RollNumber: sess.roll, Contact: sess.contact}, true
+	}
+	u, ok := s.h.GetUser(sess.username)
+	if !ok || u.Disabled {
+		return handlerpkg.User{}, false
+	}
+	return u, true
+}
+
+// public serves a route to everyone, attaching the signed-in user if there is one
+func (s *Server) public(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if u, ok := s.sessionUser(r); ok {
+			r = r.WithContext(context.WithValue(r.Context(), userContextKey, u))
+		}
+		next(w, r)
+	}
+}
+
+// require serves a route only to signed-in users holding one of the roles. Pages send
+// anonymous visitors to the login form; API routes answer 401.
+func (s *Server) require(next http.HandlerFunc, roles ...handlerpkg.Role) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u, ok := s.sessionUser(r)
+		if !ok {
+			if strings.HasPrefix(r.URL.Path, "/api/") {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "sign in required"})
+				return
+			}
+			http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+			return
+		}
+		allowed := false
+		for _, role := range roles {
+			allowed = allowed || u.Role == role
+		}
+		if !allowed {
+			http.Error(w, "forbidden: your account cannot use this page", http.StatusForbidden)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), userContextKey, u)))
+	}
+}
+
+// startSession signs a user in on this browser
+func (s *Server) startSession(w http.ResponseWriter, r *http.Request, sess session) error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	for token, sess := range s.sessions {
+		if time.Now().After(sess.expires) {
+			delete(s.sessions, token)
+		}
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	sess.expires = time.Now().Add(sessionLifetime)
+	s.sessions[token] = sess
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  sess.expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// safeNext keeps post-login redirects on this site
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	data := LoginPageData{Title: "Sign in — ExamCenterHub", Next: safeNext(r.FormValue("next"))}
+	if r.Method == http.MethodPost {
+		data.Username = r.FormValue("username")
+		u, err := s.h.Authenticate(data.Username, r.FormValue("password"))
+		if err == nil {
+			err = s.startSession(w, r, session{username: u.Username})
+		}
+		if err == nil {
+			http.Redirect(w, r, data.Next, http.StatusSeeOther)
+			return
+		}
+		data.Error = err.Error()
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+	_ = s.t.ExecuteTemplate(w, "login.html", data)
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	if c, err := r.Cookie(sessionCookie); err == nil {
+		delete(s.sessions, c.Value)
+	}
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: "", Path: "/", MaxAge: -1})
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// handleSignup creates a candidate account with a user name of the candidate's choosing. A one-time
+// code goes to the phone or email on their registration first; the account only sees registrations
+// that list that contact.
+func (s *Server) handleSignup(w http.ResponseWriter, r *http.Request) {
+	data := SignupPageData{Title: "Create account — ExamCenterHub", Next: safeNext(r.FormValue("next"))}
+	if r.Method == http.MethodPost {
+		data.RollNumber = strings.TrimSpace(r.FormValue("roll_number"))
+		data.Contact = strings.TrimSpace(r.FormValue("contact"))
+		var err error
+		switch r.FormValue("action") {
+		case "send":
+			if err = s.h.RequestSignupCode(data.RollNumber, data.Contact); err == nil {
+				data.CodeSent = true
+			}
+		case "verify":
+			data.CodeSent = true
+			data.Username = strings.TrimSpace(r.FormValue("username"))
+			password := r.FormValue("password")
+			if password != r.FormValue("confirm") {
+				err = errors.New("passwords do not match")
+			} else {
+				var u handlerpkg.User
+				if u, err = s.h.SignUpCandidate(data.Username, data.RollNumber, data.Contact, r.FormValue("code"), password); err == nil {
+					err = s.startSession(w, r, session{username: u.Username})
+				}
+			}
+			if err == nil {
+				http.Redirect(w, r, data.Next, http.StatusSeeOther)
+				return
+			}
+		default:
+			http.Error(w, "unknown action", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			data.Error = err.Error()
+		}
+	}
+	_ = s.t.ExecuteTemplate(w, "signup.html", data)
+}
+
+// scopedCenter pins superintendents to their own center; other roles keep the requested one
+func scopedCenter(r *http.Request, requested string) string {
+	if u, ok := currentUser(r); ok && u.Role == handlerpkg.RoleSuperintendent {
+		return u.Center
+	}
+	return requested
+} 
\ No newline at end of file
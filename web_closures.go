@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+type ClosurePageData struct {
+	Title       string
+	User        string
+	Message     string
+	Error       string
+	Centers     []handlerpkg.CenterStatus
+	Closures    []handlerpkg.CenterClosure
+	Center      string // the closure form
+	Date        string
+	Slot        string
+	Reason      string
+	Impact      *handlerpkg.ClosureImpact
+	Fingerprint string
+}
+
+// handleAdminClosure lists closed centers, previews closing a center, applies a previewed closure and reopens centers
+func (s *Server) handleAdminClosure(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	data := ClosurePageData{
+		Title:   "Center closures — ExamCenterHub",
+		User:    userName(r),
+		Message: q.Get("msg"),
+		Error:   q.Get("error"),
+		Center:  q.Get("center"),
+	}
+	if r.Method == http.MethodPost {
+		data.Center, data.Date, data.Slot, data.Reason = r.FormValue("center"), r.FormValue("date"), r.FormValue("slot"), r.FormValue("reason")
+		var err error
+		var msg string
+		switch r.FormValue("action") {
+		case "preview":
+			var impact handlerpkg.ClosureImpact
+			if impact, err = s.h.PreviewCenterClosure(data.Center, data.Date, data.Slot, data.Reason); err == nil {
+				data.Impact, data.Fingerprint = &impact, impact.Fingerprint()
+			}
+		case "close":
+			msg, err = s.closeCenter(r, &data)
+		case "reopen":
+			if err = s.h.ReopenCenter(data.User, data.Center, data.Date, data.Slot); err == nil {
+				msg = data.Center + " reopened; candidates moved away keep their new centers"
+			}
+		default:
+			http.Error(w, "unknown action", http.StatusBadRequest)
+			return
+		}
+		if err == nil && msg != "" {
+			http.Redirect(w, r, "/admin/closure?msg="+url.QueryEscape(msg), http.StatusSeeOther)
+			return
+		}
+		if err != nil {
+			data.Error = err.Error()
+		}
+	}
+	data.Centers = s.h.ListCenters("")
+	data.Closures = s.h.Closures()
+	_ = s.t.ExecuteTemplate(w, "admin_closure.html", data)
+}
+
+// closeCenter applies a previewed closure, unless the moves it would make are no longer the ones previewed
+func (s *Server) closeCenter(r *http.Request, data *ClosurePageData) (string, error) {
+	impact, err := s.h.PreviewCenterClosure(data.Center, data.Date, data.Slot, data.Reason)
+	if err != nil {
+		return "", err
+	}
+	if impact.Fingerprint() != r.FormValue("fingerprint") {
+		data.Impact, data.Fingerprint = &impact, impact.Fingerprint()
+		return "", fmt.Errorf("seats or registrations changed since the preview; check the moves below and close the center again")
+	}
+	if impact, err = s.h.CloseCenter(data.User, data.Center, data.Date, data.Slot, data.Reason); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s closed on %s: %s", impact.Closure.Center, impact.Closure.Sitting(), impact.Summary()), nil
+} 
\ No newline at end of file
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+// examJSON is the admin API form of an exam edition
+type examJSON struct {
+	Code                 string   `json:"code"`
+	Edition              int      `json:"edition"`
+	Name                 string   `json:"name"`
+	Description          string   `json:"description"`
+	Duration             string   `json:"duration"` // e.g. "3h20m"
+	StartDate            string   `json:"start_date"`
+	EndDate              string   `json:"end_date"`
+	TimeSlots            []string `json:"time_slots"`
+	RegistrationDeadline string   `json:"registration_deadline"`
+	MaxCenters           int      `json:"max_centers"`
+	Allocation           string   `json:"allocation"`
+	Zones                string   `json:"zones,omitempty"`
+	HomeCity             string   `json:"home_city"`
+	WomenRadius          float64  `json:"women_radius_km,omitempty"`
+	Closed               bool     `json:"closed"`
+}
+
+func toExamJSON(ex handlerpkg.ExamType) examJSON {
+	return examJSON{
+		Code:                 ex.Code,
+		Edition:              ex.Edition,
+		Name:                 ex.Name,
+		Description:          ex.Description,
+		Duration:             ex.Duration.String(),
+		StartDate:            ex.Schedule.StartDate,
+		EndDate:              ex.Schedule.EndDate,
+		TimeSlots:            ex.Schedule.TimeSlots,
+		RegistrationDeadline: ex.Schedule.RegistrationDeadline,
+		MaxCenters:           ex.MaxCenters,
+		Allocation:           ex.Allocation.String(),
+		Zones:                ex.Zones,
+		HomeCity:             ex.HomeCity.String(),
+		WomenRadius:          ex.WomenRadius,
+		Closed:               ex.Closed,
+	}
+}
+
+func (e examJSON) examType() (handlerpkg.ExamType, error) {
+	d, err := time.ParseDuration(e.Duration)
+	if err != nil {
+		return handlerpkg.ExamType{}, fmt.Errorf("duration '%s' is not like 3h or 2h40m", e.Duration)
+	}
+	return handlerpkg.ExamType{
+		Code:        e.Code,
+		Edition:     e.Edition,
+		Name:        e.Name,
+		Description: e.Description,
+		Duration:    d,
+		Schedule: handlerpkg.ExamSchedule{
+			StartDate:            e.StartDate,
+			EndDate:              e.EndDate,
+			TimeSlots:            e.TimeSlots,
+			RegistrationDeadline: e.RegistrationDeadline,
+		},
+		MaxCenters:  e.MaxCenters,
+		Allocation:  handlerpkg.AllocationRule(e.Allocation),
+		Zones:       e.Zones,
+		HomeCity:    handlerpkg.HomeCityPolicy(e.HomeCity),
+		WomenRadius: e.WomenRadius,
+		Closed:      e.Closed,
+	}, nil
+}
+
+// handleExamsAPI lists exam editions (GET), creates or replaces one (POST) or removes an unused one
+// (DELETE ?exam=JEE+2025). Exam admins may only read their own exams.
+func (s *Server) handleExamsAPI(w http.ResponseWriter, r *http.Request) {
+	u, _ := currentUser(r)
+	if r.Method != http.MethodGet && u.Role != handlerpkg.RoleNationalAdmin {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "only national admins can change exam types"})
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		list := []examJSON{}
+		for _, ex := range s.h.ExamEditions(r.URL.Query().Get("code")) {
+			if u.InScope(ex.Code, "") {
+				list = append(list, toExamJSON(ex))
+			}
+		}
+		writeJSON(w, http.StatusOK, list)
+	case http.MethodPost:
+		var in examJSON
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "expected a JSON exam edition: " + err.Error()})
+			return
+		}
+		ex, err := in.examType()
+		if err == nil {
+			ex, err = s.h.SaveExamEdition(u.Username, ex)
+		}
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, toExamJSON(ex))
+	case http.MethodDelete:
+		code, edition, err := handlerpkg.ParseExamRef(r.URL.Query().Get("exam"))
+		if err == nil && edition == 0 {
+			err = fmt.Errorf("give the exam code and edition year, e.g. exam=JEE+2025")
+		}
+		if err == nil {
+			err = s.h.RemoveExamEdition(u.Username, code, edition)
+		}
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"removed": fmt.Sprintf("%s %d", code, edition)})
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+} 
\ No newline at end of file
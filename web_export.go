@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+type ExportPageData struct {
+	Title  string
+	User   string
+	Error  string
+	Filter handlerpkg.RegistrationFilter
+	Exams  []handlerpkg.ExamType
+	Cities []string
+}
+
+// handleExport downloads registrations or utilization when a format is given and shows the export form otherwise
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	u, _ := currentUser(r)
+	q := r.URL.Query()
+	data := ExportPageData{
+		Title:  "Export — ExamCenterHub",
+		User:   u.Username,
+		Filter: registrationFilter(q),
+		Cities: s.h.GetAvailableCities(),
+	}
+	for _, ex := range s.h.GetExamTypes() {
+		if u.InScope(ex.Code, "") {
+			data.Exams = append(data.Exams, ex)
+		}
+	}
+	if q.Get("format") != "" {
+		err := s.writeExport(w, u, q.Get("what"), q.Get("format"), data.Filter)
+		if err == nil {
+			return
+		}
+		data.Error = err.Error()
+	}
+	_ = s.t.ExecuteTemplate(w, "export.html", data)
+}
+
+// writeExport sends the file; nothing is written when it returns an error
+func (s *Server) writeExport(w http.ResponseWriter, u handlerpkg.User, what, formatName string, f handlerpkg.RegistrationFilter) error {
+	format, err := handlerpkg.ParseExportFormat(formatName)
+	if err != nil {
+		return err
+	}
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	regs := s.h.FilterRegistrations(f, u.CanSeeRegistration)
+	var t handlerpkg.Table
+	switch what {
+	case "registrations":
+		t = handlerpkg.RegistrationsTable(regs)
+	case "utilization":
+		t = s.h.UtilizationTable(f, regs)
+	default:
+		return fmt.Errorf("unknown export '%s'", what)
+	}
+	var buf bytes.Buffer
+	if err := handlerpkg.WriteTable(&buf, t, format); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.%s", what, time.Now().Format("20060102"), format)
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	_, _ = w.Write(buf.Bytes())
+	return nil
+}
+
+// registrationFilter reads the filter fields shared by the registration listing and exports
+func registrationFilter(q url.Values) handlerpkg.RegistrationFilter {
+	edition, _ := strconv.Atoi(q.Get("edition"))
+	return handlerpkg.RegistrationFilter{
+		Exam:     q.Get("exam"),
+		Edition:  edition,
+		City:     q.Get("city"),
+		Center:   q.Get("center"),
+		HomeCity: q.Get("home"),
+		From:     q.Get("from"),
+		To:       q.Get("to"),
+		Name:     q.Get("name"),
+	}
+} 
\ No newline at end of file
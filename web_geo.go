@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+type GeoPageData struct {
+	Title       string
+	User        string
+	Message     string
+	Error       string
+	Cities      int
+	Centers     int
+	Diff        *handlerpkg.GeoDiff
+	Source      string
+	Format      handlerpkg.GeoFormat
+	Content     string // the reviewed file, posted back to apply it
+	Fingerprint string
+}
+
+// maxGeoUpload caps the size of an uploaded GeoJSON or KML file
+const maxGeoUpload = 10 << 20
+
+// handleAdminGeo downloads cities and centers as GeoJSON or KML, and reviews and applies an edited file
+func (s *Server) handleAdminGeo(w http.ResponseWriter, r *http.Request) {
+	u, _ := currentUser(r)
+	q := r.URL.Query()
+	if name := q.Get("download"); name != "" {
+		format, err := handlerpkg.ParseGeoFormat(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		write := handlerpkg.WriteGeoJSON
+		if format == handlerpkg.FormatKML {
+			write = handlerpkg.WriteKML
+		}
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "examcenterhub-centers."+string(format)))
+		_ = write(w, s.h.GeoFeatures())
+		return
+	}
+	data := GeoPageData{Title: "Map data — ExamCenterHub", User: u.Username, Message: q.Get("msg"), Error: q.Get("error")}
+	if r.Method == http.MethodPost {
+		var err error
+		if r.FormValue("action") == "apply" {
+			var d handlerpkg.GeoDiff
+			if d, err = s.geoApply(r, u, &data); err == nil {
+				http.Redirect(w, r, "/admin/geo?msg="+url.QueryEscape("Applied "+data.Source+": "+d.Summary()), http.StatusSeeOther)
+				return
+			}
+		} else {
+			err = s.geoReview(r, &data)
+		}
+		if err != nil {
+			data.Error = err.Error()
+		}
+	}
+	for _, f := range s.h.GeoFeatures() {
+		if f.Kind == handlerpkg.FeatureCity {
+			data.Cities++
+		} else {
+			data.Centers++
+		}
+	}
+	_ = s.t.ExecuteTemplate(w, "admin_geo.html", data)
+}
+
+// geoReview diffs an uploaded file against the current data
+func (s *Server) geoReview(r *http.Request, data *GeoPageData) error {
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return fmt.Errorf("choose a GeoJSON or KML file to upload")
+	}
+	defer file.Close()
+	format, err := handlerpkg.ParseGeoFormat(header.Filename)
+	if err != nil {
+		return err
+	}
+	content, err := io.ReadAll(io.LimitReader(file, maxGeoUpload+1))
+	if err != nil {
+		return err
+	}
+	if len(content) > maxGeoUpload {
+		return fmt.Errorf("the file is larger than %d MB", maxGeoUpload>>20)
+	}
+	data.Source, data.Format, data.Content = header.Filename, format, string(content)
+	return s.geoDiff(data)
+}
+
+// geoApply applies a reviewed file, unless the changes it makes are no longer the ones reviewed
+func (s *Server) geoApply(r *http.Request, u handlerpkg.User, data *GeoPageData) (handlerpkg.GeoDiff, error) {
+	format, err := handlerpkg.ParseGeoFormat(r.FormValue("format"))
+	if err != nil {
+		return handlerpkg.GeoDiff{}, err
+	}
+	data.Source, data.Format, data.Content = r.FormValue("source"), format, r.FormValue("content")
+	reviewed := r.FormValue("fingerprint")
+	if err := s.geoDiff(data); err != nil {
+		return handlerpkg.GeoDiff{}, err
+	}
+	if data.Fingerprint != reviewed {
+		return handlerpkg.GeoDiff{}, fmt.Errorf("the data changed since the file was reviewed; check the changes below and apply again")
+	}
+	features, _ := handlerpkg.ReadGeo(strings.NewReader(data.Content), format)
+	d, err := s.h.ApplyGeo(u.Username, data.Source, features)
+	if err != nil {
+		data.Diff = &d
+	}
+	return d, err
+}
+
+// geoDiff parses data.Content and fills in the review
+func (s *Server) geoDiff(data *GeoPageData) error {
+	features, err := handlerpkg.ReadGeo(bytes.NewReader([]byte(data.Content)), data.Format)
+	if err != nil {
+		return err
+	}
+	d := s.h.DiffGeo(features)
+	data.Diff, data.Fingerprint = &d, d.Fingerprint()
+	return nil
+} 
\ No newline at end of file
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+type ImportPageData struct {
+	Title   string
+	User    string
+	Mode    handlerpkg.ImportMode
+	Message string
+	Error   string
+	Result  *handlerpkg.ImportResult
+	Queue   []handlerpkg.QueuedCandidate
+	Columns []string
+}
+
+// handleImport uploads candidate spreadsheets and runs the batch allocator over queued rows
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	u, _ := currentUser(r)
+	allowed := func(ex handlerpkg.ExamType) bool { return u.InScope(ex.Code, "") }
+	data := ImportPageData{Title: "Import candidates — ExamCenterHub", User: u.Username, Mode: handlerpkg.ImportCheck, Columns: handlerpkg.ImportColumns}
+	if r.Method == http.MethodPost {
+		if r.FormValue("action") == "allocate" {
+			res, err := s.h.RunBatchAllocation(u.Username, allowed)
+			if err != nil {
+				data.Error = err.Error()
+			} else {
+				data.Message = fmt.Sprintf("Batch allocation: %d registered, %d waitlisted", res.Registered, res.Waitlisted)
+			}
+		} else if err := s.importUpload(r, &data, allowed); err != nil {
+			data.Error = err.Error()
+		}
+	}
+	for _, q := range s.h.QueuedCandidates() {
+		if allowed(q.ExamType) {
+			data.Queue = append(data.Queue, q)
+		}
+	}
+	_ = s.t.ExecuteTemplate(w, "import.html", data)
+}
+
+func (s *Server) importUpload(r *http.Request, data *ImportPageData, allowed func(handlerpkg.ExamType) bool) error {
+	mode, err := handlerpkg.ParseImportMode(r.FormValue("mode"))
+	if err != nil {
+		return err
+	}
+	data.Mode = mode
+	file, header, err := r.FormFile("csv")
+	if err != nil {
+		return fmt.Errorf("choose a CSV file to upload")
+	}
+	defer file.Close()
+	res, err := s.h.ImportCandidatesCSV(data.User, file, header.Filename, mode, allowed)
+	data.Result = &res
+	return err
+} 
\ No newline at end of file
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+type DemandMapPageData struct {
+	Title  string
+	User   string
+	Error  string
+	Filter handlerpkg.RegistrationFilter
+	Exams  []handlerpkg.ExamType
+	Cities []DemandRow
+	Map    template.HTML
+}
+
+// DemandRow is a city on the heatmap with its legend colour
+type DemandRow struct {
+	handlerpkg.CityDemand
+	Colour string
+	Band   string
+}
+
+// handleDemandMap shows registration demand against seats per city as an SVG heatmap of India
+func (s *Server) handleDemandMap(w http.ResponseWriter, r *http.Request) {
+	u, _ := currentUser(r)
+	q := r.URL.Query()
+	data := DemandMapPageData{
+		Title:  "Demand map — ExamCenterHub",
+		User:   u.Username,
+		Filter: handlerpkg.RegistrationFilter{Exam: q.Get("exam"), From: q.Get("from"), To: q.Get("to")},
+	}
+	for _, ex := range s.h.GetExamTypes() {
+		if u.InScope(ex.Code, "") {
+			data.Exams = append(data.Exams, ex)
+		}
+	}
+	if err := data.Filter.Validate(); err != nil {
+		data.Error = err.Error()
+		data.Filter = handlerpkg.RegistrationFilter{}
+	}
+	demand := s.h.DemandByCity(data.Filter, u.CanSeeRegistration)
+	for _, d := range demand {
+		row := DemandRow{CityDemand: d}
+		if d.Demand > 0 {
+			row.Colour, row.Band = handlerpkg.DemandBand(d)
+		}
+		data.Cities = append(data.Cities, row)
+	}
+	title := "Candidates by home city vs seats"
+	if data.Filter.Exam != "" {
+		title += " · " + data.Filter.Exam
+	}
+	// The SVG is generated from escaped text by handlerpkg.IndiaMap
+	data.Map = template.HTML(s.h.DemandMap(title, demand).SVG())
+	_ = s.t.ExecuteTemplate(w, "admin_map.html", data)
+} 
\ No newline at end of file
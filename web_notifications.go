@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+type NotificationsPageData struct {
+	Title      string
+	User       string
+	Message    string
+	Deliveries []handlerpkg.Delivery
+	Waitlist   []handlerpkg.WaitlistEntry
+}
+
+// handleAdminNotifications shows the delivery log and the waitlist; POST queues due reminders and
+// wakes the sender, which sends them with any due retries once this request has finished
+func (s *Server) handleAdminNotifications(w http.ResponseWriter, r *http.Request) {
+	data := NotificationsPageData{Title: "Notifications — ExamCenterHub", User: userName(r)}
+	if r.Method == http.MethodPost {
+		data.Message = fmt.Sprintf("Queued %d reminder(s). Due messages are being sent; refresh to see the outcome.", s.h.QueueReminders(time.Now()))
+	}
+	data.Deliveries = s.h.Deliveries(100)
+	data.Waitlist = s.h.Waitlist()
+	_ = s.t.ExecuteTemplate(w, "admin_notifications.html", data)
+}
+
+// wakeSender asks runNotifications to run now rather than at its next tick
+func (s *Server) wakeSender() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// runNotifications queues due reminders and sends queued messages and webhooks, retries included,
+// for as long as the server runs: on a timer and whenever a request may have queued something.
+// Nothing is sent while holding s.mu, so a slow mail server or receiver does not hold up requests.
+func (s *Server) runNotifications(every time.Duration) {
+	tick := time.NewTicker(every)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+		case <-s.wake:
+		}
+		now := time.Now()
+		s.mu.Lock()
+		if err := s.h.Reload(); err != nil {
+			log.Printf("reloading state: %v", err)
+		}
+		reminders := s.h.QueueReminders(now)
+		mail, hooks := s.h.DueDeliveries(now), s.h.DueWebhooks(now)
+		s.mu.Unlock()
+
+		mail, hooks = handlerpkg.SendDeliveries(mail), handlerpkg.SendWebhooks(hooks, now)
+
+		s.mu.Lock()
+		if reminders+s.h.RecordDeliveries(mail, now)+s.h.RecordWebhooks(hooks, now) > 0 {
+			if err := s.h.Save(); err != nil {
+				log.Printf("saving state: %v", err)
+			}
+		}
+		s.mu.Unlock()
+	}
+} 
\ No newline at end of file
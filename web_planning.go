@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+type PlanningPageData struct {
+	Title      string
+	User       string
+	Error      string
+	Exams      []handlerpkg.ExamType
+	Exam       string // the form
+	Demand     string
+	Synthetic  string
+	AddSeats   string
+	Keep       bool
+	MaxKm      string
+	Simulation *handlerpkg.Simulation
+	Runs       []handlerpkg.SimulationResult // current, then planned
+	Overloaded []handlerpkg.SimulatedCity    // of the last run
+	Short      []handlerpkg.SimulatedHome    // home cities of the last run with unallocated candidates
+	Chart      template.HTML
+}
+
+// handlePlanning runs the capacity planner for a forecast or synthetic demand, with and without planned seats
+func (s *Server) handlePlanning(w http.ResponseWriter, r *http.Request) {
+	u, _ := currentUser(r)
+	data := PlanningPageData{
+		Title:     "Capacity planning — ExamCenterHub",
+		User:      u.Username,
+		Synthetic: "10000",
+		MaxKm:     strconv.Itoa(handlerpkg.DefaultImportDistance),
+	}
+	for _, ex := range s.h.GetExamTypes() {
+		if u.InScope(ex.Code, "") {
+			data.Exams = append(data.Exams, ex)
+		}
+	}
+	if r.Method == http.MethodPost {
+		data.Exam, data.Demand, data.Synthetic = r.FormValue("exam"), r.FormValue("demand"), r.FormValue("synthetic")
+		data.AddSeats, data.Keep, data.MaxKm = r.FormValue("add"), r.FormValue("keep") != "", r.FormValue("max_distance")
+		if err := s.plan(u, &data); err != nil {
+			data.Error = err.Error()
+		}
+	}
+	_ = s.t.ExecuteTemplate(w, "planning.html", data)
+}
+
+// plan reads the form and runs the simulation. It is called holding s.mu, which it lets go of during the run.
+func (s *Server) plan(u handlerpkg.User, data *PlanningPageData) error {
+	if !u.InScope(data.Exam, "") {
+		return fmt.Errorf("exam type '%s' not found", data.Exam)
+	}
+	sc := handlerpkg.SimulationScenario{Exam: data.Exam, KeepBookings: data.Keep, Seed: 1}
+	var err error
+	if sc.MaxDistance, err = strconv.ParseFloat(strings.TrimSpace(data.MaxKm), 64); err != nil {
+		return errBadNumber("max distance", data.MaxKm)
+	}
+	if strings.TrimSpace(data.Demand) != "" {
+		if sc.Demand, err = s.h.ParseDemandCSV(strings.NewReader(data.Demand)); err != nil {
+			return err
+		}
+	} else {
+		n, err := strconv.Atoi(strings.TrimSpace(data.Synthetic))
+		if err != nil {
+			return errBadNumber("candidates", data.Synthetic)
+		}
+		sc.Demand = s.h.SyntheticDemand(n)
+	}
+	if sc.AddSeats, err = s.h.ParseSeatChanges(data.AddSeats); err != nil {
+		return err
+	}
+	simulator, err := s.h.NewSimulator(sc)
+	if err != nil {
+		return err
+	}
+	// The simulator works on its own copy of the data, so other requests go ahead while it runs
+	s.mu.Unlock()
+	sim := simulator.Run()
+	s.mu.Lock()
+	data.Simulation = &sim
+	data.Runs = []handlerpkg.SimulationResult{sim.Baseline}
+	if sim.Changed != nil {
+		data.Runs = append(data.Runs, *sim.Changed)
+	}
+	last := data.Runs[len(data.Runs)-1]
+	data.Overloaded = last.Overloaded()
+	for _, home := range last.Homes {
+		if home.Unallocated > 0 {
+			data.Short = append(data.Short, home)
+		}
+	}
+	short := handlerpkg.BarChart{Title: "Seats short in overloaded cities"}
+	for _, c := range data.Overloaded[:min(topCenters, len(data.Overloaded))] {
+		short.Bars = append(short.Bars, handlerpkg.Bar{Label: c.City, Value: float64(c.Overflow), Note: fmt.Sprintf("%d wanted, %d seats", c.Wanted, c.Seats)})
+	}
+	if len(short.Bars) > 0 {
+		// The SVG is generated from escaped text by handlerpkg.BarChart
+		data.Chart = template.HTML(short.SVG())
+	}
+	return nil
+} 
\ No newline at end of file
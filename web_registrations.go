@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+type RegistrationsPageData struct {
+	Title  string
+	User   handlerpkg.User
+	Error  string
+	Query  handlerpkg.RegistrationQuery
+	Result handlerpkg.RegistrationPage
+	Exams  []handlerpkg.ExamType
+	Cities []string
+	params url.Values
+}
+
+// PageURL links to another page of the same query
+func (d RegistrationsPageData) PageURL(page int) string {
+	v := cloneValues(d.params)
+	v.Set("page", strconv.Itoa(page))
+	return "/admin/registrations?" + v.Encode()
+}
+
+// SortURL links to the first page sorted by key; choosing the current key again flips the direction
+func (d RegistrationsPageData) SortURL(key string) string {
+	v := cloneValues(d.params)
+	v.Del("page")
+	v.Set("sort", key)
+	v.Del("desc")
+	if d.Query.Sort == key && !d.Query.Desc {
+		v.Set("desc", "1")
+	}
+	return "/admin/registrations?" + v.Encode()
+}
+
+// ExportURL downloads everything matching the filter
+func (d RegistrationsPageData) ExportURL(format string) string {
+	v := cloneValues(d.params)
+	for _, k := range []string{"page", "n", "sort", "desc"} {
+		v.Del(k)
+	}
+	v.Set("what", "registrations")
+	v.Set("format", format)
+	return "/admin/export?" + v.Encode()
+}
+
+func cloneValues(v url.Values) url.Values {
+	c := url.Values{}
+	for k, vs := range v {
+		if len(vs) > 0 && vs[0] != "" {
+			c[k] = append([]string(nil), vs...)
+		}
+	}
+	return c
+}
+
+// handleRegistrations lists registrations in the user's scope with search, sorting and pagination
+func (s *Server) handleRegistrations(w http.ResponseWriter, r *http.Request) {
+	u, _ := currentUser(r)
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	size, _ := strconv.Atoi(q.Get("n"))
+	data := RegistrationsPageData{
+		Title: "Registrations — ExamCenterHub",
+		User:  u,
+		Query: handlerpkg.RegistrationQuery{
+			Filter:   registrationFilter(q),
+			Sort:     q.Get("sort"),
+			Desc:     q.Get("desc") != "",
+			Page:     page,
+			PageSize: size,
+		},
+		Cities: s.h.GetAvailableCities(),
+		params: q,
+	}
+	if data.Query.Sort == "" {
+		data.Query.Sort = handlerpkg.SortRegistered
+	}
+	for _, ex := range s.h.GetExamTypes() {
+		if u.Role != handlerpkg.RoleExamAdmin || u.InScope(ex.Code, "") {
+			data.Exams = append(data.Exams, ex)
+		}
+	}
+	if u.Role == handlerpkg.RoleSuperintendent {
+		data.Query.Filter.Center = u.Center
+	}
+	var err error
+	if data.Result, err = s.h.QueryRegistrations(data.Query, u.CanSeeRegistration); err != nil {
+		data.Error = err.Error()
+	}
+	_ = s.t.ExecuteTemplate(w, "admin_registrations.html", data)
+}
+
+// registrationJSON is the API form of a registration, with why it was given its center
+type registrationJSON struct {
+	ID             string           `json:"id"`
+	RollNumber     string           `json:"roll_number"`
+	CandidateID    string           `json:"candidate_id"`
+	StudentName    string           `json:"student_name"`
+	Exam           string           `json:"exam"`
+	HomeCity       string           `json:"home_city"`
+	AssignedCity   string           `json:"assigned_city"`
+	AssignedCenter string           `json:"assigned_center"`
+	ExamDate       string           `json:"exam_date"`
+	TimeSlot       string           `json:"time_slot"`
+	DistanceKm     float64          `json:"distance_km"`
+	Categories     string           `json:"categories"`
+	Explanation    *explanationJSON `json:"explanation"` // null for registrations from before explanations
+}
+
+type explanationJSON struct {
+	Summary         string        `json:"summary"`
+	Policy          string        `json:"policy"`
+	Rank            int           `json:"rank"`
+	Offered         int           `json:"offered"`
+	DistanceKm      float64       `json:"distance_km"`
+	MaxDistanceKm   float64       `json:"max_distance_km"`
+	Skipped         []skippedJSON `json:"skipped"`
+	Full            int           `json:"excluded_full"`
+	Ineligible      int           `json:"excluded_ineligible"`
+	OverMaxDistance int           `json:"excluded_over_max_distance"`
+	ExplainedAt     time.Time     `json:"explained_at"`
+}
+
+type skippedJSON struct {
+	Center     string  `json:"center"`
+	City       string  `json:"city"`
+	DistanceKm float64 `json:"distance_km"`
+	Reason     string  `json:"reason"`
+	Rule       string  `json:"rule"`
+	Detail     string  `json:"detail"`
+}
+
+func toRegistrationJSON(reg handlerpkg.ExamRegistration) registrationJSON {
+	out := registrationJSON{
+		ID:             reg.ID,
+		RollNumber:     reg.RollNumber,
+		CandidateID:    reg.CandidateID,
+		StudentName:    reg.StudentName,
+		Exam:           reg.ExamType.Label(),
+		HomeCity:       reg.StudentCity,
+		AssignedCity:   reg.AssignedCity,
+		AssignedCenter: reg.AssignedCenter,
+		ExamDate:       reg.ExamDate,
+		TimeSlot:       reg.TimeSlot,
+		DistanceKm:     reg.Distance,
+		Categories:     reg.Preferences.CategoryList(),
+	}
+	if e := reg.Explanation; e != nil {
+		out.Explanation = &explanationJSON{Summary: e.Summary(), Policy: e.Policy, Rank: e.Rank, Offered: e.Offered, DistanceKm: e.Distance,
+			MaxDistanceKm: e.MaxDistance, Skipped: []skippedJSON{}, Full: e.Full, Ineligible: e.Ineligible, OverMaxDistance: e.OverMaxDistance,
+			ExplainedAt: e.ExplainedAt}
+		for _, s := range e.Skipped {
+			out.Explanation.Skipped = append(out.Explanation.Skipped, skippedJSON{Center: s.Center, City: s.City, DistanceKm: s.Distance,
+				Reason: string(s.Reason), Rule: s.Rule, Detail: s.Detail})
+		}
+	}
+	return out
+}
+
+// handleRegistrationAPI returns one registration and the reasons for its center, for answering grievances
+func (s *Server) handleRegistrationAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := r.URL.Query().Get("id")
+	reg, err := s.h.GetRegistration(id)
+	if u, _ := currentUser(r); err != nil || !u.CanSeeRegistration(reg) {
+		// Do not reveal whether registrations outside the user's scope exist
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("registration '%s' not found", id)})
+		return
+	}
+	writeJSON(w, http.StatusOK, toRegistrationJSON(reg))
+} 
\ No newline at end of file
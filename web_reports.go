@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+type SheetsPageData struct {
+	Title  string
+	Center string
+	Date   string
+	Slot   string
+	Sheets []handlerpkg.AttendanceSheet
+}
+
+type ManifestPageData struct {
+	Title        string
+	Lines        []handlerpkg.ManifestLine
+	TotalPapers  int
+	TotalPackets int
+	PacketSize   int
+	SparePercent float64
+}
+
+// handleSheets renders per-slot attendance sheets for printing, or as CSV with ?format=csv
+func (s *Server) handleSheets(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	data := SheetsPageData{Title: "Attendance Sheets — ExamCenterHub", Center: scopedCenter(r, q.Get("center")), Date: q.Get("date"), Slot: q.Get("slot")}
+	data.Sheets = s.h.AttendanceSheets(data.Center, data.Date, data.Slot)
+	if q.Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename=attendance-sheets.csv")
+		_ = handlerpkg.WriteAttendanceSheetsCSV(w, data.Sheets)
+		return
+	}
+	_ = s.t.ExecuteTemplate(w, "sheets.html", data)
+}
+
+// handleManifest renders the question paper dispatch manifest for the user's exams or center, or CSV with ?format=csv
+func (s *Server) handleManifest(w http.ResponseWriter, r *http.Request) {
+	u, _ := currentUser(r)
+	var lines []handlerpkg.ManifestLine
+	for _, l := range s.h.DispatchManifest() {
+		if u.InScope(l.Exam, l.Center) {
+			lines = append(lines, l)
+		}
+	}
+	if r.URL.Query().Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename=dispatch-manifest.csv")
+		_ = handlerpkg.WriteManifestCSV(w, lines)
+		return
+	}
+	data := ManifestPageData{
+		Title:        "Dispatch Manifest — ExamCenterHub",
+		Lines:        lines,
+		PacketSize:   handlerpkg.PaperPacketSize,
+		SparePercent: handlerpkg.PaperSpareRate * 100,
+	}
+	for _, l := range lines {
+		data.TotalPapers += l.Papers
+		data.TotalPackets += l.Packets
+	}
+	_ = s.t.ExecuteTemplate(w, "manifest.html", data)
+} 
\ No newline at end of file
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+type SeatCell struct {
+	Label string
+	Roll  string
+	Exam  string
+}
+
+type RoomView struct {
+	Name     string
+	Capacity int
+	Grid     [][]SeatCell
+	DoorList []handlerpkg.SeatAssignment
+}
+
+type SeatingPlanView struct {
+	Date     string
+	Slot     string
+	Rooms    []RoomView
+	Unseated int
+}
+
+type SeatingPageData struct {
+	Title      string
+	Center     string
+	City       string
+	Rooms      string
+	Interleave string
+	Error      string
+	Plans      []SeatingPlanView
+}
+
+// handleSeating shows printable seating charts and door lists for a center; POST re-allocates seats
+func (s *Server) handleSeating(w http.ResponseWriter, r *http.Request) {
+	data := SeatingPageData{Title: "Seating — ExamCenterHub", Center: scopedCenter(r, r.FormValue("center")), Interleave: r.FormValue("interleave")}
+	var plans []handlerpkg.SeatingPlan
+	var err error
+	switch {
+	case data.Center == "":
+	case r.Method == http.MethodPost:
+		if spec := strings.TrimSpace(r.FormValue("rooms")); spec != "" {
+			var rooms []handlerpkg.Room
+			if rooms, err = handlerpkg.ParseRoomSpec(spec); err == nil {
+				err = s.h.SetCenterRooms(userName(r), data.Center, rooms)
+			}
+		}
+		if err == nil {
+			plans, err = s.h.AllocateSeats(userName(r), data.Center, handlerpkg.SeatingOptions{Interleave: data.Interleave})
+		}
+	default:
+		plans, err = s.h.SeatingPlans(data.Center)
+	}
+	if err != nil {
+		data.Error = err.Error()
+	}
+	if data.Center != "" && err == nil {
+		rooms, _ := s.h.CenterRooms(data.Center)
+		data.Rooms = handlerpkg.FormatRoomSpec(rooms)
+	}
+	for _, plan := range plans {
+		data.City = plan.City
+		view := SeatingPlanView{Date: plan.Date, Slot: plan.Slot, Unseated: len(plan.Unseated)}
+		for _, rp := range plan.Rooms {
+			if len(rp.Seats) == 0 {
+				continue
+			}
+			rv := RoomView{Name: rp.Room.Name, Capacity: rp.Room.Capacity(), DoorList: rp.DoorList()}
+			for row := 0; row < rp.Room.Rows; row++ {
+				cells := make([]SeatCell, rp.Room.SeatsPerRow)
+				for seat := range cells {
+					cells[seat].Label = handlerpkg.SeatLabel(row, seat)
+					if a, ok := rp.At(row, seat); ok {
+						cells[seat].Roll = a.Registration.RollNumber
+						cells[seat].Exam = a.Registration.ExamType.Code
+					}
+				}
+				rv.Grid = append(rv.Grid, cells)
+			}
+			view.Rooms = append(view.Rooms, rv)
+		}
+		data.Plans = append(data.Plans, view)
+	}
+	_ = s.t.ExecuteTemplate(w, "seating.html", data)
+} 
\ No newline at end of file
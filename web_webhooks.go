@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	handlerpkg "exam-center-assignment/internal/handler"
+)
+
+type WebhooksPageData struct {
+	Title      string
+	User       string
+	Message    string
+	Error      string
+	Secret     string // shown once after a subscription is added or its secret rotated
+	SecretFor  string
+	Status     string
+	Webhooks   []handlerpkg.WebhookSubscription
+	Deliveries []handlerpkg.WebhookDelivery
+	Events     []handlerpkg.EventKind
+	Exams      []handlerpkg.ExamType
+}
+
+// handleAdminWebhooks manages exam body webhook subscriptions and replays failed deliveries
+func (s *Server) handleAdminWebhooks(w http.ResponseWriter, r *http.Request) {
+	data := WebhooksPageData{Title: "Webhooks — ExamCenterHub", User: userName(r)}
+	if r.Method == http.MethodPost {
+		actor := userName(r)
+		id := r.FormValue("id")
+		var err error
+		msg := "Webhook " + id + " saved"
+		switch r.FormValue("action") {
+		case "add":
+			var events []handlerpkg.EventKind
+			if events, err = handlerpkg.ParseWebhookEvents(strings.Join(r.Form["events"], ",")); err == nil {
+				var sub handlerpkg.WebhookSubscription
+				if sub, err = s.h.AddWebhook(actor, r.FormValue("exam_code"), r.FormValue("url"), events); err == nil {
+					data.Secret, data.SecretFor = sub.Secret, sub.ID
+				}
+			}
+		case "rotate":
+			if data.Secret, err = s.h.RotateWebhookSecret(actor, id); err == nil {
+				data.SecretFor = id
+			}
+		case "disable":
+			err = s.h.SetWebhookDisabled(actor, id, true)
+		case "enable":
+			err = s.h.SetWebhookDisabled(actor, id, false)
+		case "remove":
+			err = s.h.RemoveWebhook(actor, id)
+			msg = "Webhook " + id + " removed"
+		case "replay":
+			if _, err = s.h.ReplayWebhook(actor, id); err == nil {
+				msg = fmt.Sprintf("Delivery %s queued to be sent again; refresh to see the outcome", id)
+			}
+		case "replay_dead":
+			var queued int
+			if queued, err = s.h.ReplayDeadWebhooks(actor, id); err == nil {
+				msg = fmt.Sprintf("%d dead letter(s) queued to be sent again; refresh to see the outcome", queued)
+			}
+		default:
+			http.Error(w, "unknown action", http.StatusBadRequest)
+			return
+		}
+		if err == nil && data.Secret != "" {
+			// Render directly so the secret never ends up in a URL or the browser history
+			data.Message = "Copy the signing secret for " + data.SecretFor + " now; it is not shown again."
+			s.renderWebhooks(w, data)
+			return
+		}
+		v := url.Values{}
+		if err != nil {
+			v.Set("error", err.Error())
+		} else {
+			v.Set("msg", msg)
+		}
+		http.Redirect(w, r, "/admin/webhooks?"+v.Encode(), http.StatusSeeOther)
+		return
+	}
+	q := r.URL.Query()
+	data.Message, data.Error, data.Status = q.Get("msg"), q.Get("error"), q.Get("status")
+	s.renderWebhooks(w, data)
+}
+
+func (s *Server) renderWebhooks(w http.ResponseWriter, data WebhooksPageData) {
+	data.Webhooks = s.h.Webhooks()
+	data.Deliveries = s.h.WebhookDeliveries(data.Status, 100)
+	data.Events = handlerpkg.WebhookEvents
+	data.Exams = s.h.GetExamTypes()
+	_ = s.t.ExecuteTemplate(w, "admin_webhooks.html", data)
+} 
\ No newline at end of file
@@ -0,0 +1,451 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook delivery statuses
+const (
+	WebhookPending   = "pending" // waiting for its next attempt
+	WebhookDelivered = "delivered"
+	WebhookDead      = "dead" // gave up after MaxWebhookAttempts; kept on the dead-letter list for replay
+)
+
+// MaxWebhookAttempts is how often a payload is posted before it moves to the dead-letter list
+const MaxWebhookAttempts = 8
+
+// Webhook retry delays double from webhookFirstRetry up to webhookMaxRetry
+const (
+	webhookFirstRetry = 30 * time.Second
+	webhookMaxRetry   = 6 * time.Hour
+)
+
+// Request headers sent with every webhook
+const (
+	WebhookSignatureHeader = "X-ExamHub-Signature" // "sha256=" + hex HMAC-SHA256 of timestamp + "." + body
+	WebhookTimestampHeader = "X-ExamHub-Timestamp" // Unix seconds when the request was signed
+	WebhookEventHeader     = "X-ExamHub-Event"
+	WebhookDeliveryHeader  = "X-ExamHub-Delivery"
+)
+
+// WebhookEvents are the events exam bodies can subscribe to
+var WebhookEvents = []EventKind{EventRegistrationCreated, EventRegistrationCancelled, EventRegistrationReassigned}
+
+// webhookClient posts payloads; receivers that take longer count as failed
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// WebhookSubscription sends an exam body's registration events to its own system
+type WebhookSubscription struct {
+	ID        string
+	ExamCode  string
+	URL       string
+	Secret    string      // HMAC key shared with the receiver
+	Events    []EventKind // empty means all WebhookEvents
+	Disabled  bool
+	CreatedAt time.Time
+}
+
+// Wants reports whether the subscription receives an event kind. Only WebhookEvents are ever sent:
+// reminders and other candidate messages are not for exam bodies.
+func (s WebhookSubscription) Wants(kind EventKind) bool {
+	if !slices.Contains(WebhookEvents, kind) {
+		return false
+	}
+	return len(s.Events) == 0 || slices.Contains(s.Events, kind)
+}
+
+// WebhookDelivery is one payload in the webhook delivery log
+type WebhookDelivery struct {
+	ID             string
+	SubscriptionID string
+	Event          EventKind
+	RegistrationID string
+	Payload        string // JSON body, identical on every attempt and replay
+	Status         string
+	Attempts       int
+	ResponseCode   int
+	LastError      string
+	CreatedAt      time.Time
+	NextAttempt    time.Time
+	DeliveredAt    time.Time
+}
+
+// WebhookPayload is the JSON body posted to subscribers
+type WebhookPayload struct {
+	DeliveryID     string              `json:"delivery_id"`
+	Event          EventKind           `json:"event"`
+	Time           time.Time           `json:"time"`
+	Registration   WebhookRegistration `json:"registration"`
+	PreviousCenter string              `json:"previous_center,omitempty"`
+	PreviousCity   string              `json:"previous_city,omitempty"`
+	Reason         string              `json:"reason,omitempty"`
+}
+
+// WebhookRegistration is the part of a registration shared with the exam body. Contact details stay with us.
+type WebhookRegistration struct {
+	ID             string  `json:"id"`
+	RollNumber     string  `json:"roll_number"`
+	StudentName    string  `json:"student_name"`
+	ExamCode       string  `json:"exam_code"`
+	Edition        int     `json:"edition"`
+	HomeCity       string  `json:"home_city"`
+	AssignedCity   string  `json:"assigned_city"`
+	AssignedCenter string  `json:"assigned_center"`
+	ExamDate       string  `json:"exam_date"`
+	TimeSlot       string  `json:"time_slot"`
+	DistanceKm     float64 `json:"distance_km"`
+}
+
+// SignWebhook returns the signature header value for a body signed at timestamp
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseWebhookEvents reads a comma-separated event list; an empty list means all events
+func ParseWebhookEvents(list string) ([]EventKind, error) {
+	var kinds []EventKind
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, k := range WebhookEvents {
+			if string(k) == name {
+				kinds = append(kinds, k)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown webhook event '%s'", name)
+		}
+	}
+	return kinds, nil
+}
+
+// AddWebhook subscribes url to events for one exam and returns the subscription with its new secret
+func (h *ExamCenterHandler) AddWebhook(actor, examCode, target string, events []EventKind) (WebhookSubscription, error) {
+	code, err := h.examCode(examCode)
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+	u, err := url.Parse(strings.TrimSpace(target))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return WebhookSubscription{}, fmt.Errorf("webhook URL '%s' must be an absolute http or https URL", target)
+	}
+	id, err := randomHex(4)
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+	sub := WebhookSubscription{ID: "wh_" + id, ExamCode: code, URL: u.String(), Secret: secret, Events: events, CreatedAt: time.Now()}
+	h.webhooks = append(h.webhooks, sub)
+	return sub, h.commit(actor, "webhook.add", sub.ID, code+" "+sub.URL)
+}
+
+// Webhooks returns all subscriptions
+func (h *ExamCenterHandler) Webhooks() []WebhookSubscription {
+	return append([]WebhookSubscription(nil), h.webhooks...)
+}
+
+// webhookIndex returns the position of a subscription, or -1
+func (h *ExamCenterHandler) webhookIndex(id string) int {
+	for i, s := range h.webhooks {
+		if s.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetWebhookDisabled pauses or resumes a subscription. Events are not recorded for paused subscriptions.
+func (h *ExamCenterHandler) SetWebhookDisabled(actor, id string, disabled bool) error {
+	i := h.webhookIndex(id)
+	if i < 0 {
+		return fmt.Errorf("webhook '%s' not found", id)
+	}
+	h.webhooks[i].Disabled = disabled
+	return h.commit(actor, disableAction("webhook", disabled), id, "")
+}
+
+// RotateWebhookSecret replaces a subscription's secret and returns the new one
+func (h *ExamCenterHandler) RotateWebhookSecret(actor, id string) (string, error) {
+	i := h.webhookIndex(id)
+	if i < 0 {
+		return "", fmt.Errorf("webhook '%s' not found", id)
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+	h.webhooks[i].Secret = secret
+	return secret, h.commit(actor, "webhook.rotate_secret", id, "")
+}
+
+// RemoveWebhook deletes a subscription; its pending deliveries are moved to the dead-letter list
+func (h *ExamCenterHandler) RemoveWebhook(actor, id string) error {
+	i := h.webhookIndex(id)
+	if i < 0 {
+		return fmt.Errorf("webhook '%s' not found", id)
+	}
+	h.webhooks = append(h.webhooks[:i], h.webhooks[i+1:]...)
+	for j := range h.webhookLog {
+		if d := &h.webhookLog[j]; d.SubscriptionID == id && d.Status == WebhookPending {
+			d.Status, d.LastError = WebhookDead, "subscription removed"
+		}
+	}
+	return h.commit(actor, "webhook.remove", id, "")
+}
+
+// dispatchWebhooks queues a delivery for every subscription that wants the event. Nothing is
+// posted here: the caller may be holding a lock, so RunWebhooks or the server's sender sends them.
+func (h *ExamCenterHandler) dispatchWebhooks(ev Event) {
+	kind := ev.Kind
+	if kind == EventWaitlistPromoted {
+		// For the exam body a promoted candidate is simply a new registration
+		kind = EventRegistrationCreated
+	}
+	reg := ev.Registration
+	for _, sub := range h.webhooks {
+		if sub.Disabled || !strings.EqualFold(sub.ExamCode, reg.ExamType.Code) || !sub.Wants(kind) {
+			continue
+		}
+		d := WebhookDelivery{
+			ID:             fmt.Sprintf("W%06d", len(h.webhookLog)+1),
+			SubscriptionID: sub.ID,
+			Event:          kind,
+			RegistrationID: reg.ID,
+			Status:         WebhookPending,
+			CreatedAt:      ev.Time,
+			NextAttempt:    ev.Time,
+		}
+		body, err := json.Marshal(WebhookPayload{
+			DeliveryID: d.ID,
+			Event:      kind,
+			Time:       ev.Time,
+			Registration: WebhookRegistration{
+				ID:             reg.ID,
+				RollNumber:     reg.RollNumber,
+				StudentName:    reg.StudentName,
+				ExamCode:       reg.ExamType.Code,
+				Edition:        reg.ExamType.Edition,
+				HomeCity:       reg.StudentCity,
+				AssignedCity:   reg.AssignedCity,
+				AssignedCenter: reg.AssignedCenter,
+				ExamDate:       reg.ExamDate,
+				TimeSlot:       reg.TimeSlot,
+				DistanceKm:     reg.Distance,
+			},
+			PreviousCenter: ev.PreviousCenter,
+			PreviousCity:   ev.PreviousCity,
+			Reason:         ev.Reason,
+		})
+		if err != nil {
+			d.Status, d.LastError = WebhookDead, fmt.Sprintf("error encoding payload: %v", err)
+		} else {
+			d.Payload = string(body)
+		}
+		h.webhookLog = append(h.webhookLog, d)
+	}
+}
+
+// WebhookAttempt is a due delivery taken out of the log together with its subscription, so it can
+// be posted by SendWebhooks without holding whatever guards the handler
+type WebhookAttempt struct {
+	index        int
+	delivery     WebhookDelivery
+	subscription WebhookSubscription
+	code         int
+	err          error
+}
+
+// DueWebhooks returns the pending deliveries whose backoff has passed. Deliveries of paused
+// subscriptions wait; those of removed subscriptions are moved to the dead-letter list.
+func (h *ExamCenterHandler) DueWebhooks(now time.Time) []WebhookAttempt {
+	var due []WebhookAttempt
+	for i := range h.webhookLog {
+		d := &h.webhookLog[i]
+		if d.Status != WebhookPending || now.Before(d.NextAttempt) {
+			continue
+		}
+		j := h.webhookIndex(d.SubscriptionID)
+		if j < 0 {
+			d.Status, d.LastError = WebhookDead, "subscription removed"
+			continue
+		}
+		if h.webhooks[j].Disabled {
+			continue
+		}
+		due = append(due, WebhookAttempt{index: i, delivery: *d, subscription: h.webhooks[j]})
+	}
+	return due
+}
+
+// SendWebhooks posts due deliveries and returns the attempts made. It touches no handler state.
+// After a failure the rest of that subscription's deliveries wait for the next run, so a receiver
+// that is down costs one timeout per run rather than one per delivery.
+func SendWebhooks(due []WebhookAttempt, now time.Time) []WebhookAttempt {
+	var tried []WebhookAttempt
+	down := make(map[string]bool)
+	for _, a := range due {
+		if down[a.subscription.ID] {
+			continue
+		}
+		a.code, a.err = postWebhook(a.subscription, a.delivery, now)
+		if a.err != nil {
+			down[a.subscription.ID] = true
+		}
+		tried = append(tried, a)
+	}
+	return tried
+}
+
+// RecordWebhooks writes the outcome of SendWebhooks back to the log and returns how many attempts
+// were recorded. Deliveries that changed in the meantime, say by removing their subscription, are left alone.
+func (h *ExamCenterHandler) RecordWebhooks(tried []WebhookAttempt, now time.Time) int {
+	recorded := 0
+	for _, a := range tried {
+		if a.index >= len(h.webhookLog) {
+			continue
+		}
+		d := &h.webhookLog[a.index]
+		if d.ID != a.delivery.ID || d.Status != WebhookPending || d.Attempts != a.delivery.Attempts {
+			continue
+		}
+		recordWebhookAttempt(d, a.code, a.err, now)
+		recorded++
+	}
+	return recorded
+}
+
+// recordWebhookAttempt counts one attempt and schedules the next one on failure
+func recordWebhookAttempt(d *WebhookDelivery, code int, err error, now time.Time) {
+	d.Attempts++
+	d.ResponseCode = code
+	if err == nil {
+		d.Status, d.DeliveredAt, d.LastError = WebhookDelivered, now, ""
+		return
+	}
+	d.LastError = err.Error()
+	if d.Attempts >= MaxWebhookAttempts {
+		d.Status = WebhookDead
+		return
+	}
+	delay := webhookFirstRetry << (d.Attempts - 1)
+	if delay > webhookMaxRetry {
+		delay = webhookMaxRetry
+	}
+	d.NextAttempt = now.Add(delay)
+}
+
+// postWebhook sends one signed request. Any 2xx response counts as delivered.
+func postWebhook(sub WebhookSubscription, d WebhookDelivery, now time.Time) (int, error) {
+	body := []byte(d.Payload)
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %v", err)
+	}
+	ts := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ExamCenterHub-Webhook/1")
+	req.Header.Set(WebhookEventHeader, string(d.Event))
+	req.Header.Set(WebhookDeliveryHeader, d.ID)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(ts, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(sub.Secret, ts, body))
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error posting webhook: %v", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// RunWebhooks posts pending deliveries whose backoff has passed and returns how many were tried.
+// It sends while holding the handler, so the web server uses DueWebhooks, SendWebhooks and
+// RecordWebhooks instead.
+func (h *ExamCenterHandler) RunWebhooks(now time.Time) int {
+	return h.RecordWebhooks(SendWebhooks(h.DueWebhooks(now), now), now)
+}
+
+// WebhookDeliveries returns up to limit deliveries with the given status ("" for any), newest first.
+// A limit of 0 returns all of them.
+func (h *ExamCenterHandler) WebhookDeliveries(status string, limit int) []WebhookDelivery {
+	var list []WebhookDelivery
+	for i := len(h.webhookLog) - 1; i >= 0; i-- {
+		if limit > 0 && len(list) == limit {
+			break
+		}
+		if status == "" || h.webhookLog[i].Status == status {
+			list = append(list, h.webhookLog[i])
+		}
+	}
+	return list
+}
+
+// ReplayWebhook queues a dead or delivered payload to be posted again, unchanged, and returns the
+// updated delivery. A replay that fails goes back through the normal retry schedule.
+func (h *ExamCenterHandler) ReplayWebhook(actor, id string) (WebhookDelivery, error) {
+	for i := range h.webhookLog {
+		d := &h.webhookLog[i]
+		if d.ID != id {
+			continue
+		}
+		if d.Status == WebhookPending {
+			return *d, fmt.Errorf("webhook delivery '%s' is still being retried", id)
+		}
+		if h.webhookIndex(d.SubscriptionID) < 0 {
+			return *d, fmt.Errorf("webhook '%s' of delivery '%s' was removed", d.SubscriptionID, id)
+		}
+		d.Status, d.Attempts, d.NextAttempt = WebhookPending, 0, time.Now()
+		return *d, h.commit(actor, "webhook.replay", id, "")
+	}
+	return WebhookDelivery{}, fmt.Errorf("webhook delivery '%s' not found", id)
+}
+
+// ReplayDeadWebhooks queues every dead-letter delivery, optionally only for one subscription, to be
+// posted again and returns how many were queued. Deliveries of removed subscriptions stay dead.
+func (h *ExamCenterHandler) ReplayDeadWebhooks(actor, subscriptionID string) (int, error) {
+	now := time.Now()
+	queued := 0
+	for i := range h.webhookLog {
+		d := &h.webhookLog[i]
+		if d.Status != WebhookDead || (subscriptionID != "" && d.SubscriptionID != subscriptionID) || h.webhookIndex(d.SubscriptionID) < 0 {
+			continue
+		}
+		d.Status, d.Attempts, d.NextAttempt = WebhookPending, 0, now
+		queued++
+	}
+	return queued, h.commit(actor, "webhook.replay_dead", subscriptionID, fmt.Sprintf("%d queued", queued))
+}
+
+// randomHex returns n random bytes, hex encoded
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating random value: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+} 
\ No newline at end of file
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookReceiver answers with status and records whether each request carried a valid signature
+type webhookReceiver struct {
+	mu     sync.Mutex
+	secret string
+	status int
+	hits   int
+	badSig int
+}
+
+func (rc *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	ts, _ := strconv.ParseInt(r.Header.Get(WebhookTimestampHeader), 10, 64)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.hits++
+	if r.Header.Get(WebhookSignatureHeader) != SignWebhook(rc.secret, ts, body) {
+		rc.badSig++
+	}
+	w.WriteHeader(rc.status)
+}
+
+// webhookHandler subscribes a receiver answering status to NEET events and queues one event
+func webhookHandler(t *testing.T, status int) (*ExamCenterHandler, *webhookReceiver, WebhookSubscription) {
+	t.Helper()
+	rc := &webhookReceiver{status: status}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+	h := NewExamCenterHandler()
+	sub, err := h.AddWebhook("admin", "NEET", srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc.secret = sub.Secret
+	h.dispatchWebhooks(Event{Kind: EventRegistrationCreated, Time: time.Now(), Registration: ExamRegistration{ID: "NEET-R1-1", ExamType: ExamType{Code: "NEET"}}})
+	return h, rc, sub
+}
+
+func TestDispatchWebhooksOnlyQueues(t *testing.T) {
+	h, rc, _ := webhookHandler(t, http.StatusOK)
+	if rc.hits != 0 {
+		t.Fatalf("dispatch posted %d request(s); it should only queue", rc.hits)
+	}
+	if d := h.webhookLog[0]; d.Status != WebhookPending || d.Attempts != 0 {
+		t.Fatalf("queued delivery = %s after %d attempts, want pending after 0", d.Status, d.Attempts)
+	}
+	if n := h.RunWebhooks(time.Now()); n != 1 {
+		t.Fatalf("RunWebhooks tried %d, want 1", n)
+	}
+	if d := h.webhookLog[0]; d.Status != WebhookDelivered || d.ResponseCode != http.StatusOK {
+		t.Errorf("delivery = %s (%d), want delivered (200)", d.Status, d.ResponseCode)
+	}
+	if rc.badSig != 0 {
+		t.Errorf("%d request(s) carried a signature the receiver could not verify", rc.badSig)
+	}
+}
+
+func TestRunWebhooksRetrySchedule(t *testing.T) {
+	tests := []struct {
+		status   int
+		runs     int
+		want     string
+		attempts int
+		wait     time.Duration // before the next attempt; 0 when none is due
+	}{
+		{http.StatusOK, 1, WebhookDelivered, 1, 0},
+		{http.StatusNoContent, 1, WebhookDelivered, 1, 0},
+		{http.StatusInternalServerError, 1, WebhookPending, 1, webhookFirstRetry},
+		{http.StatusBadRequest, 3, WebhookPending, 3, 4 * webhookFirstRetry},
+		{http.StatusBadGateway, MaxWebhookAttempts, WebhookDead, MaxWebhookAttempts, 0},
+	}
+	for _, tt := range tests {
+		h, rc, _ := webhookHandler(t, tt.status)
+		now, last := time.Now(), time.Time{}
+		for i := 0; i < tt.runs; i++ {
+			last = now
+			h.RunWebhooks(now)
+			if next := h.webhookLog[0].NextAttempt; next.After(now) {
+				now = next
+			}
+		}
+		d := h.webhookLog[0]
+		if d.Status != tt.want || d.Attempts != tt.attempts || rc.hits != tt.attempts {
+			t.Errorf("%d x%d: delivery %s after %d attempts (%d posted), want %s after %d", tt.status, tt.runs, d.Status, d.Attempts, rc.hits, tt.want, tt.attempts)
+		}
+		if tt.want == WebhookPending {
+			if got := d.NextAttempt.Sub(last); got != tt.wait {
+				t.Errorf("%d x%d: next attempt in %v, want %v", tt.status, tt.runs, got, tt.wait)
+			}
+			if got := h.RunWebhooks(d.NextAttempt.Add(-time.Second)); got != 0 {
+				t.Errorf("%d: %d retried before the backoff of %v passed", tt.status, got, tt.wait)
+			}
+		}
+	}
+}
+
+func TestRunWebhooksSkipsPausedAndRemovedSubscriptions(t *testing.T) {
+	h, rc, sub := webhookHandler(t, http.StatusOK)
+	if err := h.SetWebhookDisabled("admin", sub.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if n := h.RunWebhooks(time.Now()); n != 0 || rc.hits != 0 {
+		t.Fatalf("paused webhook: %d tried, %d posted, want none", n, rc.hits)
+	}
+	if h.webhookLog[0].Status != WebhookPending {
+		t.Fatalf("paused webhook's delivery is %s, want it kept pending", h.webhookLog[0].Status)
+	}
+	if err := h.RemoveWebhook("admin", sub.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n := h.RunWebhooks(time.Now()); n != 0 || rc.hits != 0 || h.webhookLog[0].Status != WebhookDead {
+		t.Errorf("removed webhook: %d tried, %d posted, delivery %s; want nothing posted and a dead letter", n, rc.hits, h.webhookLog[0].Status)
+	}
+}
+
+func TestRecordWebhooksIgnoresChangedDeliveries(t *testing.T) {
+	h, rc, sub := webhookHandler(t, http.StatusOK)
+	now := time.Now()
+	due := h.DueWebhooks(now)
+	// The subscription is removed while the request is on the wire
+	if err := h.RemoveWebhook("admin", sub.ID); err != nil {
+		t.Fatal(err)
+	}
+	tried := SendWebhooks(due, now)
+	if rc.hits != 1 {
+		t.Fatalf("posted %d request(s), want 1", rc.hits)
+	}
+	if n := h.RecordWebhooks(tried, now); n != 0 || h.webhookLog[0].Status != WebhookDead {
+		t.Errorf("recorded %d, delivery %s; want the removal to stand", n, h.webhookLog[0].Status)
+	}
+}
+func TestRemindersAreNotSentToWebhooks(t *testing.T) {
+	h, _, _ := webhookHandler(t, http.StatusOK)
+	h.Subscribe(h.dispatchWebhooks)
+	now := time.Now()
+	h.registrations = append(h.registrations, ExamRegistration{
+		ID: "NEET-R2-1", RollNumber: "R2", Phone: "9876543210", ExamType: ExamType{Code: "NEET"},
+		ExamDate: now.AddDate(0, 0, 1).Format("2006-01-02"),
+	})
+	if n := h.QueueReminders(now); n != 1 {
+		t.Fatalf("queued %d reminders, want 1", n)
+	}
+	if len(h.webhookLog) != 1 {
+		t.Fatalf("webhook log has %d deliveries after a reminder, want only the registration's 1", len(h.webhookLog))
+	}
+	for _, tt := range []struct {
+		events []EventKind
+		kind   EventKind
+		want   bool
+	}{
+		{nil, EventRegistrationCreated, true},
+		{nil, EventExamReminder, false},
+		{[]EventKind{EventRegistrationCancelled}, EventRegistrationCreated, false},
+		{[]EventKind{EventRegistrationCancelled}, EventRegistrationCancelled, true},
+		{[]EventKind{EventExamReminder}, EventExamReminder, false},
+	} {
+		if got := (WebhookSubscription{Events: tt.events}).Wants(tt.kind); got != tt.want {
+			t.Errorf("subscription to %v wants %s = %v, want %v", tt.events, tt.kind, got, tt.want)
+		}
+	}
+} 
\ No newline at end of file
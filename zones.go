@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AllocationRule limits which exam cities a candidate may be assigned, by home state or zone
+type AllocationRule string
+
+const (
+	AllocateAny            AllocationRule = ""                 // nearest cities anywhere
+	AllocateSameStateFirst AllocationRule = "same_state_first" // cities in the home state before any other, each by distance
+	AllocateWithinState    AllocationRule = "within_state"     // only cities in the home state
+	AllocateWithinZone     AllocationRule = "within_zone"      // only cities in the home state's zone, see ExamType.Zones
+)
+
+// AllocationRules lists the rules in the order they are offered
+var AllocationRules = []AllocationRule{AllocateAny, AllocateSameStateFirst, AllocateWithinState, AllocateWithinZone}
+
+// ParseAllocationRule reads a rule name; "any" and "" both mean AllocateAny
+func ParseAllocationRule(s string) (AllocationRule, error) {
+	r := AllocationRule(strings.ToLower(strings.TrimSpace(s)))
+	if r == "any" {
+		return AllocateAny, nil
+	}
+	for _, known := range AllocationRules {
+		if r == known {
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("unknown allocation rule '%s' (use any, same_state_first, within_state or within_zone)", s)
+}
+
+// String names the rule for display
+func (r AllocationRule) String() string {
+	if r == AllocateAny {
+		return "any"
+	}
+	return string(r)
+}
+
+// ZoneSchemes groups states into zones, keyed by scheme name. An exam type picks one with ExamType.Zones.
+var ZoneSchemes = map[string]map[string]string{
+	// Staff Selection Commission regional offices
+	"SSC": {
+		"Delhi": "Northern", "Rajasthan": "Northern", "Uttarakhand": "Northern",
+		"Uttar Pradesh": "Central", "Bihar": "Central",
+		"West Bengal": "Eastern", "Odisha": "Eastern", "Jharkhand": "Eastern", "Sikkim": "Eastern",
+		"Andaman and Nicobar Islands": "Eastern",
+		"Assam":                       "North Eastern", "Arunachal Pradesh": "North Eastern", "Manipur": "North Eastern",
+		"Meghalaya": "North Eastern", "Mizoram": "North Eastern", "Nagaland": "North Eastern", "Tripura": "North Eastern",
+		"Maharashtra": "Western", "Gujarat": "Western", "Goa": "Western", "Dadra and Nagar Haveli and Daman and Diu": "Western",
+		"Madhya Pradesh": "Madhya Pradesh", "Chhattisgarh": "Madhya Pradesh",
+		"Andhra Pradesh": "Southern", "Tamil Nadu": "Southern", "Telangana": "Southern", "Puducherry": "Southern",
+		"Karnataka": "Karnataka Kerala", "Kerala": "Karnataka Kerala", "Lakshadweep": "Karnataka Kerala",
+		"Jammu and Kashmir": "North Western", "Ladakh": "North Western", "Himachal Pradesh": "North Western",
+		"Punjab": "North Western", "Haryana": "North Western", "Chandigarh": "North Western",
+	},
+}
+
+// Zone returns the zone of a state under the exam's zone scheme, or "" when it has none
+func (ex ExamType) Zone(state string) string {
+	return ZoneSchemes[ex.Zones][state]
+}
+
+// allocationRank orders candidate cities under the exam's rule: lower ranks are offered first and
+// ok is false for cities the rule rules out. Rules that need the home state or zone allow every
+// city when it is unknown.
+func (ex ExamType) allocationRank(home, city City) (rank int, ok bool) {
+	switch ex.Allocation {
+	case AllocateSameStateFirst:
+		if home.State != "" && city.State != home.State {
+			return 1, true
+		}
+	case AllocateWithinState:
+		return 0, home.State == "" || city.State == home.State
+	case AllocateWithinZone:
+		zone := ex.Zone(home.State)
+		return 0, zone == "" || ex.Zone(city.State) == zone
+	}
+	return 0, true
+} 
\ No newline at end of file
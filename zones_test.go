@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAllocationRule(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    AllocationRule
+		wantErr bool
+	}{
+		{"", AllocateAny, false},
+		{"any", AllocateAny, false},
+		{" Within_Zone ", AllocateWithinZone, false},
+		{"same_state_first", AllocateSameStateFirst, false},
+		{"nearest", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAllocationRule(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseAllocationRule(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestAllocationRank(t *testing.T) {
+	pune := City{Name: "Pune", State: "Maharashtra"}
+	mumbai := City{Name: "Mumbai", State: "Maharashtra"}
+	ahmedabad := City{Name: "Ahmedabad", State: "Gujarat"}
+	indore := City{Name: "Indore", State: "Madhya Pradesh"}
+	nowhere := City{Name: "Nowhere"}
+	tests := []struct {
+		name       string
+		rule       AllocationRule
+		zones      string
+		home, city City
+		rank       int
+		ok         bool
+	}{
+		{"any", AllocateAny, "", pune, indore, 0, true},
+		{"same state first, home state", AllocateSameStateFirst, "", pune, mumbai, 0, true},
+		{"same state first, other state", AllocateSameStateFirst, "", pune, ahmedabad, 1, true},
+		{"same state first, home state unknown", AllocateSameStateFirst, "", nowhere, ahmedabad, 0, true},
+		{"within state", AllocateWithinState, "", pune, mumbai, 0, true},
+		{"within state, other state", AllocateWithinState, "", pune, ahmedabad, 0, false},
+		{"within state, home state unknown", AllocateWithinState, "", nowhere, ahmedabad, 0, true},
+		{"within zone", AllocateWithinZone, "SSC", pune, ahmedabad, 0, true},
+		{"within zone, other zone", AllocateWithinZone, "SSC", pune, indore, 0, false},
+		{"within zone, city state unknown", AllocateWithinZone, "SSC", pune, nowhere, 0, false},
+		{"within zone, home zone unknown", AllocateWithinZone, "SSC", nowhere, indore, 0, true},
+		{"within zone, no scheme", AllocateWithinZone, "", pune, indore, 0, true},
+	}
+	for _, tt := range tests {
+		ex := ExamType{Allocation: tt.rule, Zones: tt.zones}
+		if rank, ok := ex.allocationRank(tt.home, tt.city); rank != tt.rank || ok != tt.ok {
+			t.Errorf("%s: rank %d, ok %v; want %d, %v", tt.name, rank, ok, tt.rank, tt.ok)
+		}
+	}
+}
+
+func TestAllocationByStateAndZone(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     AllocationRule
+		offered  []string // states that may be offered
+		excluded map[string]string
+	}{
+		{
+			name:     "within state",
+			rule:     AllocateWithinState,
+			offered:  []string{"Gujarat"},
+			excluded: map[string]string{"Mumbai Central Exam Center": "outside Gujarat", "Indore Central Exam Center": "outside Gujarat"},
+		},
+		{
+			name:     "within zone",
+			rule:     AllocateWithinZone,
+			offered:  []string{"Gujarat", "Maharashtra"},
+			excluded: map[string]string{"Indore Central Exam Center": "outside the candidate's Western zone", "Jodhpur Central Exam Center": "outside the candidate's Western zone"},
+		},
+		{
+			name:    "any",
+			rule:    AllocateAny,
+			offered: []string{"Gujarat", "Maharashtra", "Madhya Pradesh", "Rajasthan"},
+		},
+	}
+	for _, tt := range tests {
+		h := NewExamCenterHandler()
+		ex := includeHomeCity(t, h, "UPSC")
+		ex.Allocation, ex.Zones, ex.MaxCenters = tt.rule, "SSC", 0
+		res, err := h.allocate(DefaultPolicy(ex.Code), "Vadodara", ex, StudentPreference{MaxDistance: 600})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Nearest) == 0 || res.Nearest[0].City.Name != "Vadodara" {
+			t.Fatalf("%s: the home city is not offered first: %+v", tt.name, res.Nearest)
+		}
+		seen := make(map[string]bool)
+		for _, cd := range res.Nearest {
+			state := h.CityState(cd.City.Name)
+			if !hasString(tt.offered, state) {
+				t.Errorf("%s: offered %s in %s", tt.name, cd.City.Name, state)
+			}
+			seen[state] = true
+		}
+		if len(seen) != len(tt.offered) {
+			t.Errorf("%s: offered states %v, want %v", tt.name, seen, tt.offered)
+		}
+		for _, v := range res.Verdicts {
+			if want, ok := tt.excluded[v.Center.Name]; ok && (v.Rule != FilterAllocation || v.Reason != want) {
+				t.Errorf("%s: %s excluded by %q (%s), want %q", tt.name, v.Center.Name, v.Rule, v.Reason, want)
+			}
+		}
+	}
+}
+
+func TestSameStateFirst(t *testing.T) {
+	h := NewExamCenterHandler()
+	ex := includeHomeCity(t, h, "UPSC")
+	ex.Allocation, ex.MaxCenters = AllocateSameStateFirst, 0
+	res, err := h.allocate(DefaultPolicy(ex.Code), "Vadodara", ex, StudentPreference{MaxDistance: 600})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Gujarat cities come first by distance, then the rest by distance
+	var order []string
+	leftState := false
+	for _, v := range res.Verdicts {
+		if v.Rank == 0 {
+			break
+		}
+		inState := h.CityState(v.City) == "Gujarat"
+		if inState && leftState {
+			t.Errorf("%s in Gujarat is ranked %d, after a city in another state", v.City, v.Rank)
+		}
+		if !inState && !leftState {
+			leftState = true
+			if v.Behind != "outside the home state, which is offered first" {
+				t.Errorf("first out-of-state center %s is behind %q", v.Center.Name, v.Behind)
+			}
+		}
+		if len(order) == 0 || order[len(order)-1] != v.City {
+			order = append(order, v.City)
+		}
+	}
+	if got := strings.Join(order, ", "); !strings.HasPrefix(got, "Vadodara, Ahmedabad, Rajkot, ") {
+		t.Errorf("cities in order %s, want the Gujarat ones first", got)
+	}
+} 
\ No newline at end of file